@@ -3,17 +3,23 @@ package app
 import (
 	"TrackMe/internal/cache"
 	"TrackMe/internal/config"
+	"TrackMe/internal/domain/alert"
+	"TrackMe/internal/domain/rule"
 	"TrackMe/internal/handler"
+	"TrackMe/internal/notifier"
 	"TrackMe/internal/repository"
 	"TrackMe/internal/service/track"
 	"TrackMe/internal/worker"
+	"TrackMe/pkg/calendar"
 	"TrackMe/pkg/log"
 	"TrackMe/pkg/server"
 	"TrackMe/pkg/store"
 
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/signal"
 	"syscall"
@@ -37,10 +43,21 @@ func Run() {
 		return
 	}
 
+	// Exchange rates come from the currency API when configured, otherwise from the static rates file
+	currencyStore := repository.WithStaticCurrency(configs.CURRENCY.RatesFile)
+	if configs.CURRENCY.URL != "" {
+		currencyStore = repository.WithHTTPCurrency(configs.CURRENCY.URL, configs.CURRENCY.Login, configs.CURRENCY.Password)
+	}
+
 	repositories, err := repository.New(
 		repository.WithPostgresStore(configs.POSTGRES.DSN),
 		repository.WithMemoryStore(),
 		repository.WithClickHouseStore(configs.CLICKHOUSE.ADDR, configs.CLICKHOUSE.UserName, configs.CLICKHOUSE.Password, configs.CLICKHOUSE.DB),
+		currencyStore,
+		repository.WithMetricDefinitions(configs.METRICS.Definitions),
+		repository.WithAlertRules(configs.ALERTS.Rules),
+		repository.WithReasons(configs.REASONS.File),
+		repository.WithScoringModel(configs.CLIENTS.ScoringFile),
 	)
 
 	if err != nil {
@@ -52,6 +69,7 @@ func Run() {
 	caches, err := cache.New(
 		cache.Dependencies{
 			MetricRepository: repositories.Metric,
+			CurrencyProvider: repositories.Currency,
 		},
 		cache.WithRedisStore(configs.Redis.URL))
 	if err != nil {
@@ -60,12 +78,63 @@ func Run() {
 	}
 	defer caches.Close()
 
+	// Business-time durations use the calendar file, or Monday to Friday 09:00-18:00 UTC without it
+	businessCalendar, err := calendar.Load(configs.CALENDAR.File)
+	if errors.Is(err, fs.ErrNotExist) {
+		logger.Warn().Str("file", configs.CALENDAR.File).Msg("calendar file not found, using the default calendar")
+		businessCalendar, err = calendar.Default(time.UTC), nil
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("ERR_INIT_CALENDAR")
+		return
+	}
+
+	// Anomaly alerts and the messages of rule notify actions go to every configured channel
+	var (
+		notifiers     []alert.Notifier
+		ruleNotifiers []rule.Notifier
+	)
+	if configs.ALERTS.Log {
+		n := notifier.NewLog()
+		notifiers, ruleNotifiers = append(notifiers, n), append(ruleNotifiers, n)
+	}
+	if configs.ALERTS.WebhookURL != "" {
+		n := notifier.NewWebhook(configs.ALERTS.WebhookURL)
+		notifiers, ruleNotifiers = append(notifiers, n), append(ruleNotifiers, n)
+	}
+	if configs.ALERTS.SMTPAddr != "" && len(configs.ALERTS.SMTPTo) > 0 {
+		n := notifier.NewSMTP(configs.ALERTS.SMTPAddr, configs.ALERTS.SMTPUsername,
+			configs.ALERTS.SMTPPassword, configs.ALERTS.SMTPFrom, configs.ALERTS.SMTPTo)
+		notifiers, ruleNotifiers = append(notifiers, n), append(ruleNotifiers, n)
+	}
+
 	trackService, err := track.New(
 		track.WithClientRepository(repositories.Client),
 		track.WithUserRepository(repositories.User),
 		track.WithStageRepository(repositories.Stage),
 		track.WithMetricRepository(repositories.Metric),
-		track.WithMetricCache(caches.Metric))
+		track.WithJobRepository(repositories.Job),
+		track.WithTransitionRepository(repositories.Transition),
+		track.WithTouchpointRepository(repositories.Touchpoint),
+		track.WithActivityRepository(repositories.Activity),
+		track.WithReasons(repositories.Reason),
+		track.WithScoringModel(repositories.Scoring),
+		track.WithMetricRunRepository(repositories.MetricRun),
+		track.WithStepPolicy(configs.METRICS.StepTimeout, configs.METRICS.StepRetries),
+		track.WithCalendar(businessCalendar),
+		track.WithTimezone(configs.METRICS.Timezone),
+		track.WithMetricDefinitions(repositories.Definition),
+		track.WithAlertRepository(repositories.Alert),
+		track.WithAlertRules(repositories.AlertRule),
+		track.WithNotifiers(notifiers...),
+		track.WithGoalRepository(repositories.Goal),
+		track.WithAnnotationRepository(repositories.Annotation),
+		track.WithExperimentRepository(repositories.Experiment),
+		track.WithRuleRepository(repositories.Rule, repositories.RuleExecution),
+		track.WithRuleNotifiers(ruleNotifiers...),
+		track.WithWebhookCaller(notifier.NewCaller()),
+		track.WithMetricCache(caches.Metric),
+		track.WithCurrencyProvider(caches.Currency, configs.CURRENCY.Base))
 	if err != nil {
 		logger.Error().Err(err).Msg("ERR_INIT_LIBRARY_SERVICE")
 		return
@@ -92,6 +161,9 @@ func Run() {
 	metricWorker := worker.NewMetricWorker(trackService)
 	metricWorker.Start()
 
+	clientWorker := worker.NewClientWorker(trackService, configs.CLIENTS.InactivityThreshold)
+	clientWorker.Start()
+
 	if err = servers.Run(logger); err != nil {
 		logger.Error().Err(err).Msg("ERR_RUN_SERVERS")
 		return
@@ -116,8 +188,9 @@ func Run() {
 	ctx, cancel := context.WithTimeout(context.Background(), wait)
 	defer cancel()
 
-	// Stop the metric worker first
+	// Stop the workers first
 	metricWorker.Stop()
+	clientWorker.Stop()
 
 	// Doesn't block if no connections, but will otherwise wait until the timeout deadline
 	if err = servers.Stop(ctx); err != nil {
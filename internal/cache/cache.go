@@ -2,12 +2,14 @@ package cache
 
 import (
 	"TrackMe/internal/cache/redis"
+	"TrackMe/internal/domain/currency"
 	"TrackMe/internal/domain/metric"
 	"TrackMe/pkg/store"
 )
 
 type Dependencies struct {
 	MetricRepository metric.Repository
+	CurrencyProvider currency.Provider
 }
 
 // Configuration is an alias for a function that will take in a pointer to a Cache and modify it
@@ -18,6 +20,7 @@ type Cache struct {
 	dependencies Dependencies
 	redis        store.Redis
 	Metric       metric.Cache
+	Currency     currency.Provider
 }
 
 // New takes a variable amount of Configuration functions and returns a new Cache
@@ -60,6 +63,10 @@ func WithRedisStore(url string) Configuration {
 
 		s.Metric = redis.NewMetricCache(s.redis.Connection, s.dependencies.MetricRepository)
 
+		if s.dependencies.CurrencyProvider != nil {
+			s.Currency = redis.NewCurrencyCache(s.redis.Connection, s.dependencies.CurrencyProvider)
+		}
+
 		return
 	}
 }
@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"TrackMe/internal/domain/currency"
+)
+
+// CurrencyCache handles daily caching of exchange rates in Redis.
+type CurrencyCache struct {
+	cache    *redis.Client
+	provider currency.Provider
+}
+
+// NewCurrencyCache creates a new CurrencyCache.
+func NewCurrencyCache(c *redis.Client, p currency.Provider) *CurrencyCache {
+	return &CurrencyCache{
+		cache:    c,
+		provider: p,
+	}
+}
+
+// Rates retrieves exchange rates from cache, falling back to the provider once per base and day
+func (c *CurrencyCache) Rates(ctx context.Context, base string, date time.Time) (currency.Rates, error) {
+	cacheKey := fmt.Sprintf("currency:rates:%s:%s", strings.ToUpper(base), date.Format("2006-01-02"))
+
+	data, err := c.cache.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var rates currency.Rates
+		if err = json.Unmarshal([]byte(data), &rates); err == nil {
+			return rates, nil
+		}
+	}
+
+	rates, err := c.provider.Rates(ctx, base, date)
+	if err != nil {
+		return currency.Rates{}, err
+	}
+
+	payload, err := json.Marshal(rates)
+	if err != nil {
+		return currency.Rates{}, err
+	}
+
+	if err = c.cache.Set(ctx, cacheKey, payload, 24*time.Hour).Err(); err != nil {
+		return currency.Rates{}, err
+	}
+
+	return rates, nil
+}
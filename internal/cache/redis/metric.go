@@ -2,8 +2,12 @@ package redis
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -41,7 +45,7 @@ func (c *MetricCache) Set(ctx context.Context, id string, entity metric.Entity)
 
 // List retrieves metrics from cache based on filters, falling back to repository
 func (c *MetricCache) List(ctx context.Context, filters metric.Filters) ([]metric.Entity, error) {
-	cacheKey := fmt.Sprintf("metrics:list:%s:%s", filters.Type, filters.Interval)
+	cacheKey := listCacheKey(filters)
 
 	data, err := c.cache.Get(ctx, cacheKey).Result()
 	if err == nil {
@@ -70,15 +74,68 @@ func (c *MetricCache) List(ctx context.Context, filters metric.Filters) ([]metri
 	return entities, nil
 }
 
-// InvalidateListCache clears the cache for a specific list query
+// InvalidateListCache clears every cached list query that can contain metrics of the filters'
+// type and interval, whatever its time range, metadata or latest filters, including queries
+// without a type or interval filter
 func (c *MetricCache) InvalidateListCache(ctx context.Context, filters metric.Filters) error {
-	cacheKey := fmt.Sprintf("metrics:list:%s:%s", filters.Type, filters.Interval)
-	return c.cache.Del(ctx, cacheKey).Err()
+	patterns := map[string]bool{
+		fmt.Sprintf("metrics:list:%s:%s:*", filters.Type, filters.Interval): true,
+		fmt.Sprintf("metrics:list:%s::*", filters.Type):                     true,
+		fmt.Sprintf("metrics:list::%s:*", filters.Interval):                 true,
+		"metrics:list:::*": true,
+	}
+
+	for pattern := range patterns {
+		iter := c.cache.Scan(ctx, 0, pattern, 100).Iterator()
+		var keys []string
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		if err := c.cache.Del(ctx, keys...).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listCacheKey builds the cache key of a list query as metrics:list:<type>:<interval>:<digest>,
+// where the digest covers the remaining filters
+func listCacheKey(filters metric.Filters) string {
+	keys := make([]string, 0, len(filters.Metadata))
+	for k := range filters.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "from=%d;to=%d;latest=%t", filters.From.Unix(), filters.To.Unix(), filters.Latest)
+	for _, k := range keys {
+		fmt.Fprintf(&b, ";%s=%s", k, filters.Metadata[k])
+	}
+
+	defaults := make([]string, 0, len(filters.MetadataDefaults))
+	for k := range filters.MetadataDefaults {
+		defaults = append(defaults, k)
+	}
+	sort.Strings(defaults)
+	for _, k := range defaults {
+		fmt.Fprintf(&b, ";%s?=%s", k, filters.MetadataDefaults[k])
+	}
+
+	sum := sha1.Sum([]byte(b.String()))
+	return fmt.Sprintf("metrics:list:%s:%s:%s", filters.Type, filters.Interval, hex.EncodeToString(sum[:8]))
 }
 
 // StoreList caches a collection of metrics with the given filters
 func (c *MetricCache) StoreList(ctx context.Context, filters metric.Filters, entities []metric.Entity) error {
-	cacheKey := fmt.Sprintf("metrics:list:%s:%s", filters.Type, filters.Interval)
+	cacheKey := listCacheKey(filters)
 
 	payload, err := json.Marshal(entities)
 	if err != nil {
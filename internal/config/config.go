@@ -13,7 +13,12 @@ import (
 type (
 	Configs struct {
 		APP        AppConfig
-		CURRENCY   ClientConfig
+		CURRENCY   CurrencyConfig
+		METRICS    MetricsConfig
+		CALENDAR   CalendarConfig
+		ALERTS     AlertsConfig
+		CLIENTS    ClientsConfig
+		REASONS    ReasonsConfig
 		CLICKHOUSE ClickhouseConfig
 		POSTGRES   StoreConfig
 		Redis      RedisConfig
@@ -33,6 +38,43 @@ type (
 		Password string
 	}
 
+	CurrencyConfig struct {
+		ClientConfig
+		Base      string `envconfig:"BASE" default:"KZT"`
+		RatesFile string `envconfig:"RATES_FILE" default:"currency_rates.yaml"`
+	}
+
+	MetricsConfig struct {
+		StepTimeout time.Duration `envconfig:"STEP_TIMEOUT" default:"2m"`
+		StepRetries int           `envconfig:"STEP_RETRIES" default:"2"`
+		Definitions string        `envconfig:"DEFINITIONS" default:"metrics.yaml"`
+		Timezone    string        `envconfig:"TIMEZONE" default:"UTC"`
+	}
+
+	CalendarConfig struct {
+		File string `envconfig:"FILE" default:"calendar.yaml"`
+	}
+
+	AlertsConfig struct {
+		Rules        string   `envconfig:"RULES" default:"alerts.yaml"`
+		Log          bool     `envconfig:"LOG" default:"false"`
+		WebhookURL   string   `envconfig:"WEBHOOK_URL"`
+		SMTPAddr     string   `envconfig:"SMTP_ADDR"`
+		SMTPUsername string   `envconfig:"SMTP_USERNAME"`
+		SMTPPassword string   `envconfig:"SMTP_PASSWORD"`
+		SMTPFrom     string   `envconfig:"SMTP_FROM"`
+		SMTPTo       []string `envconfig:"SMTP_TO"`
+	}
+
+	ClientsConfig struct {
+		InactivityThreshold time.Duration `envconfig:"INACTIVITY_THRESHOLD" default:"720h"`
+		ScoringFile         string        `envconfig:"SCORING_FILE" default:"scoring.yaml"`
+	}
+
+	ReasonsConfig struct {
+		File string `envconfig:"FILE" default:"reasons.yaml"`
+	}
+
 	StoreConfig struct {
 		DSN string
 	}
@@ -112,5 +154,25 @@ func New() (cfg Configs, err error) {
 		return
 	}
 
+	if err = envconfig.Process("METRICS", &cfg.METRICS); err != nil {
+		return
+	}
+
+	if err = envconfig.Process("CALENDAR", &cfg.CALENDAR); err != nil {
+		return
+	}
+
+	if err = envconfig.Process("ALERTS", &cfg.ALERTS); err != nil {
+		return
+	}
+
+	if err = envconfig.Process("CLIENTS", &cfg.CLIENTS); err != nil {
+		return
+	}
+
+	if err = envconfig.Process("REASONS", &cfg.REASONS); err != nil {
+		return
+	}
+
 	return
 }
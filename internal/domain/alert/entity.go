@@ -0,0 +1,45 @@
+package alert
+
+import "time"
+
+// Detection methods of a rule.
+const (
+	MethodZScore  = "z_score"
+	MethodPercent = "percent"
+)
+
+// Directions of a deviation a rule reacts to.
+const (
+	DirectionDown = "down"
+	DirectionUp   = "up"
+	DirectionBoth = "both"
+)
+
+// Entity represents an anomalous metric value.
+type Entity struct {
+	// ID is the unique identifier for the alert (UUID).
+	ID string `db:"id" json:"id"`
+
+	// MetricID is the ID of the anomalous metric.
+	MetricID string `db:"metric_id" json:"metric_id"`
+
+	// Type and Interval identify the metric series together with Metadata.
+	Type     string            `db:"metric_type" json:"type"`
+	Interval string            `db:"metric_interval" json:"interval"`
+	Metadata map[string]string `db:"metadata" json:"metadata"`
+
+	// Value is the anomalous value; Baseline and StdDev are the mean and standard deviation of
+	// the values preceding it.
+	Value    float64 `db:"value" json:"value"`
+	Baseline float64 `db:"baseline" json:"baseline"`
+	StdDev   float64 `db:"stddev" json:"stddev"`
+
+	// Score is the z-score or the relative change from the baseline, depending on Method.
+	Method    string  `db:"method" json:"method"`
+	Score     float64 `db:"score" json:"score"`
+	Threshold float64 `db:"threshold" json:"threshold"`
+
+	// MetricCreatedAt is the creation time of the metric, DetectedAt the time of detection.
+	MetricCreatedAt time.Time `db:"metric_created_at" json:"metric_created_at"`
+	DetectedAt      time.Time `db:"detected_at" json:"detected_at"`
+}
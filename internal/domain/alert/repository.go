@@ -0,0 +1,30 @@
+package alert
+
+import (
+	"context"
+)
+
+// Repository defines the interface for alert persistence.
+type Repository interface {
+	// Add stores an alert and returns its ID.
+	Add(ctx context.Context, data Entity) (string, error)
+
+	// List retrieves alerts with pagination, newest first, optionally filtered by metric type,
+	// together with the total number of matching alerts.
+	List(ctx context.Context, metricType string, limit, offset int) ([]Entity, int, error)
+}
+
+// RuleRepository defines the interface for anomaly detection rule sources.
+type RuleRepository interface {
+	// List returns every rule.
+	List(ctx context.Context) ([]Rule, error)
+}
+
+// Notifier sends an alert to a channel such as a webhook or an email.
+type Notifier interface {
+	// Name identifies the notifier in logs.
+	Name() string
+
+	// Notify sends the alert.
+	Notify(ctx context.Context, data Entity) error
+}
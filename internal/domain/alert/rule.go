@@ -0,0 +1,106 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Rule configures anomaly detection for a metric type. A value is anomalous when it deviates from
+// the mean of the previous Window values of its series by more than Threshold standard deviations
+// (z_score) or by more than Threshold percent of the mean (percent) in Direction.
+type Rule struct {
+	Type       string  `yaml:"type" json:"type"`
+	Method     string  `yaml:"method" json:"method"`
+	Threshold  float64 `yaml:"threshold" json:"threshold"`
+	Direction  string  `yaml:"direction" json:"direction"`
+	Window     int     `yaml:"window" json:"window"`
+	MinHistory int     `yaml:"min_history" json:"min_history"`
+}
+
+// Validate checks the rule and fills in defaults: direction both, a window of 14 values and a
+// history of at least 5 values.
+func (r *Rule) Validate() error {
+	if r.Type == "" {
+		return errors.New("type: cannot be blank")
+	}
+	if r.Method != MethodZScore && r.Method != MethodPercent {
+		return fmt.Errorf("%s: method: must be %s or %s", r.Type, MethodZScore, MethodPercent)
+	}
+	if r.Threshold <= 0 {
+		return fmt.Errorf("%s: threshold: must be positive", r.Type)
+	}
+	switch r.Direction {
+	case "":
+		r.Direction = DirectionBoth
+	case DirectionDown, DirectionUp, DirectionBoth:
+	default:
+		return fmt.Errorf("%s: direction: must be %s, %s or %s", r.Type, DirectionDown, DirectionUp, DirectionBoth)
+	}
+	if r.Window == 0 {
+		r.Window = 14
+	}
+	if r.MinHistory == 0 {
+		r.MinHistory = 5
+	}
+	if r.Window < 2 || r.MinHistory < 2 || r.MinHistory > r.Window {
+		return fmt.Errorf("%s: window and min_history: must be at least 2, min_history at most window", r.Type)
+	}
+	return nil
+}
+
+// Detect compares value with the history of its series, oldest first. It returns the alert
+// fields describing the deviation and whether the value is anomalous.
+func (r Rule) Detect(value float64, history []float64) (Entity, bool) {
+	if len(history) > r.Window {
+		history = history[len(history)-r.Window:]
+	}
+	if len(history) < r.MinHistory {
+		return Entity{}, false
+	}
+
+	var mean float64
+	for _, v := range history {
+		mean += v
+	}
+	mean /= float64(len(history))
+
+	var variance float64
+	for _, v := range history {
+		variance += (v - mean) * (v - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(history)-1))
+
+	var score float64
+	switch r.Method {
+	case MethodZScore:
+		if stddev == 0 {
+			return Entity{}, false
+		}
+		score = (value - mean) / stddev
+	case MethodPercent:
+		if mean == 0 {
+			return Entity{}, false
+		}
+		score = (value - mean) / math.Abs(mean) * 100
+	}
+
+	anomalous := false
+	switch r.Direction {
+	case DirectionDown:
+		anomalous = score <= -r.Threshold
+	case DirectionUp:
+		anomalous = score >= r.Threshold
+	default:
+		anomalous = math.Abs(score) >= r.Threshold
+	}
+
+	return Entity{
+		Value:     value,
+		Baseline:  mean,
+		StdDev:    stddev,
+		Method:    r.Method,
+		Score:     score,
+		Threshold: r.Threshold,
+	}, anomalous
+}
@@ -0,0 +1,173 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Weights of the factors of a bottleneck score. Every factor is first scaled to [0, 1] relative
+// to the stage where it is worst.
+const (
+	weightDwell    = 0.35
+	weightQueue    = 0.25
+	weightOutflow  = 0.2
+	weightRollback = 0.2
+)
+
+// StageLoad is how a stage performed during a week.
+type StageLoad struct {
+	// DwellHours is the median number of hours clients spend in the stage.
+	DwellHours float64 `json:"dwell_hours"`
+
+	// Queue is the number of active clients in the stage at the end of the week.
+	Queue float64 `json:"queue"`
+
+	// Exits is the number of transitions out of the stage during the week.
+	Exits int `json:"exits"`
+
+	// OutflowRate is the share of clients that left the stage during the week, of the clients
+	// that left or are still queued.
+	OutflowRate float64 `json:"outflow_rate"`
+
+	// RollbackRate is the share of exits that went back to an earlier stage.
+	RollbackRate float64 `json:"rollback_rate"`
+
+	// Score combines the factors above, higher is worse.
+	Score float64 `json:"score"`
+}
+
+// Bottleneck is a stage ranked by how much it holds up the pipeline.
+type Bottleneck struct {
+	Rank        int       `json:"rank"`
+	Stage       string    `json:"stage"`
+	Name        string    `json:"name"`
+	Current     StageLoad `json:"current"`
+	Previous    StageLoad `json:"previous"`
+	Change      StageLoad `json:"change"`
+	Explanation string    `json:"explanation"`
+}
+
+// BottleneckReport ranks the stages for the week ending at AsOf.
+type BottleneckReport struct {
+	AsOf   time.Time    `json:"as_of"`
+	Stages []Bottleneck `json:"stages"`
+}
+
+// NewStageLoad derives the outflow and rollback rates of a stage from its exits
+func NewStageLoad(dwellHours, queue float64, exits, rollbacks int) StageLoad {
+	load := StageLoad{DwellHours: dwellHours, Queue: queue, Exits: exits}
+	if total := float64(exits) + queue; total > 0 {
+		load.OutflowRate = float64(exits) / total
+	}
+	if exits > 0 {
+		load.RollbackRate = float64(rollbacks) / float64(exits)
+	}
+	return load
+}
+
+// RankBottlenecks scores every stage for the current and the previous week and ranks the stages
+// by their current score, worst first.
+func RankBottlenecks(nodes []FlowNode, current, previous map[string]StageLoad) []Bottleneck {
+	score(current)
+	score(previous)
+
+	res := make([]Bottleneck, 0, len(nodes))
+	for _, n := range nodes {
+		cur, prev := current[n.ID], previous[n.ID]
+		b := Bottleneck{
+			Stage:    n.ID,
+			Name:     n.Name,
+			Current:  cur,
+			Previous: prev,
+			Change: StageLoad{
+				DwellHours:   cur.DwellHours - prev.DwellHours,
+				Queue:        cur.Queue - prev.Queue,
+				Exits:        cur.Exits - prev.Exits,
+				OutflowRate:  cur.OutflowRate - prev.OutflowRate,
+				RollbackRate: cur.RollbackRate - prev.RollbackRate,
+				Score:        cur.Score - prev.Score,
+			},
+		}
+		b.Explanation = explain(b, current)
+		res = append(res, b)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Current.Score > res[j].Current.Score
+	})
+	for i := range res {
+		res[i].Rank = i + 1
+	}
+
+	return res
+}
+
+// score sets the score of every stage load from its factors relative to the worst stage
+func score(loads map[string]StageLoad) {
+	var maxDwell, maxQueue float64
+	for _, l := range loads {
+		maxDwell = max(maxDwell, l.DwellHours)
+		maxQueue = max(maxQueue, l.Queue)
+	}
+
+	for id, l := range loads {
+		var s float64
+		if maxDwell > 0 {
+			s += weightDwell * l.DwellHours / maxDwell
+		}
+		if maxQueue > 0 {
+			s += weightQueue * l.Queue / maxQueue
+		}
+		// A stage nobody leaves only counts as blocked when someone is waiting in it
+		if l.Queue > 0 || l.Exits > 0 {
+			s += weightOutflow * (1 - l.OutflowRate)
+		}
+		s += weightRollback * l.RollbackRate
+		l.Score = s
+		loads[id] = l
+	}
+}
+
+// explain describes the factors that make a stage a bottleneck and how they moved week over week
+func explain(b Bottleneck, current map[string]StageLoad) string {
+	cur := b.Current
+	if cur.Queue == 0 && cur.Exits == 0 && cur.DwellHours == 0 {
+		return "No clients in or through this stage this week."
+	}
+
+	highestDwell, highestQueue := true, true
+	for id, l := range current {
+		if id == b.Stage {
+			continue
+		}
+		if l.DwellHours > cur.DwellHours {
+			highestDwell = false
+		}
+		if l.Queue > cur.Queue {
+			highestQueue = false
+		}
+	}
+
+	var parts []string
+	dwell := fmt.Sprintf("median dwell time %.1fh (%+.1fh week over week)", cur.DwellHours, b.Change.DwellHours)
+	if highestDwell && cur.DwellHours > 0 {
+		dwell = "longest " + dwell
+	}
+	parts = append(parts, dwell)
+
+	queue := fmt.Sprintf("%.0f clients waiting (%+.0f)", cur.Queue, b.Change.Queue)
+	if highestQueue && cur.Queue > 0 {
+		queue = "largest queue: " + queue
+	}
+	parts = append(parts, queue)
+
+	parts = append(parts, fmt.Sprintf("%.0f%% of clients moved on (%+.0f pp)", cur.OutflowRate*100, b.Change.OutflowRate*100))
+	if cur.RollbackRate > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f%% of exits were rollbacks (%+.0f pp)", cur.RollbackRate*100, b.Change.RollbackRate*100))
+	}
+
+	text := strings.Join(parts, "; ")
+	return strings.ToUpper(text[:1]) + text[1:] + "."
+}
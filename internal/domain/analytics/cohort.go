@@ -0,0 +1,102 @@
+package analytics
+
+import (
+	"TrackMe/internal/domain/metric"
+	"time"
+)
+
+// Cohort metrics
+const (
+	// CohortConversion is the share of a cohort that reached a stage
+	CohortConversion = "conversion"
+	// CohortActive is the share of a cohort that was still active
+	CohortActive = "active"
+)
+
+// CohortMember is a client as seen by a cohort analysis.
+type CohortMember struct {
+	// Registered is the registration time, which decides the cohort of the client.
+	Registered time.Time
+
+	// Reached is the first time the client reached the analysed stage, nil when it has not.
+	Reached *time.Time
+
+	// LastActive is the last time the client was seen, nil when it never was.
+	LastActive *time.Time
+}
+
+// Cohort is a row of a cohort matrix: the clients registered within a period and the share of
+// them that converted or stayed active after every following period.
+type Cohort struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Size  int       `json:"size"`
+
+	// Counts and Values hold, per period offset from the cohort start, the number and the share
+	// of clients that match the metric by the end of that period. Offsets that have not started
+	// yet are omitted.
+	Counts []int     `json:"counts"`
+	Values []float64 `json:"values"`
+}
+
+// CohortMatrix groups clients by registration period.
+type CohortMatrix struct {
+	By      string   `json:"by"`
+	Metric  string   `json:"metric"`
+	Stage   string   `json:"stage,omitempty"`
+	Cohorts []Cohort `json:"cohorts"`
+}
+
+// Cohorts builds one row per period, oldest first, from the members registered within it. After
+// offset n (measured at the end of the n-th period following the cohort start, or at now while
+// it is ongoing) a member counts towards:
+//   - conversion when it reached the stage before that time
+//   - active when it was last seen at or after the start of that period
+func Cohorts(periods []metric.Period, members []CohortMember, metricName string, now time.Time) []Cohort {
+	res := make([]Cohort, len(periods))
+	groups := make([][]CohortMember, len(periods))
+	for _, m := range members {
+		for i, p := range periods {
+			if p.Contains(m.Registered) {
+				groups[i] = append(groups[i], m)
+				break
+			}
+		}
+	}
+
+	for i, p := range periods {
+		row := Cohort{Start: p.Start, End: p.End, Size: len(groups[i])}
+
+		for offset := p; offset.Start.Before(now); offset = offset.Next() {
+			end := offset.End
+			if end.After(now) {
+				end = now
+			}
+
+			var count int
+			for _, m := range groups[i] {
+				switch metricName {
+				case CohortConversion:
+					if m.Reached != nil && m.Reached.Before(end) {
+						count++
+					}
+				case CohortActive:
+					if m.LastActive != nil && !m.LastActive.Before(offset.Start) {
+						count++
+					}
+				}
+			}
+
+			var value float64
+			if row.Size > 0 {
+				value = float64(count) / float64(row.Size)
+			}
+			row.Counts = append(row.Counts, count)
+			row.Values = append(row.Values, value)
+		}
+
+		res[i] = row
+	}
+
+	return res
+}
@@ -0,0 +1,164 @@
+package analytics
+
+import (
+	"TrackMe/internal/domain/transition"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FlowNode is a stage of the pipeline in a Sankey diagram.
+type FlowNode struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Order int    `json:"order"`
+}
+
+// FlowLink is the number of transitions between two stages. Rollback links go back to a stage
+// of a lower order.
+type FlowLink struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Value    int    `json:"value"`
+	Rollback bool   `json:"rollback"`
+}
+
+// FlowPath is a sequence of stages and the number of clients that took exactly that sequence.
+type FlowPath struct {
+	Stages  []string `json:"stages"`
+	Clients int      `json:"clients"`
+}
+
+// FlowBounce counts round trips between two stages: a move from one to the other and back.
+type FlowBounce struct {
+	Stages  [2]string `json:"stages"`
+	Count   int       `json:"count"`
+	Clients int       `json:"clients"`
+}
+
+// Flows is the journey of clients through the pipeline within a time range.
+type Flows struct {
+	From      time.Time    `json:"from"`
+	To        time.Time    `json:"to"`
+	Clients   int          `json:"clients"`
+	Rollbacks int          `json:"rollbacks"`
+	Nodes     []FlowNode   `json:"nodes"`
+	Links     []FlowLink   `json:"links"`
+	Paths     []FlowPath   `json:"paths"`
+	Bounces   []FlowBounce `json:"bounces"`
+}
+
+// BuildFlows aggregates transitions, ordered by client and time, into Sankey nodes and links, the
+// limit most common paths and the round trips between stages. nodes lists the stages of the
+// pipeline; order decides which links are rollbacks.
+func BuildFlows(nodes []FlowNode, events []transition.Entity, limit int) Flows {
+	order := make(map[string]int, len(nodes))
+	for _, n := range nodes {
+		order[n.ID] = n.Order
+	}
+
+	res := Flows{
+		Nodes:   nodes,
+		Links:   []FlowLink{},
+		Paths:   []FlowPath{},
+		Bounces: []FlowBounce{},
+	}
+
+	links := make(map[[2]string]int)
+	paths := make(map[string]int)
+	bounces := make(map[[2]string]*FlowBounce)
+
+	// Per client: the stages it entered, in order
+	for start := 0; start < len(events); {
+		end := start
+		for end < len(events) && events[end].ClientID == events[start].ClientID {
+			end++
+		}
+		journey := events[start:end]
+		start = end
+		res.Clients++
+
+		var path []string
+		bouncedPairs := make(map[[2]string]bool)
+		for i, e := range journey {
+			if e.ToStage == "" {
+				continue
+			}
+			path = append(path, e.ToStage)
+
+			if e.FromStage == "" || e.FromStage == e.ToStage {
+				continue
+			}
+			links[[2]string{e.FromStage, e.ToStage}]++
+			if order[e.ToStage] < order[e.FromStage] {
+				res.Rollbacks++
+			}
+
+			// A move back to the stage the previous transition came from closes a round trip
+			if i > 0 && journey[i-1].ToStage == e.FromStage && journey[i-1].FromStage == e.ToStage {
+				pair := bouncePair(e.FromStage, e.ToStage)
+				b, ok := bounces[pair]
+				if !ok {
+					b = &FlowBounce{Stages: pair}
+					bounces[pair] = b
+				}
+				b.Count++
+				if !bouncedPairs[pair] {
+					bouncedPairs[pair] = true
+					b.Clients++
+				}
+			}
+		}
+		if len(path) > 0 {
+			paths[strings.Join(path, "\x00")]++
+		}
+	}
+
+	for key, value := range links {
+		res.Links = append(res.Links, FlowLink{
+			Source:   key[0],
+			Target:   key[1],
+			Value:    value,
+			Rollback: order[key[1]] < order[key[0]],
+		})
+	}
+	sort.Slice(res.Links, func(i, j int) bool {
+		if res.Links[i].Value != res.Links[j].Value {
+			return res.Links[i].Value > res.Links[j].Value
+		}
+		return res.Links[i].Source+res.Links[i].Target < res.Links[j].Source+res.Links[j].Target
+	})
+
+	for key, clients := range paths {
+		res.Paths = append(res.Paths, FlowPath{Stages: strings.Split(key, "\x00"), Clients: clients})
+	}
+	sort.Slice(res.Paths, func(i, j int) bool {
+		if res.Paths[i].Clients != res.Paths[j].Clients {
+			return res.Paths[i].Clients > res.Paths[j].Clients
+		}
+		return strings.Join(res.Paths[i].Stages, ",") < strings.Join(res.Paths[j].Stages, ",")
+	})
+	if limit > 0 && len(res.Paths) > limit {
+		res.Paths = res.Paths[:limit]
+	}
+
+	for _, b := range bounces {
+		res.Bounces = append(res.Bounces, *b)
+	}
+	sort.Slice(res.Bounces, func(i, j int) bool {
+		if res.Bounces[i].Count != res.Bounces[j].Count {
+			return res.Bounces[i].Count > res.Bounces[j].Count
+		}
+		return res.Bounces[i].Stages[0]+res.Bounces[i].Stages[1] < res.Bounces[j].Stages[0]+res.Bounces[j].Stages[1]
+	})
+
+	return res
+}
+
+// bouncePair orders a pair of stages so both directions of a round trip share a key
+func bouncePair(a, b string) [2]string {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
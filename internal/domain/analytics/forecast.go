@@ -0,0 +1,244 @@
+package analytics
+
+import (
+	"TrackMe/internal/domain/transition"
+	"math"
+	"time"
+)
+
+const (
+	// forecastConfidence is the coverage of the forecast bands, forecastZ its normal quantile
+	forecastConfidence = 0.9
+	forecastZ          = 1.6449
+
+	// expectedDaysLimit bounds the expected time to completion; stages that complete with a
+	// probability below expectedDaysCertainty within it have no expected time
+	expectedDaysLimit     = 3650
+	expectedDaysCertainty = 0.99
+)
+
+// MarkovModel is a daily Markov chain over the stages of the pipeline, fitted on transitions.
+// Every day a client leaves its stage with a probability given by the stage's exit rate and then
+// moves to another stage with the observed transition probabilities. Target is absorbing.
+type MarkovModel struct {
+	Stages []string
+	Target string
+
+	// ExitRate is the number of exits per client-day spent in a stage.
+	ExitRate map[string]float64
+
+	// Next holds per stage the probability of every stage a client leaving it moves to.
+	Next map[string]map[string]float64
+}
+
+// FitMarkov estimates the model from transitions ordered by client and time. Time spent in a
+// stage that has not ended yet counts until now, so exit rates account for clients still waiting.
+func FitMarkov(stages []string, target string, events []transition.Entity, now time.Time) MarkovModel {
+	m := MarkovModel{
+		Stages:   stages,
+		Target:   target,
+		ExitRate: make(map[string]float64),
+		Next:     make(map[string]map[string]float64),
+	}
+
+	exits := make(map[string]map[string]int)
+	totals := make(map[string]int)
+	days := make(map[string]float64)
+
+	for i, e := range events {
+		if e.ToStage == "" {
+			continue
+		}
+		left := now
+		last := i+1 == len(events) || events[i+1].ClientID != e.ClientID
+		if !last {
+			left = events[i+1].CreatedAt
+		}
+		days[e.ToStage] += math.Max(left.Sub(e.CreatedAt).Hours()/24, 0)
+
+		if last || events[i+1].ToStage == e.ToStage {
+			continue
+		}
+		next := events[i+1].ToStage
+		if exits[e.ToStage] == nil {
+			exits[e.ToStage] = make(map[string]int)
+		}
+		exits[e.ToStage][next]++
+		totals[e.ToStage]++
+	}
+
+	for stage, total := range totals {
+		if days[stage] > 0 {
+			m.ExitRate[stage] = float64(total) / days[stage]
+		}
+		m.Next[stage] = make(map[string]float64, len(exits[stage]))
+		for next, count := range exits[stage] {
+			m.Next[stage][next] = float64(count) / float64(total)
+		}
+	}
+
+	return m
+}
+
+// step returns the one-day transition matrix over Stages
+func (m MarkovModel) step() [][]float64 {
+	index := make(map[string]int, len(m.Stages))
+	for i, s := range m.Stages {
+		index[s] = i
+	}
+
+	q := make([][]float64, len(m.Stages))
+	for i, s := range m.Stages {
+		q[i] = make([]float64, len(m.Stages))
+		if s == m.Target {
+			q[i][i] = 1
+			continue
+		}
+
+		leave := 1 - math.Exp(-m.ExitRate[s])
+		q[i][i] = 1 - leave
+		for next, p := range m.Next[s] {
+			j, ok := index[next]
+			if !ok {
+				// Moves to unknown stages keep the client where it is
+				j = i
+			}
+			q[i][j] += leave * p
+		}
+	}
+	return q
+}
+
+// Completion returns, per stage, the probability that a client in it reaches Target within days
+// and the expected days until it does. The expected days are nil when the stage does not lead to
+// Target with near certainty.
+func (m MarkovModel) Completion(days int) (map[string]float64, map[string]*float64) {
+	q := m.step()
+	n := len(q)
+
+	target := -1
+	for i, s := range m.Stages {
+		if s == m.Target {
+			target = i
+		}
+	}
+
+	probability := make(map[string]float64, n)
+	expected := make(map[string]*float64, n)
+	if target < 0 {
+		for _, s := range m.Stages {
+			probability[s] = 0
+		}
+		return probability, expected
+	}
+
+	// power is q^d, expectedSum accumulates P(not completed after d days)
+	power := identity(n)
+	expectedSum := make([]float64, n)
+	for d := 0; d < max(days, expectedDaysLimit); d++ {
+		if d == days {
+			for i, s := range m.Stages {
+				probability[s] = power[i][target]
+			}
+		}
+		for i := range power {
+			expectedSum[i] += 1 - power[i][target]
+		}
+		power = multiply(power, q)
+	}
+	if days >= expectedDaysLimit {
+		for i, s := range m.Stages {
+			probability[s] = power[i][target]
+		}
+	}
+
+	for i, s := range m.Stages {
+		if power[i][target] >= expectedDaysCertainty {
+			v := expectedSum[i]
+			expected[s] = &v
+		}
+	}
+
+	return probability, expected
+}
+
+// StageForecast is the outlook of the clients currently in a stage.
+type StageForecast struct {
+	Stage                    string             `json:"stage"`
+	Clients                  int                `json:"clients"`
+	ExitRate                 float64            `json:"exit_rate"`
+	Transitions              map[string]float64 `json:"transitions"`
+	CompletionProbability    float64            `json:"completion_probability"`
+	ExpectedDaysToCompletion *float64           `json:"expected_days_to_completion"`
+}
+
+// ClientForecast is the probability that a client reaches the target stage within the horizon.
+type ClientForecast struct {
+	ClientID    string  `json:"client_id"`
+	Name        string  `json:"name"`
+	Stage       string  `json:"stage"`
+	Probability float64 `json:"probability"`
+}
+
+// Forecast predicts how many current clients reach the target stage within the horizon.
+type Forecast struct {
+	AsOf                time.Time        `json:"as_of"`
+	HorizonDays         int              `json:"horizon_days"`
+	Target              string           `json:"target"`
+	Transitions         int              `json:"transitions"`
+	ActiveClients       int              `json:"active_clients"`
+	ExpectedCompletions float64          `json:"expected_completions"`
+	Lower               float64          `json:"lower"`
+	Upper               float64          `json:"upper"`
+	Confidence          float64          `json:"confidence"`
+	Stages              []StageForecast  `json:"stages"`
+	Clients             []ClientForecast `json:"clients"`
+}
+
+// NewForecast sums the completion probabilities of the clients into the expected number of
+// completions. The band treats clients as independent (a Poisson binomial distribution) and uses
+// its normal approximation.
+func NewForecast(clients []ClientForecast) Forecast {
+	f := Forecast{
+		ActiveClients: len(clients),
+		Confidence:    forecastConfidence,
+		Clients:       clients,
+	}
+
+	var variance float64
+	for _, c := range clients {
+		f.ExpectedCompletions += c.Probability
+		variance += c.Probability * (1 - c.Probability)
+	}
+
+	margin := forecastZ * math.Sqrt(variance)
+	f.Lower = math.Max(f.ExpectedCompletions-margin, 0)
+	f.Upper = math.Min(f.ExpectedCompletions+margin, float64(len(clients)))
+
+	return f
+}
+
+func identity(n int) [][]float64 {
+	res := make([][]float64, n)
+	for i := range res {
+		res[i] = make([]float64, n)
+		res[i][i] = 1
+	}
+	return res
+}
+
+func multiply(a, b [][]float64) [][]float64 {
+	res := make([][]float64, len(a))
+	for i := range a {
+		res[i] = make([]float64, len(b[0]))
+		for k, v := range a[i] {
+			if v == 0 {
+				continue
+			}
+			for j := range b[k] {
+				res[i][j] += v * b[k][j]
+			}
+		}
+	}
+	return res
+}
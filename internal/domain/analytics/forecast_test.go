@@ -0,0 +1,169 @@
+package analytics
+
+import (
+	"TrackMe/internal/domain/transition"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFitMarkov(t *testing.T) {
+	t0 := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	at := func(clientID, stage string, days int) transition.Entity {
+		return transition.Entity{ClientID: clientID, ToStage: stage, CreatedAt: t0.Add(time.Duration(days) * day)}
+	}
+
+	events := []transition.Entity{
+		// a: 2 days in new, 1 day in contract, then done
+		at("a", "new", 0),
+		at("a", "contract", 2),
+		at("a", "done", 3),
+		// b: still in new, 4 days until now
+		at("b", "new", 0),
+		// c: a repeated stage is no exit, 1 + 1 days in new, then lost
+		at("c", "new", 0),
+		at("c", "new", 1),
+		at("c", "lost", 2),
+		// events without a stage are ignored
+		{ClientID: "d", CreatedAt: t0},
+	}
+
+	m := FitMarkov([]string{"new", "contract", "done", "lost"}, "done", events, t0.Add(4*day))
+
+	wantRates := map[string]float64{
+		"new":      2.0 / 8, // a 2, b 4, c 2 days
+		"contract": 1,
+	}
+	if len(m.ExitRate) != len(wantRates) {
+		t.Errorf("ExitRate = %v, want %v", m.ExitRate, wantRates)
+	}
+	for stage, want := range wantRates {
+		if got := m.ExitRate[stage]; math.Abs(got-want) > 1e-9 {
+			t.Errorf("ExitRate[%s] = %f, want %f", stage, got, want)
+		}
+	}
+
+	wantNext := map[string]map[string]float64{
+		"new":      {"contract": 0.5, "lost": 0.5},
+		"contract": {"done": 1},
+	}
+	if len(m.Next) != len(wantNext) {
+		t.Errorf("Next = %v, want %v", m.Next, wantNext)
+	}
+	for stage, want := range wantNext {
+		if len(m.Next[stage]) != len(want) {
+			t.Errorf("Next[%s] = %v, want %v", stage, m.Next[stage], want)
+			continue
+		}
+		for next, p := range want {
+			if got := m.Next[stage][next]; math.Abs(got-p) > 1e-9 {
+				t.Errorf("Next[%s][%s] = %f, want %f", stage, next, got, p)
+			}
+		}
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	// Half of the clients leave a stage every day
+	half := math.Ln2
+
+	tests := []struct {
+		name        string
+		model       MarkovModel
+		days        int
+		probability map[string]float64
+		expected    map[string]float64 // stages without an entry have no expected days
+	}{
+		{
+			name: "single step",
+			model: MarkovModel{
+				Stages:   []string{"new", "done"},
+				Target:   "done",
+				ExitRate: map[string]float64{"new": half},
+				Next:     map[string]map[string]float64{"new": {"done": 1}},
+			},
+			days:        3,
+			probability: map[string]float64{"new": 0.875, "done": 1},
+			expected:    map[string]float64{"new": 2, "done": 0},
+		},
+		{
+			name: "two steps",
+			model: MarkovModel{
+				Stages:   []string{"new", "contract", "done"},
+				Target:   "done",
+				ExitRate: map[string]float64{"new": half, "contract": half},
+				Next: map[string]map[string]float64{
+					"new":      {"contract": 1},
+					"contract": {"done": 1},
+				},
+			},
+			days: 2,
+			// reaching done within 2 days takes both exits on consecutive days
+			probability: map[string]float64{"new": 0.25, "contract": 0.75, "done": 1},
+			expected:    map[string]float64{"new": 4, "contract": 2, "done": 0},
+		},
+		{
+			name: "half of the clients are lost",
+			model: MarkovModel{
+				Stages:   []string{"new", "lost", "done"},
+				Target:   "done",
+				ExitRate: map[string]float64{"new": half},
+				Next:     map[string]map[string]float64{"new": {"done": 0.5, "lost": 0.5}},
+			},
+			days:        expectedDaysLimit,
+			probability: map[string]float64{"new": 0.5, "lost": 0, "done": 1},
+			expected:    map[string]float64{"done": 0},
+		},
+		{
+			name: "moves to unknown stages stay",
+			model: MarkovModel{
+				Stages:   []string{"new", "done"},
+				Target:   "done",
+				ExitRate: map[string]float64{"new": half},
+				Next:     map[string]map[string]float64{"new": {"archived": 1}},
+			},
+			days:        30,
+			probability: map[string]float64{"new": 0, "done": 1},
+			expected:    map[string]float64{"done": 0},
+		},
+		{
+			name: "target is not a stage",
+			model: MarkovModel{
+				Stages:   []string{"new", "contract"},
+				Target:   "done",
+				ExitRate: map[string]float64{"new": half},
+				Next:     map[string]map[string]float64{"new": {"contract": 1}},
+			},
+			days:        30,
+			probability: map[string]float64{"new": 0, "contract": 0},
+			expected:    map[string]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probability, expected := tt.model.Completion(tt.days)
+
+			for stage, want := range tt.probability {
+				if got := probability[stage]; math.Abs(got-want) > 1e-6 {
+					t.Errorf("probability[%s] = %f, want %f", stage, got, want)
+				}
+			}
+
+			if len(expected) != len(tt.expected) {
+				t.Errorf("expected days of %d stages, want %d", len(expected), len(tt.expected))
+			}
+			for stage, want := range tt.expected {
+				got := expected[stage]
+				if got == nil {
+					t.Errorf("expected[%s] = nil, want %f", stage, want)
+					continue
+				}
+				if math.Abs(*got-want) > 1e-6 {
+					t.Errorf("expected[%s] = %f, want %f", stage, *got, want)
+				}
+			}
+		})
+	}
+}
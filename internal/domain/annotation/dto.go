@@ -0,0 +1,78 @@
+package annotation
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Filters narrows the annotations returned by a list.
+type Filters struct {
+	// From and To select the annotations overlapping the range; zero bounds leave it open.
+	From time.Time
+	To   time.Time
+
+	// Tag selects the annotations carrying the tag.
+	Tag string
+
+	// Source and Channel select the annotations scoped to them together with the unscoped ones.
+	Source  string
+	Channel string
+}
+
+// Request represents the request payload for creating or replacing an annotation.
+type Request struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Source      string    `json:"source"`
+	Channel     string    `json:"channel"`
+}
+
+// Bind validates the request payload. A missing end makes the annotation a moment.
+func (req *Request) Bind(r *http.Request) error {
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		return errors.New("title: cannot be blank")
+	}
+	if req.StartsAt.IsZero() {
+		return errors.New("starts_at: cannot be blank")
+	}
+	if req.EndsAt.IsZero() {
+		req.EndsAt = req.StartsAt
+	}
+	if req.EndsAt.Before(req.StartsAt) {
+		return errors.New("ends_at: must not be before starts_at")
+	}
+
+	// Tags are trimmed, lower-cased and deduplicated so that filtering by tag is predictable
+	tags := make([]string, 0, len(req.Tags))
+	seen := make(map[string]bool, len(req.Tags))
+	for _, tag := range req.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	req.Tags = tags
+
+	return nil
+}
+
+// New creates a new Entity from a request.
+func New(req Request) Entity {
+	return Entity{
+		Title:       req.Title,
+		Description: req.Description,
+		Tags:        req.Tags,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		Source:      req.Source,
+		Channel:     req.Channel,
+	}
+}
@@ -0,0 +1,39 @@
+package annotation
+
+import "time"
+
+// Entity marks a time range on the metric charts, e.g. a marketing campaign or a release.
+type Entity struct {
+	// ID is the unique identifier for the annotation (UUID).
+	ID string `db:"id" json:"id"`
+
+	Title       string   `db:"title" json:"title"`
+	Description string   `db:"description" json:"description,omitempty"`
+	Tags        []string `db:"tags" json:"tags"`
+
+	// StartsAt and EndsAt bound the annotated range; a moment has equal bounds.
+	StartsAt time.Time `db:"starts_at" json:"starts_at"`
+	EndsAt   time.Time `db:"ends_at" json:"ends_at"`
+
+	// Source and Channel scope the annotation to the metrics of one acquisition source or
+	// channel; empty values apply it to every metric.
+	Source  string `db:"source" json:"source,omitempty"`
+	Channel string `db:"channel" json:"channel,omitempty"`
+
+	// CreatedBy is the user who created the annotation; it is cleared when the user is deleted.
+	CreatedBy *string `db:"created_by" json:"created_by"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Overlaps reports whether the annotation overlaps the range. Zero bounds leave the range open.
+func (e Entity) Overlaps(from, to time.Time) bool {
+	if !to.IsZero() && e.StartsAt.After(to) {
+		return false
+	}
+	if !from.IsZero() && e.EndsAt.Before(from) {
+		return false
+	}
+	return true
+}
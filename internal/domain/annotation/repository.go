@@ -0,0 +1,23 @@
+package annotation
+
+import (
+	"context"
+)
+
+// Repository defines the interface for annotation persistence.
+type Repository interface {
+	// Create stores an annotation.
+	Create(ctx context.Context, data Entity) (Entity, error)
+
+	// Get retrieves an annotation by its ID.
+	Get(ctx context.Context, id string) (Entity, error)
+
+	// List retrieves the annotations matching the filters, ordered by start.
+	List(ctx context.Context, filters Filters) ([]Entity, error)
+
+	// Update replaces an annotation by its ID.
+	Update(ctx context.Context, id string, data Entity) (Entity, error)
+
+	// Delete removes an annotation by its ID.
+	Delete(ctx context.Context, id string) error
+}
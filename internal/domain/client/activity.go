@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Activity events
+const (
+	Deactivated = "deactivated"
+	Reactivated = "reactivated"
+)
+
+// ReasonInactive is the deactivation reason of clients deactivated for not logging in.
+const ReasonInactive = "inactive"
+
+// Activity is a client being deactivated or reactivated.
+type Activity struct {
+	ClientID string `db:"client_id" json:"client_id"`
+
+	// Event is deactivated or reactivated.
+	Event string `db:"event" json:"event"`
+
+	// Stage is the stage the client was in.
+	Stage string `db:"stage" json:"stage"`
+
+	// Reason is an optional reason code of the taxonomy.
+	Reason  string `db:"reason" json:"reason_code,omitempty"`
+	Comment string `db:"comment" json:"comment,omitempty"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ActivityRequest represents the request payload for deactivating or reactivating a client.
+type ActivityRequest struct {
+	Reason  string `json:"reason_code"`
+	Comment string `json:"comment"`
+}
+
+// Bind validates the request payload. Reason codes are checked against the reason taxonomy by the service.
+func (req *ActivityRequest) Bind(r *http.Request) error {
+	req.Reason = strings.TrimSpace(req.Reason)
+	req.Comment = strings.TrimSpace(req.Comment)
+	return nil
+}
+
+// ErrAlreadyInState is returned when a client is deactivated while inactive or reactivated while active.
+var ErrAlreadyInState = errors.New("client is already in the requested state")
+
+// ActivityRepository defines the interface for client activity event storage.
+type ActivityRepository interface {
+	// Add inserts an activity event.
+	Add(ctx context.Context, data Activity) error
+
+	// List retrieves the activity events up to asOf inclusive, ordered by client and time.
+	List(ctx context.Context, asOf time.Time) ([]Activity, error)
+}
+
+// ActivityCounts tallies the clients of a period by their state at its start and their changes in it.
+type ActivityCounts struct {
+	ActiveAtStart   int
+	Churned         int
+	InactiveAtStart int
+	Reactivated     int
+}
+
+// CountActivity tallies the clients registered before start by the activity events up to asOf,
+// given per client in time order. Clients without events fall back to their current state, an
+// inactive client last updated between start and asOf counting as churned.
+func CountActivity(clients []Entity, events map[string][]Activity, start, asOf time.Time) ActivityCounts {
+	var res ActivityCounts
+	for _, c := range clients {
+		// Clients registered during the period were not there at its start
+		if c.RegistrationDate != nil && !c.RegistrationDate.Before(start) {
+			continue
+		}
+
+		history := events[c.ID]
+		if len(history) == 0 {
+			inactive := c.IsActive != nil && !*c.IsActive
+			switch {
+			case !inactive:
+				res.ActiveAtStart++
+			case c.LastUpdated != nil && !c.LastUpdated.Before(start) && !c.LastUpdated.After(asOf):
+				res.ActiveAtStart++
+				res.Churned++
+			default:
+				res.InactiveAtStart++
+			}
+			continue
+		}
+
+		// The state at the start is the one left by the last event before it. Without one it is the
+		// state the first event in the period switched away from: inactive before a reactivation,
+		// active before a deactivation.
+		wasActive := history[0].Event != Reactivated
+		var deactivatedIn, reactivatedIn bool
+		for _, e := range history {
+			if e.CreatedAt.Before(start) {
+				wasActive = e.Event != Deactivated
+				continue
+			}
+			switch e.Event {
+			case Deactivated:
+				deactivatedIn = true
+			case Reactivated:
+				reactivatedIn = true
+			}
+		}
+
+		if wasActive {
+			res.ActiveAtStart++
+			if deactivatedIn {
+				res.Churned++
+			}
+		} else {
+			res.InactiveAtStart++
+			if reactivatedIn {
+				res.Reactivated++
+			}
+		}
+	}
+	return res
+}
+
+// ChurnRate returns the share of the clients active at the start that churned, 0 without any.
+func (c ActivityCounts) ChurnRate() float64 {
+	if c.ActiveAtStart == 0 {
+		return 0
+	}
+	return float64(c.Churned) / float64(c.ActiveAtStart)
+}
+
+// ReactivationRate returns the share of the clients inactive at the start that were reactivated, 0 without any.
+func (c ActivityCounts) ReactivationRate() float64 {
+	if c.InactiveAtStart == 0 {
+		return 0
+	}
+	return float64(c.Reactivated) / float64(c.InactiveAtStart)
+}
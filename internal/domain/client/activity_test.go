@@ -0,0 +1,142 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCountActivity(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC)
+	day := 24 * time.Hour
+
+	at := func(d time.Duration) *time.Time {
+		t := start.Add(d)
+		return &t
+	}
+	active := func(v bool) *bool { return &v }
+	event := func(clientID, name string, d time.Duration) Activity {
+		return Activity{ClientID: clientID, Event: name, CreatedAt: start.Add(d)}
+	}
+
+	tests := []struct {
+		name     string
+		clients  []Entity
+		events   map[string][]Activity
+		expected ActivityCounts
+	}{
+		{
+			name:     "active without events",
+			clients:  []Entity{{ID: "a", IsActive: active(true)}},
+			expected: ActivityCounts{ActiveAtStart: 1},
+		},
+		{
+			name:     "unknown state counts as active",
+			clients:  []Entity{{ID: "a"}},
+			expected: ActivityCounts{ActiveAtStart: 1},
+		},
+		{
+			name:     "inactive without events updated in the period churned",
+			clients:  []Entity{{ID: "a", IsActive: active(false), LastUpdated: at(5 * day)}},
+			expected: ActivityCounts{ActiveAtStart: 1, Churned: 1},
+		},
+		{
+			name:     "inactive without events updated before the period",
+			clients:  []Entity{{ID: "a", IsActive: active(false), LastUpdated: at(-5 * day)}},
+			expected: ActivityCounts{InactiveAtStart: 1},
+		},
+		{
+			name:     "registered in the period",
+			clients:  []Entity{{ID: "a", IsActive: active(false), RegistrationDate: at(day), LastUpdated: at(2 * day)}},
+			expected: ActivityCounts{},
+		},
+		{
+			name:     "deactivated in the period",
+			clients:  []Entity{{ID: "a", IsActive: active(false)}},
+			events:   map[string][]Activity{"a": {event("a", Deactivated, 3*day)}},
+			expected: ActivityCounts{ActiveAtStart: 1, Churned: 1},
+		},
+		{
+			name:     "reactivated in the period",
+			clients:  []Entity{{ID: "a", IsActive: active(true)}},
+			events:   map[string][]Activity{"a": {event("a", Reactivated, 3*day)}},
+			expected: ActivityCounts{InactiveAtStart: 1, Reactivated: 1},
+		},
+		{
+			name:    "deactivated before and reactivated in the period",
+			clients: []Entity{{ID: "a", IsActive: active(true)}},
+			events: map[string][]Activity{"a": {
+				event("a", Deactivated, -10*day),
+				event("a", Reactivated, 3*day),
+			}},
+			expected: ActivityCounts{InactiveAtStart: 1, Reactivated: 1},
+		},
+		{
+			name:    "reactivated before and deactivated in the period",
+			clients: []Entity{{ID: "a", IsActive: active(false)}},
+			events: map[string][]Activity{"a": {
+				event("a", Deactivated, -20*day),
+				event("a", Reactivated, -10*day),
+				event("a", Deactivated, 3*day),
+			}},
+			expected: ActivityCounts{ActiveAtStart: 1, Churned: 1},
+		},
+		{
+			name:    "deactivated and reactivated in the period",
+			clients: []Entity{{ID: "a", IsActive: active(true)}},
+			events: map[string][]Activity{"a": {
+				event("a", Deactivated, 3*day),
+				event("a", Reactivated, 5*day),
+			}},
+			expected: ActivityCounts{ActiveAtStart: 1, Churned: 1},
+		},
+		{
+			name:     "events before the period only",
+			clients:  []Entity{{ID: "a", IsActive: active(false)}},
+			events:   map[string][]Activity{"a": {event("a", Deactivated, -3*day)}},
+			expected: ActivityCounts{InactiveAtStart: 1},
+		},
+		{
+			name: "clients with and without events",
+			clients: []Entity{
+				{ID: "a", IsActive: active(true)},
+				{ID: "b", IsActive: active(false), LastUpdated: at(-day)},
+				{ID: "c", IsActive: active(true)},
+			},
+			events:   map[string][]Activity{"c": {event("c", Reactivated, day)}},
+			expected: ActivityCounts{ActiveAtStart: 1, InactiveAtStart: 2, Reactivated: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CountActivity(tt.clients, tt.events, start, asOf)
+			if got != tt.expected {
+				t.Errorf("CountActivity() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestActivityRates(t *testing.T) {
+	tests := []struct {
+		name                string
+		counts              ActivityCounts
+		churn, reactivation float64
+	}{
+		{"no clients", ActivityCounts{}, 0, 0},
+		{"quarter churned", ActivityCounts{ActiveAtStart: 8, Churned: 2}, 0.25, 0},
+		{"half reactivated", ActivityCounts{ActiveAtStart: 1, InactiveAtStart: 4, Reactivated: 2}, 0, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.counts.ChurnRate(); got != tt.churn {
+				t.Errorf("ChurnRate() = %f, want %f", got, tt.churn)
+			}
+			if got := tt.counts.ReactivationRate(); got != tt.reactivation {
+				t.Errorf("ReactivationRate() = %f, want %f", got, tt.reactivation)
+			}
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"net/http"
 	"regexp"
+	"strings"
 	"time"
+	"unicode"
 )
 
 type Filters struct {
@@ -19,7 +21,17 @@ type Filters struct {
 	IsActive       *bool
 	UpdatedAfter   time.Time
 	LastLoginAfter time.Time
+	MinScore       *int
+	Tag            string
+	AssigneeID     string
+
+	// Sort orders the clients by SortScore or, when empty, by last update, newest first.
+	Sort string
 }
+
+// SortScore orders clients by score, highest first.
+const SortScore = "score"
+
 type Request struct {
 	Name      string             `json:"name"`
 	Email     string             `json:"email"`
@@ -30,6 +42,10 @@ type Request struct {
 	App       string             `json:"app"`
 	LastLogin time.Time          `json:"last_login"`
 	Contracts []contract.Request `json:"contracts"`
+
+	// ReasonCode and Comment explain a move back to the previous stage (stage "prev").
+	ReasonCode string `json:"reason_code"`
+	Comment    string `json:"comment"`
 }
 
 // Bind validates the request payload.
@@ -45,6 +61,10 @@ func (s *Request) Bind(r *http.Request) error {
 	if !emailRegex.MatchString(s.Email) {
 		return errors.New("email: invalid format")
 	}
+	// The name ends up in notification headers, e.g. rule email subjects
+	if strings.IndexFunc(s.Name, unicode.IsControl) >= 0 {
+		return errors.New("name: must not contain control characters")
+	}
 	return nil
 }
 
@@ -62,6 +82,9 @@ type Response struct {
 	App              app.Response        `json:"app"`
 	LastLogin        lastLogin.Response  `json:"last_login"`
 	Contracts        []contract.Response `json:"contracts"`
+	Score            *int                `json:"score"`
+	Tags             []string            `json:"tags"`
+	AssigneeID       *string             `json:"assignee_id"`
 }
 
 // ParseFromEntity converts a client entity to a response payload.
@@ -135,6 +158,14 @@ func ParseFromEntity(data Entity) Response {
 		resp.Channel = *data.Channel
 	}
 
+	resp.Score = data.Score
+	resp.AssigneeID = data.AssigneeID
+
+	resp.Tags = data.Tags
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+
 	return resp
 }
 
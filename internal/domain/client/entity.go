@@ -42,6 +42,15 @@ type Entity struct {
 
 	// Contracts is a list of contracts associated with the client.
 	Contracts []contract.Entity `db:"contracts" bson:"contracts"`
+
+	// Score is the lead score of the client from 0 to 100, nil when scoring is not configured.
+	Score *int `db:"score" bson:"score"`
+
+	// Tags are labels set on the client, e.g. by automation rules.
+	Tags []string `db:"tags" bson:"tags"`
+
+	// AssigneeID is the user responsible for the client.
+	AssigneeID *string `db:"assignee_id" bson:"assignee_id"`
 }
 
 // New creates a new Client instance.
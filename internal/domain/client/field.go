@@ -0,0 +1,133 @@
+package client
+
+import (
+	"strconv"
+	"time"
+)
+
+// Field names accepted by Field, DateField, NumericField and BusinessNumericField.
+var (
+	Fields                = []string{"stage", "source", "channel", "app", "is_active"}
+	DateFields            = []string{"registration_date", "last_updated", "last_login"}
+	NumericFields         = []string{"contracts", "monthly_amount", "lifetime_days", "days_in_stage", "days_since_login"}
+	BusinessNumericFields = []string{"business_hours_in_stage", "business_lifetime_hours"}
+)
+
+// Field returns the value of a categorical client field by name: stage, source, channel,
+// app or is_active. The second result is false for unknown or unset fields.
+func (e Entity) Field(name string) (string, bool) {
+	var value *string
+	switch name {
+	case "stage":
+		value = e.CurrentStage
+	case "source":
+		value = e.Source
+	case "channel":
+		value = e.Channel
+	case "app":
+		value = e.App
+	case "is_active":
+		if e.IsActive == nil {
+			return "", false
+		}
+		return strconv.FormatBool(*e.IsActive), true
+	default:
+		return "", false
+	}
+
+	if value == nil {
+		return "", false
+	}
+	return *value, true
+}
+
+// DateField returns the value of a client date field by name: registration_date,
+// last_updated or last_login. The second result is false for unknown or unset fields.
+func (e Entity) DateField(name string) (time.Time, bool) {
+	var value *time.Time
+	switch name {
+	case "registration_date":
+		value = e.RegistrationDate
+	case "last_updated":
+		value = e.LastUpdated
+	case "last_login":
+		value = e.LastLogin
+	default:
+		return time.Time{}, false
+	}
+
+	if value == nil || value.IsZero() {
+		return time.Time{}, false
+	}
+	return *value, true
+}
+
+// NumericField returns the value of a numeric client field by name as of t:
+//   - contracts: number of contracts
+//   - monthly_amount: monthly amount of contracts active at t, in their own currencies
+//   - lifetime_days: days from registration to the last update
+//   - days_in_stage: days since the last update
+//   - days_since_login: days since the last login
+//
+// The second result is false for unknown fields or when the underlying dates are unset.
+func (e Entity) NumericField(name string, t time.Time) (float64, bool) {
+	switch name {
+	case "contracts":
+		return float64(len(e.Contracts)), true
+	case "monthly_amount":
+		var amount float64
+		for _, c := range e.Contracts {
+			if c.IsActiveAt(t) {
+				amount += c.MonthlyAmount()
+			}
+		}
+		return amount, true
+	case "lifetime_days":
+		registered, ok := e.DateField("registration_date")
+		updated, ok2 := e.DateField("last_updated")
+		if !ok || !ok2 {
+			return 0, false
+		}
+		return updated.Sub(registered).Hours() / 24, true
+	case "days_in_stage":
+		updated, ok := e.DateField("last_updated")
+		if !ok {
+			return 0, false
+		}
+		return t.Sub(updated).Hours() / 24, true
+	case "days_since_login":
+		login, ok := e.DateField("last_login")
+		if !ok {
+			return 0, false
+		}
+		return t.Sub(login).Hours() / 24, true
+	default:
+		return 0, false
+	}
+}
+
+// BusinessNumericField returns the value of a business-time client field by name as of t,
+// measuring durations with within (typically a calendar's BusinessDuration):
+//   - business_hours_in_stage: working hours since the last update
+//   - business_lifetime_hours: working hours from registration to the last update
+//
+// The second result is false for unknown fields or when the underlying dates are unset.
+func (e Entity) BusinessNumericField(name string, t time.Time, within func(from, to time.Time) time.Duration) (float64, bool) {
+	switch name {
+	case "business_hours_in_stage":
+		updated, ok := e.DateField("last_updated")
+		if !ok {
+			return 0, false
+		}
+		return within(updated, t).Hours(), true
+	case "business_lifetime_hours":
+		registered, ok := e.DateField("registration_date")
+		updated, ok2 := e.DateField("last_updated")
+		if !ok || !ok2 {
+			return 0, false
+		}
+		return within(registered, updated).Hours(), true
+	default:
+		return 0, false
+	}
+}
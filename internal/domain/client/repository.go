@@ -8,9 +8,13 @@ import (
 
 // Repository defines the interface for client repository operations.
 type Repository interface {
-	// List retrieves all client entities.
+	// List retrieves a page of the client entities matching the filters and their total number.
+	// A non-positive limit defaults to 10.
 	List(ctx context.Context, filters Filters, limit, offset int) ([]Entity, int, error)
 
+	// ListAll retrieves every client entity matching the filters.
+	ListAll(ctx context.Context, filters Filters) ([]Entity, error)
+
 	// Create creates a new client entity.
 	Create(ctx context.Context, data Entity) (Entity, error)
 
@@ -23,6 +27,15 @@ type Repository interface {
 	// Update modifies an existing client entity by its ID.
 	Update(ctx context.Context, id string, data Entity) (Entity, error)
 
+	// UpdateScore sets the score of a client without touching its last update.
+	UpdateScore(ctx context.Context, id string, score *int) error
+
+	// UpdateTags replaces the tags of a client without touching its last update.
+	UpdateTags(ctx context.Context, id string, tags []string) error
+
+	// Assign sets the user responsible for a client without touching its last update.
+	Assign(ctx context.Context, id string, userID string) error
+
 	// Count returns the total number of client entities matching the filter.
 	Count(ctx context.Context, filter bson.M) (int64, error)
 
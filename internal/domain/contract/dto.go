@@ -4,9 +4,12 @@ import (
 	"TrackMe/internal/domain/autopayment"
 	"errors"
 	"net/http"
+	"regexp"
 	"time"
 )
 
+var currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
 // Request represents the request payload for contract operations.
 type Request struct {
 	ID               string    `json:"id"`
@@ -16,6 +19,7 @@ type Request struct {
 	ConclusionDate   time.Time `json:"conclusion_date"`
 	ExpirationDate   time.Time `json:"expiration_date"`
 	Amount           float64   `json:"amount"`
+	Currency         string    `json:"currency"`
 	PaymentFrequency string    `json:"payment_frequency"`
 	AutoPayment      string    `json:"autopayment"`
 }
@@ -40,6 +44,9 @@ func (req *Request) Bind(r *http.Request) error {
 	if req.Amount <= 0 {
 		return errors.New("amount: must be greater than zero")
 	}
+	if req.Currency != "" && !currencyRegex.MatchString(req.Currency) {
+		return errors.New("currency: must be an ISO 4217 code (e.g., KZT, USD)")
+	}
 	if req.PaymentFrequency == "" {
 		return errors.New("payment_frequency: cannot be blank")
 	}
@@ -58,6 +65,7 @@ type Response struct {
 	ConclusionDate   time.Time            `json:"conclusion_date"`
 	ExpirationDate   time.Time            `json:"expiration_date"`
 	Amount           float64              `json:"amount"`
+	Currency         string               `json:"currency,omitempty"`
 	PaymentFrequency string               `json:"payment_frequency"`
 	AutoPayment      autopayment.Response `json:"autopayment"`
 }
@@ -65,6 +73,13 @@ type Response struct {
 // ParseFromEntity creates a new Response from a given Entity.
 func ParseFromEntity(entity Entity) Response {
 	autoPaymentEntity := autopayment.Entity{Status: *entity.AutoPayment}
+
+	// Contracts stored before currencies were introduced have no currency
+	var currency string
+	if entity.Currency != nil {
+		currency = *entity.Currency
+	}
+
 	return Response{
 		ID:               entity.ID,
 		Name:             *entity.Name,
@@ -73,6 +88,7 @@ func ParseFromEntity(entity Entity) Response {
 		ConclusionDate:   *entity.ConclusionDate,
 		ExpirationDate:   *entity.ExpirationDate,
 		Amount:           *entity.Amount,
+		Currency:         currency,
 		PaymentFrequency: *entity.PaymentFrequency,
 		AutoPayment:      autopayment.ParseFromEntity(autoPaymentEntity),
 	}
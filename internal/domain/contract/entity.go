@@ -27,6 +27,9 @@ type Entity struct {
 	// Amount is the payment amount for the contract (numeric, in currency).
 	Amount *float64 `db:"amount" bson:"amount"`
 
+	// Currency is the ISO 4217 code of Amount; empty means the configured base currency.
+	Currency *string `db:"currency" bson:"currency"`
+
 	// PaymentFrequency is the payment frequency (e.g., monthly, quarterly, annually).
 	PaymentFrequency *string `db:"payment_frequency" bson:"payment_frequency"`
 
@@ -44,7 +47,46 @@ func New(req Request) Entity {
 		ConclusionDate:   &req.ConclusionDate,
 		ExpirationDate:   &req.ExpirationDate,
 		Amount:           &req.Amount,
+		Currency:         &req.Currency,
 		PaymentFrequency: &req.PaymentFrequency,
 		AutoPayment:      &req.AutoPayment,
 	}
 }
+
+// monthlyFactor maps a payment frequency to the number of payments per month.
+var monthlyFactor = map[string]float64{
+	"daily":        365.0 / 12,
+	"weekly":       52.0 / 12,
+	"monthly":      1,
+	"quarterly":    1.0 / 3,
+	"semiannually": 1.0 / 6,
+	"annually":     1.0 / 12,
+	"yearly":       1.0 / 12,
+}
+
+// MonthlyAmount returns the contract amount normalized to one month by its payment frequency.
+// Contracts with an unknown or one-time frequency are not recurring and return zero.
+func (e Entity) MonthlyAmount() float64 {
+	if e.Amount == nil || e.PaymentFrequency == nil {
+		return 0
+	}
+	factor, ok := monthlyFactor[*e.PaymentFrequency]
+	if !ok {
+		return 0
+	}
+	return *e.Amount * factor
+}
+
+// IsActiveAt reports whether the contract is in force at the given time.
+func (e Entity) IsActiveAt(t time.Time) bool {
+	if e.Status != nil && (*e.Status == "cancelled" || *e.Status == "pending") {
+		return false
+	}
+	if e.ConclusionDate != nil && e.ConclusionDate.After(t) {
+		return false
+	}
+	if e.ExpirationDate != nil && !e.ExpirationDate.IsZero() && !e.ExpirationDate.After(t) {
+		return false
+	}
+	return true
+}
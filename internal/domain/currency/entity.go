@@ -0,0 +1,60 @@
+package currency
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrUnknownCurrency is returned when no exchange rate is known for a currency.
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Rates represents exchange rates relative to a base currency on a given date.
+type Rates struct {
+	// Base is the ISO 4217 code all rates are quoted against (e.g., KZT).
+	Base string `json:"base" yaml:"base"`
+
+	// Date is the day the rates are valid for.
+	Date time.Time `json:"date" yaml:"date"`
+
+	// Values maps an ISO 4217 code to the amount of that currency for one unit of Base.
+	Values map[string]float64 `json:"rates" yaml:"rates"`
+}
+
+// Convert converts amount expressed in the from currency into the base currency.
+// An empty currency is treated as the base currency.
+func (r Rates) Convert(amount float64, from string) (float64, error) {
+	from = strings.ToUpper(from)
+	if from == "" || from == r.Base {
+		return amount, nil
+	}
+
+	rate, ok := r.Values[from]
+	if !ok || rate <= 0 {
+		return 0, ErrUnknownCurrency
+	}
+
+	return amount / rate, nil
+}
+
+// Rebase returns the same rates quoted against another base currency.
+func (r Rates) Rebase(base string) (Rates, error) {
+	base = strings.ToUpper(base)
+	if base == r.Base {
+		return r, nil
+	}
+
+	rate, ok := r.Values[base]
+	if !ok || rate <= 0 {
+		return Rates{}, ErrUnknownCurrency
+	}
+
+	values := make(map[string]float64, len(r.Values)+1)
+	for code, value := range r.Values {
+		values[code] = value / rate
+	}
+	values[r.Base] = 1 / rate
+	values[base] = 1
+
+	return Rates{Base: base, Date: r.Date, Values: values}, nil
+}
@@ -0,0 +1,12 @@
+package currency
+
+import (
+	"context"
+	"time"
+)
+
+// Provider defines the interface for exchange-rate sources.
+type Provider interface {
+	// Rates returns exchange rates quoted against base that are valid on date.
+	Rates(ctx context.Context, base string, date time.Time) (Rates, error)
+}
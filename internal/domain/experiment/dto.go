@@ -0,0 +1,76 @@
+package experiment
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Request represents the request payload for creating an experiment.
+type Request struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	GoalStage   string    `json:"goal_stage"`
+	Variants    []Variant `json:"variants"`
+}
+
+// Bind validates the request payload. Variants without a weight get a weight of 1, splitting
+// the clients evenly.
+func (req *Request) Bind(r *http.Request) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("name: cannot be blank")
+	}
+	if len(req.Variants) < 2 {
+		return errors.New("variants: at least two variants are required")
+	}
+
+	seen := make(map[string]bool, len(req.Variants))
+	for i := range req.Variants {
+		v := &req.Variants[i]
+		v.Name = strings.TrimSpace(v.Name)
+		if v.Name == "" {
+			return fmt.Errorf("variants[%d].name: cannot be blank", i)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("variants[%d].name: duplicate variant %s", i, v.Name)
+		}
+		seen[v.Name] = true
+
+		switch {
+		case v.Weight == 0:
+			v.Weight = 1
+		case v.Weight < 0:
+			return fmt.Errorf("variants[%d].weight: must be positive", i)
+		}
+	}
+	return nil
+}
+
+// New creates a new Entity from a request.
+func New(req Request) Entity {
+	return Entity{
+		Name:        req.Name,
+		Description: req.Description,
+		GoalStage:   req.GoalStage,
+		Variants:    req.Variants,
+	}
+}
+
+// AssignRequest represents the request payload for assigning a client to a variant. Without a
+// variant the client is assigned by hashing its ID.
+type AssignRequest struct {
+	ClientID string `json:"client_id"`
+	Variant  string `json:"variant"`
+}
+
+// Bind validates the request payload.
+func (req *AssignRequest) Bind(r *http.Request) error {
+	req.ClientID = strings.TrimSpace(req.ClientID)
+	if req.ClientID == "" {
+		return errors.New("client_id: cannot be blank")
+	}
+	req.Variant = strings.TrimSpace(req.Variant)
+	return nil
+}
@@ -0,0 +1,82 @@
+package experiment
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Assignment methods
+const (
+	MethodHash   = "hash"
+	MethodManual = "manual"
+)
+
+// Entity represents an A/B experiment on the client funnel.
+type Entity struct {
+	// ID is the unique identifier for the experiment (UUID).
+	ID string `db:"id" json:"id"`
+
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description,omitempty"`
+
+	// GoalStage is the stage whose reach counts as a conversion.
+	GoalStage string `db:"goal_stage" json:"goal_stage"`
+
+	// Variants split the clients by weight; the first one is the control.
+	Variants []Variant `db:"variants" json:"variants"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Variant is an arm of an experiment.
+type Variant struct {
+	Name string `json:"name"`
+
+	// Weight is the share of the clients hashed into the variant relative to the other weights.
+	Weight int `json:"weight"`
+}
+
+// Assignment places a client in a variant of an experiment.
+type Assignment struct {
+	ExperimentID string `db:"experiment_id" json:"experiment_id"`
+	ClientID     string `db:"client_id" json:"client_id"`
+	Variant      string `db:"variant" json:"variant"`
+
+	// Method is hash for a deterministic assignment by client ID, manual for an explicit one.
+	Method     string    `db:"method" json:"method"`
+	AssignedAt time.Time `db:"assigned_at" json:"assigned_at"`
+}
+
+// HasVariant reports whether the experiment has a variant with the name.
+func (e Entity) HasVariant(name string) bool {
+	for _, v := range e.Variants {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Variant deterministically picks the variant of a client by hashing the experiment and client
+// IDs, so that a client keeps its variant and lands in different variants across experiments.
+func (e Entity) Variant(clientID string) string {
+	var total int
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(e.ID + ":" + clientID))
+	bucket := int(h.Sum64() % uint64(total))
+
+	for _, v := range e.Variants {
+		if bucket < v.Weight {
+			return v.Name
+		}
+		bucket -= v.Weight
+	}
+	return e.Variants[len(e.Variants)-1].Name
+}
@@ -0,0 +1,26 @@
+package experiment
+
+import (
+	"context"
+)
+
+// Repository defines the interface for experiment persistence.
+type Repository interface {
+	// Create stores an experiment.
+	Create(ctx context.Context, data Entity) (Entity, error)
+
+	// Get retrieves an experiment by its ID.
+	Get(ctx context.Context, id string) (Entity, error)
+
+	// List retrieves every experiment, newest first.
+	List(ctx context.Context) ([]Entity, error)
+
+	// Assign stores the assignment of a client, replacing its previous one in the experiment.
+	Assign(ctx context.Context, data Assignment) (Assignment, error)
+
+	// Assignment retrieves the assignment of a client in an experiment.
+	Assignment(ctx context.Context, experimentID, clientID string) (Assignment, error)
+
+	// Assignments retrieves every assignment of an experiment.
+	Assignments(ctx context.Context, experimentID string) ([]Assignment, error)
+}
@@ -0,0 +1,211 @@
+package experiment
+
+import (
+	"TrackMe/internal/domain/metric"
+	"math"
+	"time"
+)
+
+// DefaultAlpha is the significance level of the results when none is requested.
+const DefaultAlpha = 0.05
+
+// Stage is a stage of the funnel an experiment is measured on.
+type Stage struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Order int    `json:"order"`
+}
+
+// Outcome is what happened to an assigned client after its assignment.
+type Outcome struct {
+	// Reached is the highest stage order the client was in at or after its assignment.
+	Reached int
+
+	// Stays are the hours spent per stage entered after the assignment.
+	Stays map[string][]float64
+}
+
+// Results reports the funnel of every variant and compares its conversion with the control.
+type Results struct {
+	ExperimentID string          `json:"experiment_id"`
+	Name         string          `json:"name"`
+	GoalStage    string          `json:"goal_stage"`
+	Control      string          `json:"control"`
+	Alpha        float64         `json:"alpha"`
+	Variants     []VariantResult `json:"variants"`
+	CalculatedAt time.Time       `json:"calculated_at"`
+}
+
+// VariantResult is the funnel of the clients assigned to a variant.
+type VariantResult struct {
+	Variant        string          `json:"variant"`
+	Clients        int             `json:"clients"`
+	Converted      int             `json:"converted"`
+	ConversionRate float64         `json:"conversion_rate"`
+	Funnel         []FunnelStep    `json:"funnel"`
+	Durations      []StageDuration `json:"durations"`
+
+	// Comparison is the difference from the control, nil for the control itself.
+	Comparison *Comparison `json:"comparison,omitempty"`
+}
+
+// FunnelStep is the number and share of assigned clients that reached a stage or a later one.
+type FunnelStep struct {
+	Stage   string  `json:"stage"`
+	Name    string  `json:"name"`
+	Reached int     `json:"reached"`
+	Rate    float64 `json:"rate"`
+}
+
+// StageDuration summarizes the hours assigned clients spent in a stage.
+type StageDuration struct {
+	Stage       string  `json:"stage"`
+	Stays       int     `json:"stays"`
+	MeanHours   float64 `json:"mean_hours"`
+	MedianHours float64 `json:"median_hours"`
+}
+
+// Comparison is the result of a two-proportion z-test of a variant's conversion rate against
+// the control's.
+type Comparison struct {
+	// Difference is the variant rate minus the control rate, Lift the difference relative to the
+	// control rate (nil when the control did not convert).
+	Difference float64  `json:"difference"`
+	Lift       *float64 `json:"lift,omitempty"`
+
+	ZScore      float64 `json:"z_score"`
+	PValue      float64 `json:"p_value"`
+	Significant bool    `json:"significant"`
+}
+
+// Analyze builds the results of an experiment from the assignments and the outcome of every
+// assigned client. Clients without an outcome count as not converted.
+func Analyze(e Entity, stages []Stage, assignments []Assignment, outcomes map[string]Outcome, alpha float64, now time.Time) Results {
+	res := Results{
+		ExperimentID: e.ID,
+		Name:         e.Name,
+		GoalStage:    e.GoalStage,
+		Alpha:        alpha,
+		CalculatedAt: now,
+	}
+	if len(e.Variants) == 0 {
+		return res
+	}
+	res.Control = e.Variants[0].Name
+
+	goal := 0
+	for _, st := range stages {
+		if st.ID == e.GoalStage {
+			goal = st.Order
+		}
+	}
+
+	byVariant := make(map[string][]Assignment, len(e.Variants))
+	for _, a := range assignments {
+		byVariant[a.Variant] = append(byVariant[a.Variant], a)
+	}
+
+	res.Variants = make([]VariantResult, 0, len(e.Variants))
+	for _, v := range e.Variants {
+		members := byVariant[v.Name]
+		vr := VariantResult{
+			Variant:   v.Name,
+			Clients:   len(members),
+			Funnel:    make([]FunnelStep, len(stages)),
+			Durations: make([]StageDuration, 0, len(stages)),
+		}
+
+		stays := make(map[string][]float64, len(stages))
+		for i, st := range stages {
+			vr.Funnel[i] = FunnelStep{Stage: st.ID, Name: st.Name}
+		}
+		for _, a := range members {
+			o := outcomes[a.ClientID]
+			for i, st := range stages {
+				if o.Reached >= st.Order {
+					vr.Funnel[i].Reached++
+				}
+			}
+			if goal > 0 && o.Reached >= goal {
+				vr.Converted++
+			}
+			for stageID, hours := range o.Stays {
+				stays[stageID] = append(stays[stageID], hours...)
+			}
+		}
+
+		for i := range vr.Funnel {
+			vr.Funnel[i].Rate = rate(vr.Funnel[i].Reached, vr.Clients)
+		}
+		vr.ConversionRate = rate(vr.Converted, vr.Clients)
+
+		for _, st := range stages {
+			hours := stays[st.ID]
+			if len(hours) == 0 {
+				continue
+			}
+			var sum float64
+			for _, h := range hours {
+				sum += h
+			}
+			vr.Durations = append(vr.Durations, StageDuration{
+				Stage:       st.ID,
+				Stays:       len(hours),
+				MeanHours:   sum / float64(len(hours)),
+				MedianHours: metric.Quantiles(hours, 0.5)[0],
+			})
+		}
+
+		res.Variants = append(res.Variants, vr)
+	}
+
+	control := res.Variants[0]
+	for i := 1; i < len(res.Variants); i++ {
+		vr := &res.Variants[i]
+		z, p := ZTest(control.Converted, control.Clients, vr.Converted, vr.Clients)
+
+		cmp := &Comparison{
+			Difference:  vr.ConversionRate - control.ConversionRate,
+			ZScore:      z,
+			PValue:      p,
+			Significant: p < alpha,
+		}
+		if control.ConversionRate > 0 {
+			lift := cmp.Difference / control.ConversionRate
+			cmp.Lift = &lift
+		}
+		vr.Comparison = cmp
+	}
+
+	return res
+}
+
+// ZTest runs a two-sided two-proportion z-test with a pooled standard error of x2/n2 against
+// x1/n1. Without enough data to tell the proportions apart it returns a z-score of 0 and a
+// p-value of 1.
+func ZTest(x1, n1, x2, n2 int) (z, p float64) {
+	if n1 == 0 || n2 == 0 {
+		return 0, 1
+	}
+
+	p1 := float64(x1) / float64(n1)
+	p2 := float64(x2) / float64(n2)
+	pooled := float64(x1+x2) / float64(n1+n2)
+
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 0, 1
+	}
+
+	z = (p2 - p1) / se
+	p = math.Erfc(math.Abs(z) / math.Sqrt2)
+	return z, p
+}
+
+// rate returns part/total, 0 for an empty total
+func rate(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
@@ -0,0 +1,46 @@
+package experiment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestZTest(t *testing.T) {
+	tests := []struct {
+		name           string
+		x1, n1, x2, n2 int
+		z, p           float64
+	}{
+		{"variant converts better", 200, 1000, 250, 1000, 2.677398, 0.007420},
+		{"variant converts worse", 30, 100, 15, 120, -3.204164, 0.001355},
+		{"small samples", 10, 50, 12, 50, 0.482805, 0.629235},
+		{"same proportions", 20, 100, 40, 200, 0, 1},
+		{"empty control", 0, 0, 5, 10, 0, 1},
+		{"empty variant", 5, 10, 0, 0, 0, 1},
+		{"nobody converted", 0, 100, 0, 100, 0, 1},
+		{"everybody converted", 100, 100, 50, 50, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z, p := ZTest(tt.x1, tt.n1, tt.x2, tt.n2)
+			if math.Abs(z-tt.z) > 1e-6 {
+				t.Errorf("ZTest(%d, %d, %d, %d) z = %f, want %f", tt.x1, tt.n1, tt.x2, tt.n2, z, tt.z)
+			}
+			if math.Abs(p-tt.p) > 1e-6 {
+				t.Errorf("ZTest(%d, %d, %d, %d) p = %f, want %f", tt.x1, tt.n1, tt.x2, tt.n2, p, tt.p)
+			}
+		})
+	}
+}
+
+func TestZTestSymmetric(t *testing.T) {
+	z1, p1 := ZTest(200, 1000, 250, 1000)
+	z2, p2 := ZTest(250, 1000, 200, 1000)
+	if z1 != -z2 {
+		t.Errorf("swapped z = %f, want %f", z2, -z1)
+	}
+	if p1 != p2 {
+		t.Errorf("swapped p = %f, want %f", p2, p1)
+	}
+}
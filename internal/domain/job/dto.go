@@ -0,0 +1,104 @@
+package job
+
+import (
+	"TrackMe/pkg/store"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Request represents the request payload for starting a metric calculation job.
+type Request struct {
+	Interval string    `json:"interval"`
+	AsOf     time.Time `json:"as_of"`
+}
+
+// Bind validates the request payload.
+func (req *Request) Bind(r *http.Request) error {
+	return validateInterval(req.Interval)
+}
+
+// BackfillRequest represents the parameters of a job recalculating past periods.
+type BackfillRequest struct {
+	Interval string    `json:"interval"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+}
+
+// Bind validates the request payload.
+func (req *BackfillRequest) Bind(r *http.Request) error {
+	if err := validateInterval(req.Interval); err != nil {
+		return err
+	}
+	if req.To.Before(req.From) {
+		return fmt.Errorf("%w range: from must not be after to", store.ErrorInvalid)
+	}
+	return nil
+}
+
+func validateInterval(interval string) error {
+	switch interval {
+	case "day", "week", "month":
+	case "":
+		return errors.New("interval: cannot be blank")
+	default:
+		return errors.New("interval: must be one of day, week, month")
+	}
+	return nil
+}
+
+// Response represents the response payload for job operations.
+type Response struct {
+	ID         string     `json:"id"`
+	Interval   string     `json:"interval"`
+	AsOf       *time.Time `json:"as_of,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+	Status     string     `json:"status"`
+	Progress   float64    `json:"progress"`
+	Steps      []Step     `json:"steps"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ParseFromEntity converts a job entity to a response payload.
+func ParseFromEntity(data Entity) Response {
+	var done int
+	for _, step := range data.Steps {
+		if step.Status == StatusSucceeded || step.Status == StatusFailed {
+			done++
+		}
+	}
+
+	var progress float64
+	if len(data.Steps) > 0 {
+		progress = float64(done) / float64(len(data.Steps))
+	}
+
+	return Response{
+		ID:         data.ID,
+		Interval:   data.Interval,
+		AsOf:       data.AsOf,
+		From:       data.From,
+		To:         data.To,
+		Status:     data.Status,
+		Progress:   progress,
+		Steps:      data.Steps,
+		Error:      data.Error,
+		CreatedAt:  data.CreatedAt,
+		StartedAt:  data.StartedAt,
+		FinishedAt: data.FinishedAt,
+	}
+}
+
+// ParseFromEntities converts a list of job entities to a list of response payloads.
+func ParseFromEntities(data []Entity) []Response {
+	res := make([]Response, len(data))
+	for i, entity := range data {
+		res[i] = ParseFromEntity(entity)
+	}
+	return res
+}
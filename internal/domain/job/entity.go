@@ -0,0 +1,91 @@
+package job
+
+import "time"
+
+// Status constants
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Entity represents an asynchronous metric calculation or backfill job.
+type Entity struct {
+	// ID is the unique identifier for the job (UUID).
+	ID string `db:"id" bson:"_id"`
+
+	// Interval is the metric interval the job calculates (day, week, month).
+	Interval string `db:"metric_interval" bson:"interval"`
+
+	// AsOf is the timestamp metrics are calculated as of; nil means the time the job started.
+	AsOf *time.Time `db:"as_of" bson:"as_of"`
+
+	// From is the first day of a backfill; nil for a calculation job.
+	From *time.Time `db:"backfill_from" bson:"from"`
+
+	// To is the last day of a backfill, inclusive; nil for a calculation job.
+	To *time.Time `db:"backfill_to" bson:"to"`
+
+	// Status is the job state (pending, running, succeeded, failed).
+	Status string `db:"status" bson:"status"`
+
+	// Steps is the per-calculation progress of the job.
+	Steps []Step `db:"steps" bson:"steps"`
+
+	// Error is the error that failed the job.
+	Error string `db:"error" bson:"error"`
+
+	// CreatedAt is the timestamp when the job was requested.
+	CreatedAt time.Time `db:"created_at" bson:"created_at"`
+
+	// StartedAt is the timestamp when the job started running.
+	StartedAt *time.Time `db:"started_at" bson:"started_at"`
+
+	// FinishedAt is the timestamp when the job succeeded or failed.
+	FinishedAt *time.Time `db:"finished_at" bson:"finished_at"`
+
+	// ExpiresAt is the timestamp after which an unfinished job is considered abandoned.
+	ExpiresAt time.Time `db:"expires_at" bson:"expires_at"`
+}
+
+// Step represents the progress of a single calculation, or of a period of a backfill, within a job.
+type Step struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// New creates a new pending Job instance.
+func New(req Request) Entity {
+	entity := Entity{
+		Interval:  req.Interval,
+		Status:    StatusPending,
+		Steps:     []Step{},
+		CreatedAt: time.Now(),
+	}
+	if !req.AsOf.IsZero() {
+		entity.AsOf = &req.AsOf
+	}
+	return entity
+}
+
+// NewBackfill creates a new pending backfill job.
+func NewBackfill(req BackfillRequest) Entity {
+	entity := New(Request{Interval: req.Interval})
+	entity.From = &req.From
+	entity.To = &req.To
+	return entity
+}
+
+// IsBackfill reports whether the job recalculates past periods.
+func (e Entity) IsBackfill() bool {
+	return e.From != nil
+}
+
+// IsActive reports whether the job is still pending or running.
+func (e Entity) IsActive() bool {
+	return e.Status == StatusPending || e.Status == StatusRunning
+}
@@ -0,0 +1,25 @@
+package job
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for job repository operations.
+type Repository interface {
+	// List retrieves job entities, newest first.
+	List(ctx context.Context, limit, offset int) ([]Entity, int, error)
+
+	// Create creates a new job entity. It fails with store.ErrorAlreadyExists
+	// while another job for the same interval is pending or running.
+	Create(ctx context.Context, data Entity) (Entity, error)
+
+	// Get retrieves a job entity by its ID.
+	Get(ctx context.Context, id string) (Entity, error)
+
+	// Update modifies the state of an existing job entity by its ID.
+	Update(ctx context.Context, id string, data Entity) (Entity, error)
+
+	// ExpireActive fails pending or running jobs of the interval that expired before the given time.
+	ExpireActive(ctx context.Context, interval string, now time.Time) error
+}
@@ -0,0 +1,159 @@
+package metric
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Calculator computes metrics for a calculation period.
+type Calculator interface {
+	// Name identifies the calculator in runs, jobs and failure counters.
+	Name() string
+
+	// Types lists the metric types the calculator produces.
+	Types() []Type
+
+	// Intervals lists the intervals the calculator runs for. A calculator without intervals
+	// measures a point-in-time snapshot: it runs for every interval and its metrics are
+	// stored without an interval.
+	Intervals() []string
+
+	// Calculate returns the metrics of the period as of period.AsOf. Entities without an ID,
+	// interval or creation time are completed by the caller before they are stored.
+	Calculate(ctx context.Context, period Period) ([]Entity, error)
+}
+
+// IsSnapshot reports whether the calculator measures a point-in-time snapshot.
+func IsSnapshot(c Calculator) bool {
+	return len(c.Intervals()) == 0
+}
+
+// Supports reports whether the calculator runs for the interval.
+func Supports(c Calculator, interval string) bool {
+	if IsSnapshot(c) {
+		return true
+	}
+	for _, i := range c.Intervals() {
+		if i == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCalculator returns a Calculator backed by a calculation function.
+func NewCalculator(name string, types []Type, intervals []string, calculate func(ctx context.Context, period Period) ([]Entity, error)) Calculator {
+	return &funcCalculator{
+		name:      name,
+		types:     types,
+		intervals: intervals,
+		calculate: calculate,
+	}
+}
+
+type funcCalculator struct {
+	name      string
+	types     []Type
+	intervals []string
+	calculate func(ctx context.Context, period Period) ([]Entity, error)
+}
+
+func (c *funcCalculator) Name() string        { return c.name }
+func (c *funcCalculator) Types() []Type       { return c.types }
+func (c *funcCalculator) Intervals() []string { return c.intervals }
+
+func (c *funcCalculator) Calculate(ctx context.Context, period Period) ([]Entity, error) {
+	return c.calculate(ctx, period)
+}
+
+// Registry holds the calculators a metrics run executes, in registration order.
+type Registry struct {
+	mu          sync.RWMutex
+	calculators []Calculator
+}
+
+// NewRegistry creates an empty calculator registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a calculator to the registry. Names must be unique.
+func (r *Registry) Register(c Calculator) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c.Name() == "" {
+		return fmt.Errorf("calculator name cannot be blank")
+	}
+	for _, existing := range r.calculators {
+		if existing.Name() == c.Name() {
+			return fmt.Errorf("calculator %s is already registered", c.Name())
+		}
+		for _, t := range existing.Types() {
+			for _, added := range c.Types() {
+				if t == added {
+					return fmt.Errorf("calculator %s: metric type %s is already produced by %s", c.Name(), t, existing.Name())
+				}
+			}
+		}
+	}
+
+	r.calculators = append(r.calculators, c)
+	return nil
+}
+
+// Calculators returns every registered calculator.
+func (r *Registry) Calculators() []Calculator {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Calculator(nil), r.calculators...)
+}
+
+// For returns the calculators that run for the interval.
+func (r *Registry) For(interval string) []Calculator {
+	var res []Calculator
+	for _, c := range r.Calculators() {
+		if Supports(c, interval) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Intervals returns the intervals at least one calculator runs for.
+func (r *Registry) Intervals() []string {
+	var res []string
+	for _, interval := range []string{"day", "week", "month"} {
+		if len(r.For(interval)) > 0 {
+			res = append(res, interval)
+		}
+	}
+	return res
+}
+
+// Types returns every metric type produced by a registered calculator.
+func (r *Registry) Types() []Type {
+	seen := make(map[Type]bool)
+	var res []Type
+	for _, c := range r.Calculators() {
+		for _, t := range c.Types() {
+			if !seen[t] {
+				seen[t] = true
+				res = append(res, t)
+			}
+		}
+	}
+	return res
+}
+
+// HasType reports whether a registered calculator produces the metric type.
+func (r *Registry) HasType(t string) bool {
+	for _, registered := range r.Types() {
+		if string(registered) == t {
+			return true
+		}
+	}
+	return false
+}
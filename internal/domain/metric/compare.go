@@ -0,0 +1,134 @@
+package metric
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Trend directions
+const (
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendFlat = "flat"
+)
+
+// PeriodValue is the latest value of a metric within a period, nil when nothing was stored.
+type PeriodValue struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Value *float64  `json:"value"`
+}
+
+// Comparison compares the current period of a metric series with the previous ones.
+type Comparison struct {
+	Type     string            `json:"type"`
+	Interval string            `json:"interval"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Current is the value of the current period.
+	Current PeriodValue `json:"current"`
+
+	// Previous are the values of the preceding periods, most recent first.
+	Previous []PeriodValue `json:"previous"`
+
+	// Delta is the absolute change from the directly preceding period.
+	Delta *float64 `json:"delta"`
+
+	// RelativeDelta is Delta as a fraction of the preceding value; nil when that value is zero.
+	RelativeDelta *float64 `json:"relative_delta"`
+
+	// Trend is up, down or flat; empty when either value is missing.
+	Trend string `json:"trend,omitempty"`
+}
+
+// Compare builds one comparison per metadata series of the entities over the given periods,
+// ordered from the current period back. Within a period the most recent value is used.
+func Compare(metricType, interval string, periods []Period, entities []Entity) []Comparison {
+	type series struct {
+		metadata map[string]string
+		values   []PeriodValue
+		stamps   []time.Time
+	}
+
+	index := make(map[string]*series)
+	var keys []string
+
+	for _, e := range entities {
+		if e.CreatedAt == nil || e.Value == nil {
+			continue
+		}
+
+		key := seriesKey(e.Metadata)
+		s, ok := index[key]
+		if !ok {
+			s = &series{
+				metadata: e.Metadata,
+				values:   make([]PeriodValue, len(periods)),
+				stamps:   make([]time.Time, len(periods)),
+			}
+			for i, p := range periods {
+				s.values[i] = PeriodValue{Start: p.Start, End: p.End}
+			}
+			index[key] = s
+			keys = append(keys, key)
+		}
+
+		for i, p := range periods {
+			if !p.Contains(*e.CreatedAt) || e.CreatedAt.Before(s.stamps[i]) {
+				continue
+			}
+			value := *e.Value
+			s.values[i].Value = &value
+			s.stamps[i] = *e.CreatedAt
+		}
+	}
+
+	sort.Strings(keys)
+
+	res := make([]Comparison, 0, len(keys))
+	for _, key := range keys {
+		s := index[key]
+		c := Comparison{
+			Type:     metricType,
+			Interval: interval,
+			Metadata: s.metadata,
+			Current:  s.values[0],
+			Previous: s.values[1:],
+		}
+
+		if len(c.Previous) > 0 && c.Current.Value != nil && c.Previous[0].Value != nil {
+			current, previous := *c.Current.Value, *c.Previous[0].Value
+			delta := current - previous
+			c.Delta = &delta
+			if previous != 0 {
+				relative := delta / math.Abs(previous)
+				c.RelativeDelta = &relative
+			}
+
+			switch {
+			case math.Abs(delta) < 1e-9:
+				c.Trend = TrendFlat
+			case delta > 0:
+				c.Trend = TrendUp
+			default:
+				c.Trend = TrendDown
+			}
+		}
+
+		res = append(res, c)
+	}
+
+	return res
+}
+
+// seriesKey identifies a metadata breakdown by its sorted key=value pairs
+func seriesKey(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for k, v := range metadata {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
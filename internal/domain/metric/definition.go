@@ -0,0 +1,94 @@
+package metric
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Definition kinds
+const (
+	KindCount = "count"
+	KindRatio = "ratio"
+	KindAvg   = "avg"
+)
+
+var definitionID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Condition matches a client field against one of several values.
+type Condition map[string]Values
+
+// Values is a list of accepted values that unmarshals from a scalar or a sequence.
+type Values []string
+
+// UnmarshalYAML accepts both `field: value` and `field: [a, b]`.
+func (v *Values) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*v = Values{single}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*v = list
+	return nil
+}
+
+// Definition describes a metric computed over client fields without code.
+type Definition struct {
+	// ID is stored as the metric type and must not clash with another metric type.
+	ID string `yaml:"id"`
+
+	// Name is a human readable description of the metric.
+	Name string `yaml:"name"`
+
+	// Kind is count, ratio or avg.
+	Kind string `yaml:"kind"`
+
+	// Intervals lists the intervals the metric is calculated for, all when empty.
+	Intervals []string `yaml:"intervals"`
+
+	// Filters selects the clients the metric is computed over.
+	Filters Condition `yaml:"filters"`
+
+	// Numerator selects the share of the filtered clients a ratio measures.
+	Numerator Condition `yaml:"numerator"`
+
+	// Field is the numeric client field an avg is computed over.
+	Field string `yaml:"field"`
+
+	// PeriodField limits the clients to those whose date field falls within the period
+	// (registration_date, last_updated or last_login). Every client is counted when empty.
+	PeriodField string `yaml:"period_field"`
+}
+
+// Validate checks the definition is complete for its kind.
+func (d Definition) Validate() error {
+	if !definitionID.MatchString(d.ID) {
+		return fmt.Errorf("id: %q must be lowercase letters, digits, '-' or '_'", d.ID)
+	}
+
+	switch d.Kind {
+	case KindCount:
+	case KindRatio:
+		if len(d.Numerator) == 0 {
+			return fmt.Errorf("%s: numerator: cannot be blank for a ratio", d.ID)
+		}
+	case KindAvg:
+		if d.Field == "" {
+			return fmt.Errorf("%s: field: cannot be blank for an avg", d.ID)
+		}
+	default:
+		return fmt.Errorf("%s: kind: must be one of count, ratio, avg", d.ID)
+	}
+
+	for _, interval := range d.Intervals {
+		if interval != "day" && interval != "week" && interval != "month" {
+			return fmt.Errorf("%s: intervals: must be day, week or month", d.ID)
+		}
+	}
+
+	return nil
+}
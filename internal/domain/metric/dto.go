@@ -6,9 +6,32 @@ import (
 	"time"
 )
 
+// Filters selects the metrics returned by List.
 type Filters struct {
 	Type     string
 	Interval string
+
+	// From and To bound the creation time of the metrics, inclusive; zero values leave the range open.
+	From time.Time
+	To   time.Time
+
+	// Metadata matches metrics whose metadata has every given key and value (e.g. stage, source).
+	Metadata map[string]string
+
+	// MetadataDefaults matches metrics whose metadata has the given key and value or lacks the key,
+	// e.g. rows stored before the key existed; those are returned with the default value set.
+	MetadataDefaults map[string]string
+
+	// Latest returns only the most recent metric of every series (type, interval and metadata)
+	// instead of the full time series.
+	Latest bool
+}
+
+// MetadataKeys are the metadata keys calculators store metrics with, the only ones metrics can be
+// filtered by.
+var MetadataKeys = []string{
+	"stage", "percentile", "mode", "sla_hours", "reason", "source", "channel", "currency", "model", "kind", "name",
+	"timezone",
 }
 
 // Request represents the request payload for metric operations.
@@ -41,6 +64,9 @@ type Response struct {
 	Interval  string            `json:"interval,omitempty"`
 	CreatedAt time.Time         `json:"created_at"`
 	Metadata  map[string]string `json:"metadata,omitempty"`
+
+	// Goal is the standing of the value against the metric's goal, when one is set.
+	Goal *GoalStatus `json:"goal,omitempty"`
 }
 
 // ParseFromEntity converts a metric entity to a response payload.
@@ -18,6 +18,23 @@ const (
 	ChannelConversion Type = "channel-conversion"
 	AppInstallRate    Type = "app-install-rate"
 	AutoPaymentRate   Type = "autopayment-rate"
+	MRR               Type = "mrr"
+	NewMRR            Type = "new-mrr"
+	ChurnedMRR        Type = "churned-mrr"
+	ExpansionMRR      Type = "expansion-mrr"
+	ContractionMRR    Type = "contraction-mrr"
+	ARPU              Type = "arpu"
+	LTV               Type = "ltv"
+
+	StageBusinessDuration Type = "stage-business-duration"
+	TotalBusinessDuration Type = "total-business-duration"
+	SLABreaches           Type = "sla-breaches"
+
+	ChurnRate        Type = "churn-rate"
+	ReactivationRate Type = "reactivation-rate"
+
+	RollbackReasons Type = "rollback-reasons"
+	DropoutReasons  Type = "dropout-reasons"
 )
 
 // Entity represents a metric in the system.
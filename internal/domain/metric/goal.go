@@ -0,0 +1,321 @@
+package metric
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Goal operators
+const (
+	GoalAtLeast = "gte"
+	GoalAtMost  = "lte"
+)
+
+// Goal is a target value of a metric per day, week or month, e.g. monthly conversion >= 0.12.
+type Goal struct {
+	ID string `db:"id" json:"id"`
+
+	// Type is the metric type the goal applies to.
+	Type string `db:"metric_type" json:"type"`
+
+	// Interval is the period the target applies to: day, week or month.
+	Interval string `db:"metric_interval" json:"interval"`
+
+	// Operator is gte (the value must reach the target) or lte (the value must stay below it).
+	Operator string `db:"operator" json:"operator"`
+
+	Target float64 `db:"target" json:"target"`
+
+	// Metadata restricts the goal to the series with these metadata values, e.g. a source.
+	Metadata map[string]string `db:"metadata" json:"metadata,omitempty"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// GoalRequest represents the request payload for creating a goal.
+type GoalRequest struct {
+	Type     string            `json:"type"`
+	Interval string            `json:"interval"`
+	Operator string            `json:"operator"`
+	Target   *float64          `json:"target"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Bind validates the request payload, accepting >= and <= as operators.
+func (req *GoalRequest) Bind(r *http.Request) error {
+	if req.Type == "" {
+		return errors.New("type: cannot be blank")
+	}
+	switch req.Interval {
+	case "day", "week", "month":
+	case "":
+		return errors.New("interval: cannot be blank")
+	default:
+		return errors.New("interval: must be one of day, week, month")
+	}
+	switch req.Operator {
+	case GoalAtLeast, ">=", "":
+		req.Operator = GoalAtLeast
+	case GoalAtMost, "<=":
+		req.Operator = GoalAtMost
+	default:
+		return errors.New("operator: must be gte (>=) or lte (<=)")
+	}
+	if req.Target == nil {
+		return errors.New("target: cannot be blank")
+	}
+	return nil
+}
+
+// NewGoal creates a new Goal from a request.
+func NewGoal(req GoalRequest) Goal {
+	return Goal{
+		Type:      req.Type,
+		Interval:  req.Interval,
+		Operator:  req.Operator,
+		Target:    *req.Target,
+		Metadata:  req.Metadata,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Applies reports whether the goal covers a metric: same type, the goal's interval or a snapshot
+// metric, and every goal metadata value present in the metric's metadata.
+func (g Goal) Applies(m Response) bool {
+	if m.Type != g.Type || (m.Interval != g.Interval && m.Interval != "") {
+		return false
+	}
+	for k, v := range g.Metadata {
+		if m.Metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Met reports whether value satisfies the goal.
+func (g Goal) Met(value float64) bool {
+	if g.Operator == GoalAtMost {
+		return value <= g.Target
+	}
+	return value >= g.Target
+}
+
+// Attainment returns how far value is towards the target in percent; 100 or more means the goal
+// is met.
+func (g Goal) Attainment(value float64) float64 {
+	if g.Operator == GoalAtMost {
+		if value <= 0 {
+			return 100
+		}
+		return g.Target / value * 100
+	}
+	if g.Target == 0 {
+		if value >= 0 {
+			return 100
+		}
+		return 0
+	}
+	return value / g.Target * 100
+}
+
+// GoalStatus is the standing of a metric value against a goal. Projection and OnTrack are only
+// set for the latest value of a series in the current period; Missed is set for values of closed
+// periods that do not meet the target.
+type GoalStatus struct {
+	GoalID     string   `json:"goal_id"`
+	Operator   string   `json:"operator"`
+	Target     float64  `json:"target"`
+	Period     Period   `json:"period"`
+	Value      float64  `json:"value"`
+	Attainment float64  `json:"attainment"`
+	Met        bool     `json:"met"`
+	Missed     bool     `json:"missed"`
+	Projection *float64 `json:"projection,omitempty"`
+	OnTrack    *bool    `json:"on_track,omitempty"`
+}
+
+// Status returns the standing of a value of period against the goal as of now.
+func (g Goal) Status(period Period, value float64, now time.Time) GoalStatus {
+	st := GoalStatus{
+		GoalID:     g.ID,
+		Operator:   g.Operator,
+		Target:     g.Target,
+		Period:     period,
+		Value:      value,
+		Attainment: g.Attainment(value),
+		Met:        g.Met(value),
+	}
+	st.Missed = !st.Met && !now.Before(period.End)
+	return st
+}
+
+// project sets the projection of the value to the end of its period and whether it meets the goal
+func (g Goal) project(st *GoalStatus, projection float64) {
+	onTrack := g.Met(projection)
+	st.Projection = &projection
+	st.OnTrack = &onTrack
+}
+
+// GoalProgress is a goal with the standing of its series in the current and recent periods.
+type GoalProgress struct {
+	Goal
+	Series []GoalSeries `json:"series"`
+}
+
+// GoalSeries is the standing of one metadata breakdown of a goal's metric.
+type GoalSeries struct {
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Current  *GoalStatus       `json:"current,omitempty"`
+	Misses   []GoalStatus      `json:"misses"`
+}
+
+// latestInPeriods keeps per series and period the last entity created within it
+func latestInPeriods(periods []Period, entities []Entity) map[string]map[int]Entity {
+	res := make(map[string]map[int]Entity)
+	for _, e := range entities {
+		if e.Value == nil || e.CreatedAt == nil {
+			continue
+		}
+		for i, p := range periods {
+			if !p.Contains(*e.CreatedAt) {
+				continue
+			}
+			key := seriesKey(e.Metadata)
+			if res[key] == nil {
+				res[key] = make(map[int]Entity)
+			}
+			if prev, ok := res[key][i]; !ok || !e.CreatedAt.Before(*prev.CreatedAt) {
+				res[key][i] = e
+			}
+			break
+		}
+	}
+	return res
+}
+
+// projections extrapolates every series of entities created within current to its end
+func projections(current Period, entities []Entity) map[string]float64 {
+	times := make(map[string][]time.Time)
+	values := make(map[string][]float64)
+	for _, e := range entities {
+		if e.Value == nil || e.CreatedAt == nil || !current.Contains(*e.CreatedAt) {
+			continue
+		}
+		key := seriesKey(e.Metadata)
+		times[key] = append(times[key], *e.CreatedAt)
+		values[key] = append(values[key], *e.Value)
+	}
+
+	res := make(map[string]float64, len(values))
+	for key := range values {
+		res[key] = Project(times[key], values[key], current.Last())
+	}
+	return res
+}
+
+// Progress evaluates the goal for periods, newest first, the first being the current one, from the
+// entities of the goal's metric created within them, ordered by time.
+func (g Goal) Progress(periods []Period, entities []Entity, now time.Time) GoalProgress {
+	res := GoalProgress{Goal: g, Series: []GoalSeries{}}
+	if len(periods) == 0 {
+		return res
+	}
+
+	latest := latestInPeriods(periods, entities)
+	projected := projections(periods[0], entities)
+
+	keys := make([]string, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		series := GoalSeries{Misses: []GoalStatus{}}
+		for i, p := range periods {
+			e, ok := latest[key][i]
+			if !ok {
+				continue
+			}
+			series.Metadata = e.Metadata
+
+			st := g.Status(p, *e.Value, now)
+			if i == 0 {
+				g.project(&st, projected[key])
+				series.Current = &st
+			} else if st.Missed {
+				series.Misses = append(series.Misses, st)
+			}
+		}
+		res.Series = append(res.Series, series)
+	}
+
+	return res
+}
+
+// Annotate sets the goal status of every response the goal applies to that has none yet. recent
+// holds the entities of the goal's metric created within the current period, used to project the
+// latest value of every series to the end of the period.
+func (g Goal) Annotate(responses []Response, current Period, recent []Entity, now time.Time) {
+	projected := projections(current, recent)
+
+	latest := make(map[string]time.Time)
+	for _, e := range recent {
+		if e.CreatedAt != nil && current.Contains(*e.CreatedAt) {
+			key := seriesKey(e.Metadata)
+			if e.CreatedAt.After(latest[key]) {
+				latest[key] = *e.CreatedAt
+			}
+		}
+	}
+
+	for i := range responses {
+		r := &responses[i]
+		if r.Goal != nil || !g.Applies(*r) {
+			continue
+		}
+
+		period, err := PeriodOf(g.Interval, r.CreatedAt)
+		if err != nil {
+			continue
+		}
+
+		st := g.Status(period, r.Value, now)
+		key := seriesKey(r.Metadata)
+		if t, ok := latest[key]; ok && current.Contains(r.CreatedAt) && r.CreatedAt.Equal(t) {
+			g.project(&st, projected[key])
+		}
+		r.Goal = &st
+	}
+}
+
+// Project extrapolates the values of a series, ordered by time, to the time at with a least
+// squares line. A single value is projected as is.
+func Project(times []time.Time, values []float64, at time.Time) float64 {
+	n := float64(len(values))
+	if len(values) == 1 {
+		return values[0]
+	}
+
+	origin := times[0]
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range values {
+		x := times[i].Sub(origin).Hours()
+		sumX += x
+		sumY += v
+		sumXY += x * v
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return sumY / n
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+
+	return intercept + slope*at.Sub(origin).Hours()
+}
@@ -0,0 +1,100 @@
+package metric
+
+import (
+	"TrackMe/pkg/store"
+	"fmt"
+	"time"
+)
+
+// Period represents a calculation window [Start, End) of a given interval
+// and the timestamp within it the metrics are calculated as of.
+type Period struct {
+	Interval string    `json:"interval"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	AsOf     time.Time `json:"as_of"`
+}
+
+// PeriodOf returns the day, ISO week or month period that contains t, calculated as of t.
+func PeriodOf(interval string, t time.Time) (Period, error) {
+	var start time.Time
+
+	switch interval {
+	case "day":
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case "week":
+		year, week := t.ISOWeek()
+		start = firstDayOfISOWeek(year, week, t.Location())
+	case "month":
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return Period{}, fmt.Errorf("%w interval: %s (valid values: day, week, month)", store.ErrorInvalid, interval)
+	}
+
+	return Period{
+		Interval: interval,
+		Start:    start,
+		End:      advance(interval, start),
+		AsOf:     t,
+	}, nil
+}
+
+// Next returns the period that directly follows p, calculated as of its last second.
+func (p Period) Next() Period {
+	next := Period{
+		Interval: p.Interval,
+		Start:    p.End,
+		End:      advance(p.Interval, p.End),
+	}
+	next.AsOf = next.Last()
+	return next
+}
+
+// Last returns the last second of the period, the timestamp a closed period is calculated at.
+func (p Period) Last() time.Time {
+	return p.End.Add(-time.Second)
+}
+
+// Contains reports whether t falls within the period.
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+func advance(interval string, t time.Time) time.Time {
+	switch interval {
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// Helper function to get the first day of an ISO week
+func firstDayOfISOWeek(year, week int, loc *time.Location) time.Time {
+	// Get January 1 for the year
+	jan1 := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+
+	// Get the day of the week for January 1
+	dayOfWeek := int(jan1.Weekday())
+	if dayOfWeek == 0 {
+		// Sunday is 0, but ISO considers it 7
+		dayOfWeek = 7
+	}
+
+	// Days to add to get to the Monday of week 1
+	daysToAdd := 1 - dayOfWeek
+
+	// Monday of the first week
+	firstMonday := jan1.AddDate(0, 0, daysToAdd)
+
+	// If January 1 is after Thursday, it's part of week 1
+	// If not, it's part of the last week of the previous year
+	if dayOfWeek > 4 {
+		firstMonday = firstMonday.AddDate(0, 0, 7)
+	}
+
+	// Add the required number of weeks
+	return firstMonday.AddDate(0, 0, 7*(week-1))
+}
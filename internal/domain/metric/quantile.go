@@ -0,0 +1,26 @@
+package metric
+
+import (
+	"math"
+	"sort"
+)
+
+// Quantiles returns the values at the given levels (0..1) with linear interpolation between
+// the closest ranks. It returns nil for an empty input.
+func Quantiles(values []float64, levels ...float64) []float64 {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	res := make([]float64, len(levels))
+	for i, level := range levels {
+		pos := level * float64(len(sorted)-1)
+		lower := int(math.Floor(pos))
+		upper := int(math.Ceil(pos))
+		res[i] = sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+	}
+	return res
+}
@@ -0,0 +1,48 @@
+package metric
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestQuantiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		levels []float64
+		want   []float64
+	}{
+		{"empty", nil, []float64{0.5}, nil},
+		{"single value", []float64{5}, []float64{0, 0.5, 1}, []float64{5, 5, 5}},
+		{"bounds", []float64{3, 1, 2}, []float64{0, 1}, []float64{1, 3}},
+		{"exact rank", []float64{1, 2, 3}, []float64{0.5}, []float64{2}},
+		{"interpolated", []float64{4, 1, 3, 2}, []float64{0.25, 0.5, 0.9}, []float64{1.75, 2.5, 3.7}},
+		{"percentiles", []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110}, []float64{0.5, 0.75, 0.9, 0.95},
+			[]float64{60, 85, 100, 105}},
+		{"no levels", []float64{1, 2}, nil, []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Quantiles(tt.values, tt.levels...)
+			if (got == nil) != (tt.want == nil) || len(got) != len(tt.want) {
+				t.Fatalf("Quantiles(%v, %v) = %v, want %v", tt.values, tt.levels, got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("Quantiles(%v, %v) = %v, want %v", tt.values, tt.levels, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestQuantilesKeepsInput(t *testing.T) {
+	values := []float64{3, 1, 2}
+	Quantiles(values, 0.5)
+	if !slices.Equal(values, []float64{3, 1, 2}) {
+		t.Errorf("Quantiles reordered its input to %v", values)
+	}
+}
@@ -2,6 +2,7 @@ package metric
 
 import (
 	"context"
+	"time"
 )
 
 // Repository defines the interface for client repository operations.
@@ -14,4 +15,34 @@ type Repository interface {
 
 	// Update modifies an existing client entity by its ID.
 	Update(ctx context.Context, id string, data Entity) (Entity, error)
+
+	// DeleteRange removes entities of the given types and interval created within [from, to).
+	DeleteRange(ctx context.Context, types []string, interval string, from, to time.Time) error
+}
+
+// RunRepository defines the interface for metric run repository operations.
+type RunRepository interface {
+	// List retrieves runs, newest first, optionally limited to an interval.
+	List(ctx context.Context, interval string, limit, offset int) ([]Run, int, error)
+
+	// Add inserts a finished run and returns its ID.
+	Add(ctx context.Context, data Run) (string, error)
+}
+
+// DefinitionRepository defines the interface for declarative metric definition sources.
+type DefinitionRepository interface {
+	// List retrieves every metric definition.
+	List(ctx context.Context) ([]Definition, error)
+}
+
+// GoalRepository defines the interface for metric goal operations.
+type GoalRepository interface {
+	// Create inserts a goal and returns it with its ID.
+	Create(ctx context.Context, data Goal) (Goal, error)
+
+	// List retrieves every goal, oldest first.
+	List(ctx context.Context) ([]Goal, error)
+
+	// Delete removes a goal by its ID.
+	Delete(ctx context.Context, id string) error
 }
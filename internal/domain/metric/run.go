@@ -0,0 +1,67 @@
+package metric
+
+import "time"
+
+// Run status constants
+const (
+	RunSucceeded = "succeeded"
+	RunPartial   = "partial"
+	RunFailed    = "failed"
+)
+
+// Run represents the outcome of a single calculation of every metric for an interval.
+type Run struct {
+	// ID is the unique identifier for the run (UUID).
+	ID string `db:"id" json:"id"`
+
+	// JobID is the calculation job the run belongs to, nil for scheduled runs and backfills.
+	JobID *string `db:"job_id" json:"job_id,omitempty"`
+
+	// Interval is the metric interval of the run (day, week, month).
+	Interval string `db:"metric_interval" json:"interval"`
+
+	// AsOf is the timestamp metrics were calculated as of.
+	AsOf time.Time `db:"as_of" json:"as_of"`
+
+	// Status is succeeded when every calculation succeeded, failed when all failed and partial otherwise.
+	Status string `db:"status" json:"status"`
+
+	// Results is the per-calculation summary of the run.
+	Results []RunResult `db:"results" json:"results"`
+
+	// StartedAt is the timestamp when the run started.
+	StartedAt time.Time `db:"started_at" json:"started_at"`
+
+	// FinishedAt is the timestamp when the run finished.
+	FinishedAt time.Time `db:"finished_at" json:"finished_at"`
+}
+
+// RunResult represents the outcome of a single calculation within a run.
+type RunResult struct {
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Summarize derives the run status from its results.
+func (r Run) Summarize() string {
+	var failed int
+	for _, res := range r.Results {
+		if res.Status == RunFailed {
+			failed++
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return RunSucceeded
+	case failed == len(r.Results):
+		return RunFailed
+	default:
+		return RunPartial
+	}
+}
@@ -0,0 +1,97 @@
+package reason
+
+import (
+	"TrackMe/pkg/store"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Kinds of reasons
+const (
+	// Rollback reasons explain a client moving back to a previous stage.
+	Rollback = "rollback"
+
+	// Deactivation reasons explain a client being deactivated.
+	Deactivation = "deactivation"
+)
+
+// Unspecified is the reason of rollbacks and dropouts recorded without a reason code in metrics.
+const Unspecified = "unspecified"
+
+// Reason is a reason code of the taxonomy.
+type Reason struct {
+	Code string `yaml:"code" json:"code"`
+	Name string `yaml:"name" json:"name"`
+
+	// Stages restricts the reason to rollbacks from, or deactivations in, these stages; empty
+	// allows every stage.
+	Stages []string `yaml:"stages" json:"stages,omitempty"`
+}
+
+// Taxonomy lists the reason codes per kind.
+type Taxonomy struct {
+	Rollback     []Reason `yaml:"rollback" json:"rollback"`
+	Deactivation []Reason `yaml:"deactivation" json:"deactivation"`
+}
+
+// Validate checks that every reason has a unique code within its kind.
+func (t Taxonomy) Validate() error {
+	for _, kind := range []string{Rollback, Deactivation} {
+		reasons := t.Reasons(kind)
+		seen := make(map[string]bool, len(reasons))
+		for i, r := range reasons {
+			if r.Code == "" {
+				return fmt.Errorf("%s[%d].code: cannot be blank", kind, i)
+			}
+			if r.Code == Unspecified {
+				return fmt.Errorf("%s[%d].code: %s is reserved", kind, i, Unspecified)
+			}
+			if seen[r.Code] {
+				return fmt.Errorf("%s[%d].code: duplicate code %s", kind, i, r.Code)
+			}
+			seen[r.Code] = true
+		}
+	}
+	return nil
+}
+
+// Reasons returns the reasons of a kind.
+func (t Taxonomy) Reasons(kind string) []Reason {
+	if kind == Deactivation {
+		return t.Deactivation
+	}
+	return t.Rollback
+}
+
+// Check validates the reason code of a kind given in a stage. An empty code is always valid, and so
+// is any code of a kind without reasons in the taxonomy.
+func (t Taxonomy) Check(kind, code, stage string) error {
+	if code == "" {
+		return nil
+	}
+
+	reasons := t.Reasons(kind)
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	codes := make([]string, 0, len(reasons))
+	for _, r := range reasons {
+		if len(r.Stages) > 0 && !slices.Contains(r.Stages, stage) {
+			continue
+		}
+		if r.Code == code {
+			return nil
+		}
+		codes = append(codes, r.Code)
+	}
+	return fmt.Errorf("%w reason_code: %s is not a %s reason in stage %s (valid values: %s)", store.ErrorInvalid, code, kind, stage, strings.Join(codes, ", "))
+}
+
+// Repository defines the interface for reason taxonomy sources.
+type Repository interface {
+	// Taxonomy returns the reason taxonomy.
+	Taxonomy(ctx context.Context) (Taxonomy, error)
+}
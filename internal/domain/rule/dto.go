@@ -0,0 +1,144 @@
+package rule
+
+import (
+	"TrackMe/pkg/expr"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Filters narrows a rule listing.
+type Filters struct {
+	Trigger string
+	Enabled *bool
+}
+
+// ExecutionFilters narrows an execution log listing.
+type ExecutionFilters struct {
+	RuleID   string
+	ClientID string
+}
+
+// Request represents the request payload for creating or replacing a rule.
+type Request struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Enabled     *bool    `json:"enabled"`
+	Trigger     string   `json:"trigger"`
+	Schedule    string   `json:"schedule"`
+	Condition   string   `json:"condition"`
+	Actions     []Action `json:"actions"`
+}
+
+// Bind validates the request payload. Rules are enabled unless enabled is false.
+func (req *Request) Bind(r *http.Request) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("name: cannot be blank")
+	}
+
+	if !slices.Contains(Triggers, req.Trigger) {
+		return fmt.Errorf("trigger: must be one of %s", strings.Join(Triggers, ", "))
+	}
+
+	req.Schedule = strings.TrimSpace(req.Schedule)
+	switch {
+	case req.Trigger == TriggerSchedule && req.Schedule == "":
+		return errors.New("schedule: cannot be blank for a schedule trigger")
+	case req.Trigger != TriggerSchedule && req.Schedule != "":
+		return errors.New("schedule: only allowed for a schedule trigger")
+	case req.Schedule != "":
+		if _, err := cron.ParseStandard(req.Schedule); err != nil {
+			return fmt.Errorf("schedule: %w", err)
+		}
+	}
+
+	req.Condition = strings.TrimSpace(req.Condition)
+	if req.Condition != "" {
+		program, err := expr.Compile(req.Condition)
+		if err != nil {
+			return fmt.Errorf("condition: %w", err)
+		}
+		for _, name := range program.Vars() {
+			if !slices.Contains(Variables, name) {
+				return fmt.Errorf("condition: unknown field %s (valid fields: %s)", name, strings.Join(Variables, ", "))
+			}
+		}
+	}
+
+	if len(req.Actions) == 0 {
+		return errors.New("actions: at least one action is required")
+	}
+	for i, a := range req.Actions {
+		if err := a.Validate(); err != nil {
+			return fmt.Errorf("actions[%d].%w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the action type and its required params.
+func (a Action) Validate() error {
+	required := func(name string) error {
+		if strings.TrimSpace(a.Params[name]) == "" {
+			return fmt.Errorf("params.%s: cannot be blank for %s", name, a.Type)
+		}
+		return nil
+	}
+
+	switch a.Type {
+	case ActionTransition:
+		if direction := a.Params["direction"]; direction != "next" && direction != "prev" {
+			return errors.New("params.direction: must be next or prev")
+		}
+	case ActionDeactivate:
+	case ActionTag:
+		return required("tag")
+	case ActionAssign:
+		return required("user_id")
+	case ActionNotify:
+		return required("message")
+	case ActionWebhook:
+		if err := required("url"); err != nil {
+			return err
+		}
+		u, err := url.Parse(a.Params["url"])
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("params.url: must be an http or https URL")
+		}
+	default:
+		return fmt.Errorf("type: must be one of %s", strings.Join(Actions, ", "))
+	}
+	return nil
+}
+
+// New creates a new Entity from a request.
+func New(req Request) Entity {
+	enabled := req.Enabled == nil || *req.Enabled
+	return Entity{
+		Name:        req.Name,
+		Description: req.Description,
+		Enabled:     enabled,
+		Trigger:     req.Trigger,
+		Schedule:    req.Schedule,
+		Condition:   req.Condition,
+		Actions:     req.Actions,
+	}
+}
+
+// DryRunRequest represents the request payload for a dry run. Without a client the rule is
+// evaluated against every client.
+type DryRunRequest struct {
+	ClientID string `json:"client_id"`
+}
+
+// Bind validates the request payload.
+func (req *DryRunRequest) Bind(r *http.Request) error {
+	req.ClientID = strings.TrimSpace(req.ClientID)
+	return nil
+}
@@ -0,0 +1,174 @@
+package rule
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/pkg/expr"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Triggers of a rule
+const (
+	// TriggerCreated fires when a client is created.
+	TriggerCreated = "created"
+
+	// TriggerUpdated fires when a client is updated, including its activity.
+	TriggerUpdated = "updated"
+
+	// TriggerTransitioned fires when a client moves to another stage.
+	TriggerTransitioned = "transitioned"
+
+	// TriggerSchedule fires on the cron schedule of the rule for every client.
+	TriggerSchedule = "schedule"
+)
+
+// Triggers lists the valid triggers.
+var Triggers = []string{TriggerCreated, TriggerUpdated, TriggerTransitioned, TriggerSchedule}
+
+// Action types
+const (
+	// ActionTransition moves the client to the next or previous stage (params: direction, and
+	// reason_code and comment for prev).
+	ActionTransition = "transition"
+
+	// ActionDeactivate deactivates the client (params: reason_code, comment).
+	ActionDeactivate = "deactivate"
+
+	// ActionTag adds a tag to the client (params: tag).
+	ActionTag = "tag"
+
+	// ActionAssign assigns the client to a user (params: user_id).
+	ActionAssign = "assign"
+
+	// ActionNotify sends a message through the configured notifiers (params: message).
+	ActionNotify = "notify"
+
+	// ActionWebhook posts the rule and the client as JSON to a URL (params: url).
+	ActionWebhook = "webhook"
+)
+
+// Actions lists the valid action types.
+var Actions = []string{ActionTransition, ActionDeactivate, ActionTag, ActionAssign, ActionNotify, ActionWebhook}
+
+// Statuses of an executed action
+const (
+	StatusPlanned   = "planned"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Action is a step a rule takes on a matching client.
+type Action struct {
+	Type   string            `json:"type"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Entity is an automation rule: when the trigger fires for a client matching the condition,
+// the actions are taken in order.
+type Entity struct {
+	// ID is the unique identifier for the rule (UUID).
+	ID string `db:"id" json:"id"`
+
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+
+	// Enabled rules are evaluated, disabled ones only on a dry run.
+	Enabled bool `db:"enabled" json:"enabled"`
+
+	// Trigger is created, updated, transitioned or schedule.
+	Trigger string `db:"trigger" json:"trigger"`
+
+	// Schedule is the cron spec (minute hour day month weekday, or a descriptor like @daily)
+	// of a schedule rule, in the reporting timezone.
+	Schedule string `db:"schedule" json:"schedule,omitempty"`
+
+	// Condition is an expression over client fields, e.g. `stage == "payment_waiting"`; an
+	// empty condition matches every client.
+	Condition string `db:"condition" json:"condition"`
+
+	Actions []Action `db:"actions" json:"actions"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	// program is the compiled condition, set by Compile.
+	program *expr.Program
+}
+
+// Compile compiles the condition of the rule, so evaluating it against many clients parses it
+// only once.
+func (e *Entity) Compile() error {
+	e.program = nil
+	if e.Condition == "" {
+		return nil
+	}
+
+	program, err := expr.Compile(e.Condition)
+	if err != nil {
+		return err
+	}
+	e.program = program
+	return nil
+}
+
+// Matches evaluates the condition of the rule against a client. A rule that was not compiled
+// compiles its condition on every call.
+func (e Entity) Matches(c client.Entity, fromStage string, t time.Time) (bool, error) {
+	if e.Condition == "" {
+		return true, nil
+	}
+
+	program := e.program
+	if program == nil {
+		var err error
+		if program, err = expr.Compile(e.Condition); err != nil {
+			return false, err
+		}
+	}
+	return program.Bool(Env(c, fromStage, t))
+}
+
+// Due reports whether the schedule of the rule fires within (from, to].
+func (e Entity) Due(from, to time.Time) bool {
+	schedule, err := cron.ParseStandard(e.Schedule)
+	if err != nil {
+		return false
+	}
+	next := schedule.Next(from)
+	return !next.IsZero() && !next.After(to)
+}
+
+// ActionResult is the outcome of an action of an execution.
+type ActionResult struct {
+	Action
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Execution records a rule matching a client and the outcome of its actions.
+type Execution struct {
+	ID       string `db:"id" json:"id"`
+	RuleID   string `db:"rule_id" json:"rule_id"`
+	ClientID string `db:"client_id" json:"client_id"`
+
+	// Trigger is the event that fired the rule.
+	Trigger string `db:"trigger" json:"trigger"`
+
+	// DryRun executions only plan their actions.
+	DryRun bool `db:"dry_run" json:"dry_run"`
+
+	Results []ActionResult `db:"results" json:"results"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Failed reports whether any action of the execution failed.
+func (e Execution) Failed() bool {
+	for _, r := range e.Results {
+		if r.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
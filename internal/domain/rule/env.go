@@ -0,0 +1,67 @@
+package rule
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/pkg/expr"
+	"slices"
+	"time"
+)
+
+// Variables lists the client fields a condition can refer to: the categorical fields, from_stage
+// (the stage a transitioned client left), name, email, score, tags, assignee and the numeric
+// fields.
+var Variables = slices.Concat(
+	client.Fields,
+	[]string{"from_stage", "name", "email", "score", "tags", "assignee"},
+	client.NumericFields,
+)
+
+// Env returns the condition environment of a client as of t. Unset fields are null.
+func Env(c client.Entity, fromStage string, t time.Time) expr.Env {
+	return func(name string) (any, bool) {
+		if !slices.Contains(Variables, name) {
+			return nil, false
+		}
+
+		switch name {
+		case "is_active":
+			if c.IsActive == nil {
+				return nil, true
+			}
+			return *c.IsActive, true
+		case "from_stage":
+			return fromStage, true
+		case "name":
+			return optional(c.Name), true
+		case "email":
+			return optional(c.Email), true
+		case "score":
+			if c.Score == nil {
+				return nil, true
+			}
+			return float64(*c.Score), true
+		case "tags":
+			if c.Tags == nil {
+				return []string{}, true
+			}
+			return c.Tags, true
+		case "assignee":
+			return optional(c.AssigneeID), true
+		}
+
+		if value, ok := c.Field(name); ok {
+			return value, true
+		}
+		if value, ok := c.NumericField(name, t); ok {
+			return value, true
+		}
+		return nil, true
+	}
+}
+
+func optional(value *string) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -0,0 +1,47 @@
+package rule
+
+import (
+	"context"
+)
+
+// Repository defines the interface for rule persistence.
+type Repository interface {
+	// Create stores a rule.
+	Create(ctx context.Context, data Entity) (Entity, error)
+
+	// Get retrieves a rule by its ID.
+	Get(ctx context.Context, id string) (Entity, error)
+
+	// List retrieves the rules matching the filters, oldest first, the order they are evaluated in.
+	List(ctx context.Context, filters Filters) ([]Entity, error)
+
+	// Update replaces a rule.
+	Update(ctx context.Context, id string, data Entity) (Entity, error)
+
+	// Delete removes a rule and its execution log.
+	Delete(ctx context.Context, id string) error
+}
+
+// ExecutionRepository defines the interface for the rule execution log.
+type ExecutionRepository interface {
+	// Add records an execution.
+	Add(ctx context.Context, data Execution) (Execution, error)
+
+	// List retrieves the executions matching the filters, newest first, and their total count.
+	List(ctx context.Context, filters ExecutionFilters, limit, offset int) ([]Execution, int, error)
+}
+
+// Notifier sends the messages of notify actions.
+type Notifier interface {
+	// Name identifies the notifier in logs.
+	Name() string
+
+	// Send sends a message with a subject.
+	Send(ctx context.Context, subject, text string) error
+}
+
+// Caller performs the requests of webhook actions.
+type Caller interface {
+	// Post posts the payload as JSON to the URL.
+	Post(ctx context.Context, url string, payload any) error
+}
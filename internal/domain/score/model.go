@@ -0,0 +1,151 @@
+package score
+
+import (
+	"TrackMe/internal/domain/client"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// StageProgress is the rule field measuring how far the client is in the funnel, from 0 in the
+// first stage to 1 in the last one.
+const StageProgress = "stage_progress"
+
+// MonthlyAmount is the rule field of the monthly amount of the active contracts of the client in
+// the base currency. It is passed to Score, as converting the amounts needs exchange rates.
+const MonthlyAmount = "monthly_amount"
+
+// Any is the key of the points of categorical values not listed in a rule.
+const Any = "*"
+
+// Step gives points to numeric values within [Min, Max]; an unset bound is open.
+type Step struct {
+	Min    *float64 `yaml:"min" json:"min,omitempty"`
+	Max    *float64 `yaml:"max" json:"max,omitempty"`
+	Points float64  `yaml:"points" json:"points"`
+}
+
+// Matches reports whether the value lies within the step.
+func (s Step) Matches(value float64) bool {
+	return (s.Min == nil || value >= *s.Min) && (s.Max == nil || value <= *s.Max)
+}
+
+// Rule scores one client field. Points are the share of the weight a client earns, from 0 to 1.
+type Rule struct {
+	// Field is a categorical client field (source, channel, app, stage, is_active), a numeric one
+	// (e.g. days_since_login, monthly_amount) or stage_progress.
+	Field string `yaml:"field" json:"field"`
+
+	// Weight is the relative importance of the rule.
+	Weight float64 `yaml:"weight" json:"weight"`
+
+	// Values are the points per value of a categorical field; "*" applies to unlisted values.
+	Values map[string]float64 `yaml:"values" json:"values,omitempty"`
+
+	// Steps are the points of a numeric field, the first matching step applies. stage_progress
+	// without steps earns its value as points.
+	Steps []Step `yaml:"steps" json:"steps,omitempty"`
+}
+
+// Model is a weighted set of scoring rules.
+type Model struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Validate checks that every rule refers to a known field and is complete for its kind.
+func (m Model) Validate() error {
+	for i, r := range m.Rules {
+		if r.Weight <= 0 {
+			return fmt.Errorf("rules[%d].weight: must be positive", i)
+		}
+
+		switch {
+		case slices.Contains(client.Fields, r.Field):
+			if len(r.Values) == 0 {
+				return fmt.Errorf("rules[%d].values: cannot be blank for %s", i, r.Field)
+			}
+		case slices.Contains(client.NumericFields, r.Field):
+			if len(r.Steps) == 0 {
+				return fmt.Errorf("rules[%d].steps: cannot be blank for %s", i, r.Field)
+			}
+		case r.Field == StageProgress:
+		default:
+			return fmt.Errorf("rules[%d].field: unknown field %q", i, r.Field)
+		}
+
+		for value, points := range r.Values {
+			if points < 0 || points > 1 {
+				return fmt.Errorf("rules[%d].values.%s: points must be between 0 and 1", i, value)
+			}
+		}
+		for j, s := range r.Steps {
+			if s.Points < 0 || s.Points > 1 {
+				return fmt.Errorf("rules[%d].steps[%d].points: must be between 0 and 1", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// Uses reports whether a rule of the model scores the field.
+func (m Model) Uses(field string) bool {
+	return slices.ContainsFunc(m.Rules, func(r Rule) bool { return r.Field == field })
+}
+
+// Score returns the score of a client as of t from 0 to 100: the points earned per rule weighted
+// and scaled to the total weight. progress is the stage progress of the client from 0 to 1 and
+// amount its monthly amount in the base currency.
+func (m Model) Score(c client.Entity, progress, amount float64, t time.Time) int {
+	var total, earned float64
+	for _, r := range m.Rules {
+		total += r.Weight
+		earned += r.Weight * r.points(c, progress, amount, t)
+	}
+	if total == 0 {
+		return 0
+	}
+	return int(math.Round(100 * earned / total))
+}
+
+// points returns the share of the weight of the rule the client earns
+func (r Rule) points(c client.Entity, progress, amount float64, t time.Time) float64 {
+	if len(r.Values) > 0 {
+		value, _ := c.Field(r.Field)
+		if points, ok := r.Values[value]; ok {
+			return points
+		}
+		return r.Values[Any]
+	}
+
+	var value float64
+	switch r.Field {
+	case StageProgress:
+		if len(r.Steps) == 0 {
+			return progress
+		}
+		value = progress
+	case MonthlyAmount:
+		value = amount
+	default:
+		v, ok := c.NumericField(r.Field, t)
+		if !ok {
+			return 0
+		}
+		value = v
+	}
+
+	for _, s := range r.Steps {
+		if s.Matches(value) {
+			return s.Points
+		}
+	}
+	return 0
+}
+
+// Repository defines the interface for scoring model sources.
+type Repository interface {
+	// Model returns the scoring model, nil when scoring is not configured.
+	Model(ctx context.Context) (*Model, error)
+}
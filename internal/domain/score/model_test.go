@@ -0,0 +1,135 @@
+package score
+
+import (
+	"TrackMe/internal/domain/client"
+	"testing"
+	"time"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestScore(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	model := Model{Rules: []Rule{
+		{Field: "source", Weight: 2, Values: map[string]float64{"partner": 1, Any: 0.2}},
+		{Field: "days_since_login", Weight: 1, Steps: []Step{
+			{Max: ptr(7.0), Points: 1},
+			{Max: ptr(30.0), Points: 0.5},
+		}},
+		{Field: StageProgress, Weight: 1},
+	}}
+
+	tests := []struct {
+		name     string
+		client   client.Entity
+		progress float64
+		want     int
+	}{
+		{
+			name:     "every rule earns",
+			client:   client.Entity{Source: ptr("partner"), LastLogin: ptr(now.Add(-2 * 24 * time.Hour))},
+			progress: 1,
+			want:     100,
+		},
+		{
+			name:     "second step and half progress",
+			client:   client.Entity{Source: ptr("partner"), LastLogin: ptr(now.Add(-10 * 24 * time.Hour))},
+			progress: 0.5,
+			want:     75,
+		},
+		{
+			name:     "unlisted value earns the default",
+			client:   client.Entity{Source: ptr("ads"), LastLogin: ptr(now.Add(-60 * 24 * time.Hour))},
+			progress: 0,
+			want:     10,
+		},
+		{
+			name:     "unset fields",
+			client:   client.Entity{},
+			progress: 0,
+			want:     10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.Score(tt.client, tt.progress, 0, now); got != tt.want {
+				t.Errorf("Score() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreStageProgressSteps(t *testing.T) {
+	model := Model{Rules: []Rule{
+		{Field: StageProgress, Weight: 1, Steps: []Step{{Min: ptr(0.5), Points: 1}}},
+	}}
+
+	if got := model.Score(client.Entity{}, 0.4, 0, time.Now()); got != 0 {
+		t.Errorf("Score() below the step = %d, want 0", got)
+	}
+	if got := model.Score(client.Entity{}, 0.5, 0, time.Now()); got != 100 {
+		t.Errorf("Score() within the step = %d, want 100", got)
+	}
+}
+
+func TestScoreMonthlyAmount(t *testing.T) {
+	model := Model{Rules: []Rule{
+		{Field: MonthlyAmount, Weight: 1, Steps: []Step{{Min: ptr(1000.0), Points: 1}, {Min: ptr(100.0), Points: 0.5}}},
+	}}
+
+	// The amount is given in the base currency, whatever the currencies of the contracts
+	tests := []struct {
+		amount float64
+		want   int
+	}{
+		{0, 0},
+		{150, 50},
+		{1000, 100},
+	}
+	for _, tt := range tests {
+		if got := model.Score(client.Entity{}, 0, tt.amount, time.Now()); got != tt.want {
+			t.Errorf("Score() with amount %.0f = %d, want %d", tt.amount, got, tt.want)
+		}
+	}
+
+	if !model.Uses(MonthlyAmount) {
+		t.Error("Uses(monthly_amount) = false, want true")
+	}
+	if model.Uses(StageProgress) {
+		t.Error("Uses(stage_progress) = true, want false")
+	}
+}
+
+func TestScoreWithoutRules(t *testing.T) {
+	if got := (Model{}).Score(client.Entity{}, 1, 0, time.Now()); got != 0 {
+		t.Errorf("Score() = %d, want 0", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"categorical", Rule{Field: "source", Weight: 1, Values: map[string]float64{"partner": 1}}, false},
+		{"numeric", Rule{Field: "contracts", Weight: 1, Steps: []Step{{Points: 1}}}, false},
+		{"stage progress", Rule{Field: StageProgress, Weight: 1}, false},
+		{"zero weight", Rule{Field: StageProgress}, true},
+		{"unknown field", Rule{Field: "age", Weight: 1, Steps: []Step{{Points: 1}}}, true},
+		{"categorical without values", Rule{Field: "source", Weight: 1}, true},
+		{"numeric without steps", Rule{Field: "contracts", Weight: 1}, true},
+		{"value points above 1", Rule{Field: "source", Weight: 1, Values: map[string]float64{"partner": 2}}, true},
+		{"negative step points", Rule{Field: "contracts", Weight: 1, Steps: []Step{{Points: -1}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Model{Rules: []Rule{tt.rule}}.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
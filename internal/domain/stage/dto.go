@@ -12,6 +12,8 @@ type Request struct {
 	Order              int      `json:"order"`
 	AllowedTransitions []string `json:"allowed_transitions"`
 	LastUpdated        string   `json:"last_updated"`
+	SLAHours           *float64 `json:"sla_hours,omitempty"`
+	SLABusinessHours   *float64 `json:"sla_business_hours,omitempty"`
 }
 
 // Bind validates the request payload.
@@ -29,6 +31,8 @@ type Response struct {
 	Order              int      `json:"order"`
 	AllowedTransitions []string `json:"allowed_transitions"`
 	LastUpdated        string   `json:"last_updated"`
+	SLAHours           *float64 `json:"sla_hours,omitempty"`
+	SLABusinessHours   *float64 `json:"sla_business_hours,omitempty"`
 }
 
 // ParseFromEntity creates a new Response from a given Entity.
@@ -39,6 +43,8 @@ func ParseFromEntity(entity Entity) Response {
 		Order:              *entity.Order,
 		AllowedTransitions: entity.AllowedTransitions,
 		LastUpdated:        *entity.LastUpdated,
+		SLAHours:           entity.SLAHours,
+		SLABusinessHours:   entity.SLABusinessHours,
 	}
 }
 
@@ -16,6 +16,12 @@ type Entity struct {
 
 	// LastUpdated is the date when the client transitioned to this stage.
 	LastUpdated *string `db:"last_updated" bson:"last_updated"`
+
+	// SLAHours is the maximum calendar hours a client may spend in the stage, nil when unbounded.
+	SLAHours *float64 `db:"sla_hours" bson:"sla_hours"`
+
+	// SLABusinessHours is the maximum working hours a client may spend in the stage, nil when unbounded.
+	SLABusinessHours *float64 `db:"sla_business_hours" bson:"sla_business_hours"`
 }
 
 // New creates a new Stage instance.
@@ -26,5 +32,7 @@ func New(req Request) Entity {
 		Order:              &req.Order,
 		AllowedTransitions: req.AllowedTransitions,
 		LastUpdated:        &req.LastUpdated,
+		SLAHours:           req.SLAHours,
+		SLABusinessHours:   req.SLABusinessHours,
 	}
 }
@@ -0,0 +1,54 @@
+package touchpoint
+
+import (
+	"TrackMe/pkg/store"
+	"fmt"
+)
+
+// Attribution models
+const (
+	FirstTouch = "first_touch"
+	LastTouch  = "last_touch"
+	Linear     = "linear"
+)
+
+// Models lists the attribution models, the default one first.
+var Models = []string{LastTouch, FirstTouch, Linear}
+
+// ValidModel returns an error for an unknown attribution model.
+func ValidModel(model string) error {
+	for _, m := range Models {
+		if m == model {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w model: %s (valid values: %s, %s, %s)", store.ErrorInvalid, model, LastTouch, FirstTouch, Linear)
+}
+
+// Credit splits one conversion between the values of the touches, ordered oldest first:
+// first_touch credits the first value, last_touch the last one and linear every touch equally.
+// Empty values are skipped. The credits sum to 1 unless there are no touches.
+func Credit(model string, touches []string) map[string]float64 {
+	values := make([]string, 0, len(touches))
+	for _, v := range touches {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	switch model {
+	case FirstTouch:
+		return map[string]float64{values[0]: 1}
+	case Linear:
+		res := make(map[string]float64, len(values))
+		for _, v := range values {
+			res[v] += 1 / float64(len(values))
+		}
+		return res
+	default:
+		return map[string]float64{values[len(values)-1]: 1}
+	}
+}
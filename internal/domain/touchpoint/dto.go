@@ -0,0 +1,25 @@
+package touchpoint
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Request represents the request payload for ingesting a touchpoint event.
+type Request struct {
+	Source     string    `json:"source"`
+	Channel    string    `json:"channel"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Bind validates the request payload.
+func (req *Request) Bind(r *http.Request) error {
+	req.Source = strings.TrimSpace(req.Source)
+	req.Channel = strings.TrimSpace(req.Channel)
+	if req.Source == "" && req.Channel == "" {
+		return errors.New("source, channel: at least one cannot be blank")
+	}
+	return nil
+}
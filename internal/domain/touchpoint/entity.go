@@ -0,0 +1,24 @@
+package touchpoint
+
+import "time"
+
+// Origins of a touchpoint
+const (
+	// OriginClient is a touchpoint recorded when a client is created with or changes its source or channel.
+	OriginClient = "client"
+
+	// OriginEvent is a touchpoint ingested as an event, e.g. an ad click of a known client.
+	OriginEvent = "event"
+)
+
+// Entity represents a contact of a client with an acquisition source and channel.
+type Entity struct {
+	ClientID string `db:"client_id" json:"client_id"`
+	Source   string `db:"source" json:"source"`
+	Channel  string `db:"channel" json:"channel"`
+
+	// Origin is client or event.
+	Origin string `db:"origin" json:"origin"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package touchpoint
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for touchpoint event storage.
+type Repository interface {
+	// Add inserts a touchpoint.
+	Add(ctx context.Context, data Entity) error
+
+	// List retrieves the touchpoints recorded up to asOf inclusive, ordered by client and time.
+	List(ctx context.Context, asOf time.Time) ([]Entity, error)
+
+	// ListByClient retrieves the touchpoints of a client, oldest first.
+	ListByClient(ctx context.Context, clientID string) ([]Entity, error)
+}
@@ -0,0 +1,43 @@
+package transition
+
+import "time"
+
+// Entity represents a client moving from one stage to another.
+type Entity struct {
+	// ClientID is the client that moved.
+	ClientID string `db:"client_id" bson:"client_id"`
+
+	// FromStage is the stage the client left, empty when the client was created.
+	FromStage string `db:"from_stage" bson:"from_stage"`
+
+	// ToStage is the stage the client entered.
+	ToStage string `db:"to_stage" bson:"to_stage"`
+
+	// Source is the acquisition source of the client at the time of the transition.
+	Source string `db:"source" bson:"source"`
+
+	// Channel is the acquisition channel of the client at the time of the transition.
+	Channel string `db:"channel" bson:"channel"`
+
+	// Reason and Comment explain a move back to a previous stage.
+	Reason  string `db:"reason" bson:"reason"`
+	Comment string `db:"comment" bson:"comment"`
+
+	// CreatedAt is the timestamp of the transition.
+	CreatedAt time.Time `db:"created_at" bson:"created_at"`
+}
+
+// Stay is the time a client spent in a stage, between entering it and its next transition.
+type Stay struct {
+	ClientID  string    `db:"client_id"`
+	Stage     string    `db:"stage"`
+	EnteredAt time.Time `db:"entered_at"`
+	LeftAt    time.Time `db:"left_at"`
+}
+
+// Journey is the time from a client's first transition to the first time it reached a stage.
+type Journey struct {
+	ClientID   string    `db:"client_id"`
+	StartedAt  time.Time `db:"started_at"`
+	FinishedAt time.Time `db:"finished_at"`
+}
@@ -0,0 +1,35 @@
+package transition
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for stage transition event operations.
+type Repository interface {
+	// Add records a transition.
+	Add(ctx context.Context, data Entity) error
+
+	// List retrieves the transitions recorded between from and to inclusive, ordered by client
+	// and time. A zero from or to leaves that side of the range open.
+	List(ctx context.Context, from, to time.Time) ([]Entity, error)
+
+	// Clients returns the IDs of the clients with transitions recorded up to the given time.
+	Clients(ctx context.Context, before time.Time) ([]string, error)
+
+	// StageDurationQuantiles returns, per stage, the quantiles at levels of the seconds clients
+	// spent in the stage as of asOf. A stay that has not ended yet lasts until asOf. The extra
+	// seconds per stage, e.g. of clients without transitions, are included in the quantiles.
+	StageDurationQuantiles(ctx context.Context, asOf time.Time, levels []float64, extra map[string][]float64) (map[string][]float64, error)
+
+	// JourneyDurationQuantiles returns the quantiles at levels of the seconds from a client's
+	// first transition to the first time it entered lastStage, for clients that reached it by asOf,
+	// and the number of journeys. The extra seconds are included as further journeys.
+	JourneyDurationQuantiles(ctx context.Context, lastStage string, asOf time.Time, levels []float64, extra []float64) ([]float64, int, error)
+
+	// Stays returns every stay in a stage as of asOf. A stay that has not ended yet lasts until asOf.
+	Stays(ctx context.Context, asOf time.Time) ([]Stay, error)
+
+	// Journeys returns the journey of every client that reached lastStage by asOf.
+	Journeys(ctx context.Context, lastStage string, asOf time.Time) ([]Journey, error)
+}
@@ -68,12 +68,18 @@ func WithHTTPHandler() Configuration {
 		clientHandler := http.NewClientHandler(h.dependencies.TrackService, tokenManager)
 		userHandler := http.NewUserHandler(h.dependencies.TrackService, tokenManager)
 		metricHandler := http.NewMetricHandler(h.dependencies.TrackService, tokenManager)
+		analyticsHandler := http.NewAnalyticsHandler(h.dependencies.TrackService, tokenManager)
+		experimentHandler := http.NewExperimentHandler(h.dependencies.TrackService, tokenManager)
+		ruleHandler := http.NewRuleHandler(h.dependencies.TrackService, tokenManager)
 
 		h.HTTP.Route(basePath+"/", func(r chi.Router) {
 			r.Mount("/auth", authHandler.Routes())
 			r.Mount("/clients", clientHandler.Routes())
 			r.Mount("/users", userHandler.Routes())
 			r.Mount("/metrics", metricHandler.Routes())
+			r.Mount("/analytics", analyticsHandler.Routes())
+			r.Mount("/experiments", experimentHandler.Routes())
+			r.Mount("/rules", ruleHandler.Routes())
 		})
 		return
 	}
@@ -0,0 +1,227 @@
+package http
+
+import (
+	"TrackMe/internal/service/track"
+	"TrackMe/pkg/jwt"
+	"TrackMe/pkg/server/middleware"
+	"TrackMe/pkg/server/response"
+	"TrackMe/pkg/store"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AnalyticsHandler struct {
+	trackService track.AnalyticsTrackService
+	tokenManager *jwt.TokenManager
+}
+
+func NewAnalyticsHandler(s track.AnalyticsTrackService, tm *jwt.TokenManager) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		trackService: s,
+		tokenManager: tm,
+	}
+}
+
+func (h *AnalyticsHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	// All routes require authentication
+	r.Use(middleware.AuthMiddleware(h.tokenManager))
+
+	r.Get("/cohorts", h.cohorts)
+	r.Get("/flows", h.flows)
+	r.Get("/bottlenecks", h.bottlenecks)
+	r.Get("/forecast", h.forecast)
+
+	return r
+}
+
+// @Summary Cohort matrix by registration period
+// @Description Groups clients by the period of their registration and returns, per cohort, the share that reached a stage (conversion) or stayed active (active) after every following period
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param by query string false "Registration period (day, week, month), default week"
+// @Param metric query string false "conversion or active, default conversion"
+// @Param stage query string false "Stage a conversion counts towards, default the last stage"
+// @Param periods query integer false "Number of cohorts including the current period (default 8, at most 52)"
+// @Success 200 {object} analytics.CohortMatrix
+// @Failure 400 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /analytics/cohorts [get]
+// @Security BearerAuth
+func (h *AnalyticsHandler) cohorts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	by := query.Get("by")
+	if by == "" {
+		by = "week"
+	}
+
+	metricName := query.Get("metric")
+	if metricName == "" {
+		metricName = "conversion"
+	}
+
+	periods := 8
+	if p := query.Get("periods"); p != "" {
+		pInt, err := strconv.Atoi(p)
+		if err != nil {
+			response.BadRequest(w, r, errors.New("periods: must be a number"), p)
+			return
+		}
+		periods = pInt
+	}
+
+	res, err := h.trackService.CohortAnalysis(r.Context(), by, metricName, query.Get("stage"), periods)
+	if err != nil {
+		if errors.Is(err, store.ErrorInvalid) {
+			response.BadRequest(w, r, err, nil)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Client journey flows
+// @Description Returns Sankey nodes (stages) and links (transition counts, rollbacks flagged), the most common client paths and the round trips between stages
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param from query string false "Transitions at or after (YYYY-MM-DD or RFC3339), default 30 days before to"
+// @Param to query string false "Transitions at or before, a date includes the whole day (YYYY-MM-DD or RFC3339), default now"
+// @Param limit query integer false "Number of most common paths (default 10, at most 100)"
+// @Success 200 {object} analytics.Flows
+// @Failure 400 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /analytics/flows [get]
+// @Security BearerAuth
+func (h *AnalyticsHandler) flows(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var from, to time.Time
+	if v := query.Get("from"); v != "" {
+		t, err := parseTime(v, h.trackService.Location())
+		if err != nil {
+			response.BadRequest(w, r, errors.New("from: invalid date"), v)
+			return
+		}
+		from = t
+	}
+	if v := query.Get("to"); v != "" {
+		t, err := parseTime(v, h.trackService.Location())
+		if err != nil {
+			response.BadRequest(w, r, errors.New("to: invalid date"), v)
+			return
+		}
+		// A date covers the whole day
+		if _, err = time.Parse("2006-01-02", v); err == nil {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		to = t
+	}
+
+	limit := 10
+	if l := query.Get("limit"); l != "" {
+		lInt, err := strconv.Atoi(l)
+		if err != nil || lInt < 1 || lInt > 100 {
+			response.BadRequest(w, r, errors.New("limit: must be a number between 1 and 100"), l)
+			return
+		}
+		limit = lInt
+	}
+
+	res, err := h.trackService.ClientFlows(r.Context(), from, to, limit)
+	if err != nil {
+		if errors.Is(err, store.ErrorInvalid) {
+			response.BadRequest(w, r, err, nil)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Stage bottleneck report
+// @Description Ranks stages by median dwell time, queue size (clients-per-stage), outflow rate and rollback rate over the last 7 days, with the change from the 7 days before and an explanation per stage
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Success 200 {object} analytics.BottleneckReport
+// @Failure 500 {object} response.Object
+// @Router /analytics/bottlenecks [get]
+// @Security BearerAuth
+func (h *AnalyticsHandler) bottlenecks(w http.ResponseWriter, r *http.Request) {
+	res, err := h.trackService.Bottlenecks(r.Context())
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Pipeline completion forecast
+// @Description Fits a Markov model of stage transitions and predicts how many active clients reach the last stage within the horizon, with a 90% band, per-stage transition probabilities and expected time to completion, and per-client completion probabilities
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param horizon query string false "Forecast horizon in days (30d), weeks (4w) or hours (720h), default 30d"
+// @Success 200 {object} analytics.Forecast
+// @Failure 400 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /analytics/forecast [get]
+// @Security BearerAuth
+func (h *AnalyticsHandler) forecast(w http.ResponseWriter, r *http.Request) {
+	horizon := r.URL.Query().Get("horizon")
+	if horizon == "" {
+		horizon = "30d"
+	}
+
+	days, err := parseDays(horizon)
+	if err != nil {
+		response.BadRequest(w, r, errors.New("horizon: must be a number of days (30d), weeks (4w) or a duration (720h)"), horizon)
+		return
+	}
+
+	res, err := h.trackService.ForecastCompletions(r.Context(), days)
+	if err != nil {
+		if errors.Is(err, store.ErrorInvalid) {
+			response.BadRequest(w, r, err, horizon)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// parseDays parses a number of days (30d), weeks (4w) or a duration (720h) into whole days,
+// rounding partial days up
+func parseDays(s string) (int, error) {
+	switch {
+	case strings.HasSuffix(s, "d"):
+		return strconv.Atoi(strings.TrimSuffix(s, "d"))
+	case strings.HasSuffix(s, "w"):
+		weeks, err := strconv.Atoi(strings.TrimSuffix(s, "w"))
+		return weeks * 7, err
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Ceil(d.Hours() / 24)), nil
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"github.com/go-chi/render"
 
 	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/touchpoint"
 	"TrackMe/internal/domain/user"
 	"TrackMe/internal/service/track"
 	"TrackMe/pkg/jwt"
@@ -41,8 +43,13 @@ func (h *ClientHandler) Routes() chi.Router {
 	r.Group(func(r chi.Router) {
 		r.Get("/", h.list)
 		r.Post("/", h.create)
+		r.Get("/reasons", h.reasons)
 		r.Put("/{id}/stage", h.update)
 		r.Delete("/{id}", h.delete)
+		r.Get("/{id}/touchpoints", h.listTouchpoints)
+		r.Post("/{id}/touchpoints", h.addTouchpoint)
+		r.Post("/{id}/deactivate", h.deactivate)
+		r.Post("/{id}/reactivate", h.reactivate)
 
 	})
 
@@ -62,20 +69,27 @@ func (h *ClientHandler) Routes() chi.Router {
 // @Param       is_active query boolean false "Filter by active status (default: true)"
 // @Param       updated query string false "Filter by last updated after date (YYYY-MM-DD)"
 // @Param       last_login query string false "Filter by last login date after (YYYY-MM-DD)"
+// @Param       min_score query integer false "Filter by lead score of at least (0-100)"
+// @Param       tag query string false "Filter by tag"
+// @Param       assignee query string false "Filter by assigned user ID"
+// @Param       sort query string false "Order by score (highest first) instead of last update" Enums(score)
 // @Param       limit query integer false "Pagination limit (default 50)"
 // @Param       offset query integer false "Pagination offset (default 0)"
 // @Success     200 {array} client.Response
+// @Failure     400 {object} response.Object
 // @Failure     500 {object} response.Object
 // @Router      /clients [get]
 // @Security BearerAuth
 func (h *ClientHandler) list(w http.ResponseWriter, r *http.Request) {
 	filters := client.Filters{
-		ID:        r.URL.Query().Get("id"),
-		Stage:     r.URL.Query().Get("stage"),
-		Source:    r.URL.Query().Get("source"),
-		Channel:   r.URL.Query().Get("channel"),
-		AppStatus: r.URL.Query().Get("app"),
-		IsActive:  parseBool(r.URL.Query().Get("is_active"), true),
+		ID:         r.URL.Query().Get("id"),
+		Stage:      r.URL.Query().Get("stage"),
+		Source:     r.URL.Query().Get("source"),
+		Channel:    r.URL.Query().Get("channel"),
+		AppStatus:  r.URL.Query().Get("app"),
+		IsActive:   parseBool(r.URL.Query().Get("is_active"), true),
+		Tag:        r.URL.Query().Get("tag"),
+		AssigneeID: r.URL.Query().Get("assignee"),
 	}
 
 	if updated := r.URL.Query().Get("updated"); updated != "" {
@@ -90,6 +104,24 @@ func (h *ClientHandler) list(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if minScore := r.URL.Query().Get("min_score"); minScore != "" {
+		value, err := strconv.Atoi(minScore)
+		if err != nil || value < 0 || value > 100 {
+			response.BadRequest(w, r, errors.New("invalid min_score: must be an integer between 0 and 100"), minScore)
+			return
+		}
+		filters.MinScore = &value
+	}
+
+	switch sort := r.URL.Query().Get("sort"); sort {
+	case "", "last_updated":
+	case client.SortScore:
+		filters.Sort = sort
+	default:
+		response.BadRequest(w, r, errors.New("invalid sort: must be score or last_updated"), sort)
+		return
+	}
+
 	limit := 50
 	if l := r.URL.Query().Get("limit"); l != "" {
 		if lInt, err := strconv.Atoi(l); err == nil && lInt > 0 {
@@ -222,6 +254,8 @@ func (h *ClientHandler) update(w http.ResponseWriter, r *http.Request) {
 			response.NotFound(w, r, err)
 		case strings.Contains(err.Error(), "invalid stage transition"):
 			response.BadRequest(w, r, err, req.Stage)
+		case errors.Is(err, store.ErrorInvalid):
+			response.BadRequest(w, r, err, req.ReasonCode)
 
 		default:
 			response.InternalServerError(w, r, err)
@@ -276,3 +310,155 @@ func (h *ClientHandler) delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// @Summary List reason codes
+// @Description Returns the reason taxonomy: the reason codes a move back to a previous stage (PUT /clients/{id}/stage with stage "prev") and a deactivation can be given, with the stages each is restricted to
+// @Tags clients
+// @Produce json
+// @Success 200 {object} reason.Taxonomy
+// @Router /clients/reasons [get]
+// @Security BearerAuth
+func (h *ClientHandler) reasons(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, r, h.trackService.ReasonTaxonomy(r.Context()), nil)
+}
+
+// @Summary List client touchpoints
+// @Description Returns the touchpoint history of a client, oldest first: its sources and channels and the ingested touchpoint events
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {array} touchpoint.Entity
+// @Failure 404 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /clients/{id}/touchpoints [get]
+// @Security BearerAuth
+func (h *ClientHandler) listTouchpoints(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	res, err := h.trackService.ListTouchpoints(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrorNotFound) {
+			response.NotFound(w, r, err)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Ingest a client touchpoint
+// @Description Records a contact of a client with a source or channel, e.g. an ad click, for multi-touch attribution. The source and channel of the client are not changed.
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param request body touchpoint.Request true "body param"
+// @Success 201 {object} touchpoint.Entity
+// @Failure 400 {object} response.Object
+// @Failure 404 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /clients/{id}/touchpoints [post]
+// @Security BearerAuth
+func (h *ClientHandler) addTouchpoint(w http.ResponseWriter, r *http.Request) {
+	// Check role - only admin and super_user can record touchpoints
+	claims, _ := middleware.GetUserFromContext(r.Context())
+	if claims.Role == user.RoleManager {
+		response.Forbidden(w, r, errors.New("managers have read-only access"))
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	var req touchpoint.Request
+	if err := render.Bind(r, &req); err != nil {
+		response.BadRequest(w, r, err, req)
+		return
+	}
+
+	res, err := h.trackService.AddTouchpoint(r.Context(), id, req)
+	if err != nil {
+		if errors.Is(err, store.ErrorNotFound) {
+			response.NotFound(w, r, err)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.Created(w, r, res)
+}
+
+// @Summary Deactivate client
+// @Description Marks an active client inactive, optionally with a reason_code of the deactivation reason taxonomy (see GET /clients/reasons) and a comment.
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param request body client.ActivityRequest true "body param"
+// @Success 200 {object} client.Response
+// @Failure 400 {object} response.Object
+// @Failure 404 {object} response.Object
+// @Failure 409 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /clients/{id}/deactivate [post]
+// @Security BearerAuth
+func (h *ClientHandler) deactivate(w http.ResponseWriter, r *http.Request) {
+	h.changeActivity(w, r, h.trackService.DeactivateClient)
+}
+
+// @Summary Reactivate client
+// @Description Marks an inactive client active again, optionally with a reason code and comment
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param request body client.ActivityRequest false "body param"
+// @Success 200 {object} client.Response
+// @Failure 400 {object} response.Object
+// @Failure 404 {object} response.Object
+// @Failure 409 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /clients/{id}/reactivate [post]
+// @Security BearerAuth
+func (h *ClientHandler) reactivate(w http.ResponseWriter, r *http.Request) {
+	h.changeActivity(w, r, h.trackService.ReactivateClient)
+}
+
+// changeActivity binds an activity request and applies it with change
+func (h *ClientHandler) changeActivity(w http.ResponseWriter, r *http.Request,
+	change func(ctx context.Context, id string, req client.ActivityRequest) (client.Response, error)) {
+	// Check role - only admin and super_user can change the activity of a client
+	claims, _ := middleware.GetUserFromContext(r.Context())
+	if claims.Role == user.RoleManager {
+		response.Forbidden(w, r, errors.New("managers have read-only access"))
+		return
+	}
+
+	var req client.ActivityRequest
+	if r.ContentLength != 0 {
+		if err := render.Bind(r, &req); err != nil {
+			response.BadRequest(w, r, err, req)
+			return
+		}
+	}
+
+	res, err := change(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrorNotFound):
+			response.NotFound(w, r, err)
+		case errors.Is(err, client.ErrAlreadyInState):
+			response.Conflict(w, r, err)
+		case errors.Is(err, store.ErrorInvalid):
+			response.BadRequest(w, r, err, req)
+		default:
+			response.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
@@ -0,0 +1,216 @@
+package http
+
+import (
+	"TrackMe/internal/domain/experiment"
+	"TrackMe/internal/domain/user"
+	"TrackMe/internal/service/track"
+	"TrackMe/pkg/jwt"
+	"TrackMe/pkg/server/middleware"
+	"TrackMe/pkg/server/response"
+	"TrackMe/pkg/store"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type ExperimentHandler struct {
+	trackService track.ExperimentTrackService
+	tokenManager *jwt.TokenManager
+}
+
+func NewExperimentHandler(s track.ExperimentTrackService, tm *jwt.TokenManager) *ExperimentHandler {
+	return &ExperimentHandler{
+		trackService: s,
+		tokenManager: tm,
+	}
+}
+
+func (h *ExperimentHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	// All routes require authentication, managers can only read
+	r.Use(middleware.AuthMiddleware(h.tokenManager))
+
+	r.Get("/", h.list)
+	r.Post("/", h.create)
+
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.get)
+		r.Post("/assignments", h.assign)
+		r.Get("/results", h.results)
+	})
+
+	return r
+}
+
+// @Summary Create an experiment
+// @Description Creates an A/B experiment on the client funnel. The first variant is the control; variants without a weight split the clients evenly. goal_stage defaults to the last stage.
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param request body experiment.Request true "body param"
+// @Success 201 {object} experiment.Entity
+// @Failure 400 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /experiments [post]
+// @Security BearerAuth
+func (h *ExperimentHandler) create(w http.ResponseWriter, r *http.Request) {
+	if !h.canWrite(w, r) {
+		return
+	}
+
+	var req experiment.Request
+	if err := render.Bind(r, &req); err != nil {
+		response.BadRequest(w, r, err, req)
+		return
+	}
+
+	res, err := h.trackService.CreateExperiment(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, store.ErrorInvalid) {
+			response.BadRequest(w, r, err, req)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.Created(w, r, res)
+}
+
+// @Summary List experiments
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Success 200 {array} experiment.Entity
+// @Failure 500 {object} response.Object
+// @Router /experiments [get]
+// @Security BearerAuth
+func (h *ExperimentHandler) list(w http.ResponseWriter, r *http.Request) {
+	res, err := h.trackService.ListExperiments(r.Context())
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Get an experiment
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param id path string true "Experiment ID"
+// @Success 200 {object} experiment.Entity
+// @Failure 404 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /experiments/{id} [get]
+// @Security BearerAuth
+func (h *ExperimentHandler) get(w http.ResponseWriter, r *http.Request) {
+	res, err := h.trackService.GetExperiment(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, store.ErrorNotFound) {
+			response.NotFound(w, r, err)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Assign a client to a variant
+// @Description Assigns a client to the given variant, replacing its previous assignment. Without a variant the client keeps its assignment or is assigned deterministically by hashing its ID.
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param id path string true "Experiment ID"
+// @Param request body experiment.AssignRequest true "body param"
+// @Success 200 {object} experiment.Assignment
+// @Failure 400 {object} response.Object
+// @Failure 404 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /experiments/{id}/assignments [post]
+// @Security BearerAuth
+func (h *ExperimentHandler) assign(w http.ResponseWriter, r *http.Request) {
+	if !h.canWrite(w, r) {
+		return
+	}
+
+	var req experiment.AssignRequest
+	if err := render.Bind(r, &req); err != nil {
+		response.BadRequest(w, r, err, req)
+		return
+	}
+
+	res, err := h.trackService.AssignClient(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrorNotFound):
+			response.NotFound(w, r, err)
+		case errors.Is(err, store.ErrorInvalid):
+			response.BadRequest(w, r, err, req)
+		default:
+			response.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Experiment results
+// @Description Returns per variant the funnel conversion and stage durations of its clients since their assignment, and a two-proportion z-test of the conversion of every variant against the control
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param id path string true "Experiment ID"
+// @Param alpha query number false "Significance level (default 0.05)"
+// @Success 200 {object} experiment.Results
+// @Failure 400 {object} response.Object
+// @Failure 404 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /experiments/{id}/results [get]
+// @Security BearerAuth
+func (h *ExperimentHandler) results(w http.ResponseWriter, r *http.Request) {
+	alpha := experiment.DefaultAlpha
+	if a := r.URL.Query().Get("alpha"); a != "" {
+		aFloat, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			response.BadRequest(w, r, errors.New("alpha: must be a number"), a)
+			return
+		}
+		alpha = aFloat
+	}
+
+	res, err := h.trackService.ExperimentResults(r.Context(), chi.URLParam(r, "id"), alpha)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrorNotFound):
+			response.NotFound(w, r, err)
+		case errors.Is(err, store.ErrorInvalid):
+			response.BadRequest(w, r, err, nil)
+		default:
+			response.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// canWrite rejects managers, who have read-only access to experiments
+func (h *ExperimentHandler) canWrite(w http.ResponseWriter, r *http.Request) bool {
+	claims, _ := middleware.GetUserFromContext(r.Context())
+	if claims.Role == user.RoleManager {
+		response.Forbidden(w, r, errors.New("managers have read-only access"))
+		return false
+	}
+	return true
+}
@@ -1,15 +1,25 @@
 package http
 
 import (
+	"TrackMe/internal/domain/annotation"
+	"TrackMe/internal/domain/job"
 	"TrackMe/internal/domain/metric"
+	"TrackMe/internal/domain/user"
 	"TrackMe/internal/service/track"
 	"TrackMe/pkg/jwt"
+	"TrackMe/pkg/server/middleware"
 	"TrackMe/pkg/server/response"
 	"TrackMe/pkg/store"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
 )
 
 type MetricHandler struct {
@@ -34,40 +44,131 @@ func (h *MetricHandler) Routes() chi.Router {
 	// r.Use(middleware.RequireAdminOrManager())
 
 	r.Get("/", h.list)
-	r.Get("/calculate", h.triggerCalculateAllMetrics)
+	r.Get("/compare", h.compare)
+
+	// Backfills replace stored metrics and are started by admins only
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(h.tokenManager))
+		r.Use(middleware.RequireSuperUserOrAdmin())
+		r.Post("/backfill", h.backfill)
+	})
+
+	r.Get("/runs", h.listRuns)
+	r.Get("/alerts", h.listAlerts)
+
+	// Goals are managed by admins only
+	r.Route("/goals", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(h.tokenManager))
+		r.Use(middleware.RequireSuperUserOrAdmin())
+
+		r.Get("/", h.listGoals)
+		r.Post("/", h.createGoal)
+		r.Delete("/{id}", h.deleteGoal)
+	})
+
+	// Annotations require authentication, managers can only read them
+	r.Route("/annotations", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(h.tokenManager))
+
+		r.Get("/", h.listAnnotations)
+		r.Post("/", h.createAnnotation)
+		r.Get("/{id}", h.getAnnotation)
+		r.Put("/{id}", h.updateAnnotation)
+		r.Delete("/{id}", h.deleteAnnotation)
+	})
+
+	r.Route("/jobs", func(r chi.Router) {
+		r.Get("/", h.listJobs)
+
+		// Calculation jobs are started by admins only
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.AuthMiddleware(h.tokenManager))
+			r.Use(middleware.RequireSuperUserOrAdmin())
+			r.Post("/", h.startJob)
+		})
+
+		r.Get("/{id}", h.getJob)
+	})
 
 	return r
 }
 
 // @Summary Get metrics with filtering
+// @Description Returns a time series ordered by created_at. The metadata keys stage, percentile, mode, sla_hours, reason, source, channel, currency, model, kind, name and timezone are accepted as parameters and filter by metadata (e.g. stage=registration, source=partner); any other parameter is rejected. When from or to is given, meta.annotations lists the annotations overlapping the range that apply to the requested source and channel.
 // @Tags metrics
 // @Accept json
 // @Produce json
 // @Param type query string false "Filter by metric type"
 // @Param interval query string false "Filter by time interval (day, week, month)"
+// @Param from query string false "Created at or after (YYYY-MM-DD or RFC3339)"
+// @Param to query string false "Created at or before, a date includes the whole day (YYYY-MM-DD or RFC3339)"
+// @Param latest query boolean false "Return only the most recent metric of every series"
+// @Param model query string false "Attribution model of source-conversion and channel-conversion (last_touch, first_touch, linear), default last_touch"
 // @Success 200 {array} metric.Response
 // @Failure 400 {object} response.Object
 // @Failure 500 {object} response.Object
 // @Router /metrics [get]
 // @Security BearerAuth
 func (h *MetricHandler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
 	filters := metric.Filters{
-		Type:     r.URL.Query().Get("type"),
-		Interval: r.URL.Query().Get("interval"),
+		Type:     query.Get("type"),
+		Interval: query.Get("interval"),
 	}
 
-	if interval := r.URL.Query().Get("interval"); interval != "" {
-		filters.Interval = interval
+	if v := query.Get("from"); v != "" {
+		from, err := parseTime(v, h.trackService.Location())
+		if err != nil {
+			response.BadRequest(w, r, errors.New("from: invalid date"), v)
+			return
+		}
+		filters.From = from
+	}
+
+	if v := query.Get("to"); v != "" {
+		to, err := parseTime(v, h.trackService.Location())
+		if err != nil {
+			response.BadRequest(w, r, errors.New("to: invalid date"), v)
+			return
+		}
+		// A date covers the whole day
+		if _, err = time.Parse("2006-01-02", v); err == nil {
+			to = to.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		filters.To = to
+	}
 
+	if v := query.Get("latest"); v != "" {
+		latest, err := strconv.ParseBool(v)
+		if err != nil {
+			response.BadRequest(w, r, errors.New("latest: must be true or false"), v)
+			return
+		}
+		filters.Latest = latest
 	}
 
-	if metricType := r.URL.Query().Get("type"); metricType != "" {
-		filters.Type = metricType
+	for key, values := range query {
+		switch key {
+		case "type", "interval", "from", "to", "latest":
+			continue
+		}
+		// An unknown parameter, such as a typo, would otherwise silently match nothing
+		if !slices.Contains(metric.MetadataKeys, key) {
+			response.BadRequest(w, r, fmt.Errorf("invalid parameter %s: metadata filters are %s", key, strings.Join(metric.MetadataKeys, ", ")), key)
+			return
+		}
+		if filters.Metadata == nil {
+			filters.Metadata = make(map[string]string)
+		}
+		filters.Metadata[key] = values[0]
 	}
 
 	metrics, err := h.trackService.ListMetrics(r.Context(), filters)
 	if err != nil {
 		switch {
+		case errors.Is(err, store.ErrorInvalid):
+			response.BadRequest(w, r, err, filters.Type)
 		case errors.Is(err, store.ErrorNotFound):
 			response.NotFound(w, r, err)
 		default:
@@ -76,20 +177,596 @@ func (h *MetricHandler) list(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.OK(w, r, metrics, nil)
+	var meta any
+	if !filters.From.IsZero() || !filters.To.IsZero() {
+		annotations, err := h.trackService.MetricAnnotations(r.Context(), filters)
+		if err != nil {
+			response.InternalServerError(w, r, err)
+			return
+		}
+		if annotations != nil {
+			meta = map[string]interface{}{"annotations": annotations}
+		}
+	}
+
+	response.OK(w, r, metrics, meta)
+}
+
+// @Summary Compare a metric with previous periods
+// @Description Returns the current value, previous values, absolute and relative deltas and the trend for every metadata breakdown of the metric
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param type query string true "Metric type"
+// @Param interval query string true "Time interval (day, week, month)"
+// @Param periods query integer false "Number of periods including the current one (default 2)"
+// @Success 200 {array} metric.Comparison
+// @Failure 400 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/compare [get]
+// @Security BearerAuth
+func (h *MetricHandler) compare(w http.ResponseWriter, r *http.Request) {
+	metricType := r.URL.Query().Get("type")
+	interval := r.URL.Query().Get("interval")
+
+	periods := 2
+	if p := r.URL.Query().Get("periods"); p != "" {
+		pInt, err := strconv.Atoi(p)
+		if err != nil {
+			response.BadRequest(w, r, errors.New("periods: must be a number"), p)
+			return
+		}
+		periods = pInt
+	}
+
+	res, err := h.trackService.CompareMetrics(r.Context(), metricType, interval, periods)
+	if err != nil {
+		if errors.Is(err, store.ErrorInvalid) {
+			response.BadRequest(w, r, err, nil)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, map[string]interface{}{
+		"total": len(res),
+	})
 }
 
-func (h *MetricHandler) triggerCalculateAllMetrics(w http.ResponseWriter, r *http.Request) {
+// @Summary Recalculate metrics for past periods
+// @Description Starts a job recomputing every day, week or month period between from and to, replacing previously stored rows. The job has one step per period; poll it for progress.
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param from query string true "Start date (YYYY-MM-DD or RFC3339)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD or RFC3339)"
+// @Param interval query string true "Time interval (day, week, month)"
+// @Success 202 {object} job.Response
+// @Failure 400 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 409 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/backfill [post]
+// @Security BearerAuth
+func (h *MetricHandler) backfill(w http.ResponseWriter, r *http.Request) {
 	interval := r.URL.Query().Get("interval")
 	if interval == "" {
 		response.BadRequest(w, r, errors.New("interval parameter is required"), interval)
 		return
 	}
-	if err := h.trackService.CalculateAllMetrics(r.Context(), interval); err != nil {
+
+	from, err := parseTime(r.URL.Query().Get("from"), h.trackService.Location())
+	if err != nil {
+		response.BadRequest(w, r, errors.New("from: invalid date"), r.URL.Query().Get("from"))
+		return
+	}
+
+	to, err := parseTime(r.URL.Query().Get("to"), h.trackService.Location())
+	if err != nil {
+		response.BadRequest(w, r, errors.New("to: invalid date"), r.URL.Query().Get("to"))
+		return
+	}
+
+	req := job.BackfillRequest{Interval: interval, From: from, To: to}
+	if err = req.Bind(r); err != nil {
+		response.BadRequest(w, r, err, req)
+		return
+	}
+
+	res, err := h.trackService.BackfillMetrics(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrorAlreadyExists):
+			response.Conflict(w, r, errors.New("a job for this interval is already running"))
+		case errors.Is(err, store.ErrorInvalid):
+			response.BadRequest(w, r, err, req)
+		default:
+			response.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	response.Accepted(w, r, res)
+}
+
+// @Summary Start a metric calculation job
+// @Description Calculates every metric of the interval in the background; poll the returned job for progress
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param request body job.Request true "body param"
+// @Success 202 {object} job.Response
+// @Failure 400 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 409 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/jobs [post]
+// @Security BearerAuth
+func (h *MetricHandler) startJob(w http.ResponseWriter, r *http.Request) {
+	var req job.Request
+	if err := render.Bind(r, &req); err != nil {
+		response.BadRequest(w, r, err, req)
+		return
+	}
+
+	res, err := h.trackService.StartMetricJob(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrorAlreadyExists):
+			response.Conflict(w, r, errors.New("a job for this interval is already running"))
+		default:
+			response.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	response.Accepted(w, r, res)
+}
+
+// @Summary Get a metric calculation job
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param id path string true "path param"
+// @Success 200 {object} job.Response
+// @Failure 404 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/jobs/{id} [get]
+// @Security BearerAuth
+func (h *MetricHandler) getJob(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	res, err := h.trackService.GetMetricJob(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrorNotFound):
+			response.NotFound(w, r, err)
+		default:
+			response.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary List metric calculation jobs
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param limit query integer false "Pagination limit (default 50)"
+// @Param offset query integer false "Pagination offset (default 0)"
+// @Success 200 {array} job.Response
+// @Failure 500 {object} response.Object
+// @Router /metrics/jobs [get]
+// @Security BearerAuth
+func (h *MetricHandler) listJobs(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if lInt, err := strconv.Atoi(l); err == nil && lInt > 0 {
+			limit = lInt
+		}
+	}
+
+	offset := 0
+	if o := r.URL.Query().Get("offset"); o != "" {
+		if oInt, err := strconv.Atoi(o); err == nil && oInt >= 0 {
+			offset = oInt
+		}
+	}
+
+	res, total, err := h.trackService.ListMetricJobs(r.Context(), limit, offset)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, map[string]interface{}{
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// @Summary List metric calculation runs
+// @Description Returns the per-metric result summary of past calculation runs, newest first
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param interval query string false "Filter by time interval (day, week, month)"
+// @Param limit query integer false "Pagination limit (default 50)"
+// @Param offset query integer false "Pagination offset (default 0)"
+// @Success 200 {array} metric.Run
+// @Failure 500 {object} response.Object
+// @Router /metrics/runs [get]
+// @Security BearerAuth
+func (h *MetricHandler) listRuns(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if lInt, err := strconv.Atoi(l); err == nil && lInt > 0 {
+			limit = lInt
+		}
+	}
+
+	offset := 0
+	if o := r.URL.Query().Get("offset"); o != "" {
+		if oInt, err := strconv.Atoi(o); err == nil && oInt >= 0 {
+			offset = oInt
+		}
+	}
+
+	res, total, err := h.trackService.ListMetricRuns(r.Context(), r.URL.Query().Get("interval"), limit, offset)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, map[string]interface{}{
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// @Summary List metric anomaly alerts
+// @Description Returns the anomalies detected after metric calculations, newest first
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param type query string false "Filter by metric type"
+// @Param limit query integer false "Pagination limit (default 50)"
+// @Param offset query integer false "Pagination offset (default 0)"
+// @Success 200 {array} alert.Entity
+// @Failure 500 {object} response.Object
+// @Router /metrics/alerts [get]
+// @Security BearerAuth
+func (h *MetricHandler) listAlerts(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if lInt, err := strconv.Atoi(l); err == nil && lInt > 0 {
+			limit = lInt
+		}
+	}
+
+	offset := 0
+	if o := r.URL.Query().Get("offset"); o != "" {
+		if oInt, err := strconv.Atoi(o); err == nil && oInt >= 0 {
+			offset = oInt
+		}
+	}
+
+	res, total, err := h.trackService.ListAlerts(r.Context(), r.URL.Query().Get("type"), limit, offset)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, map[string]interface{}{
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// @Summary Set a metric goal
+// @Description Sets a target for a metric type per day, week or month, optionally for one series (e.g. metadata stage=payment). Metrics the goal applies to carry its status in the goal field.
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param request body metric.GoalRequest true "body param"
+// @Success 201 {object} metric.Goal
+// @Failure 400 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/goals [post]
+// @Security BearerAuth
+func (h *MetricHandler) createGoal(w http.ResponseWriter, r *http.Request) {
+	var req metric.GoalRequest
+	if err := render.Bind(r, &req); err != nil {
+		response.BadRequest(w, r, err, req)
+		return
+	}
+
+	res, err := h.trackService.CreateGoal(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, store.ErrorInvalid) {
+			response.BadRequest(w, r, err, req)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.Created(w, r, res)
+}
+
+// @Summary List metric goals with progress
+// @Description Returns every goal with, per series, the attainment and end-of-period projection of the current period and the goal misses among the preceding closed periods
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param periods query integer false "Number of periods including the current one (default 6)"
+// @Success 200 {array} metric.GoalProgress
+// @Failure 400 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/goals [get]
+// @Security BearerAuth
+func (h *MetricHandler) listGoals(w http.ResponseWriter, r *http.Request) {
+	periods := 6
+	if p := r.URL.Query().Get("periods"); p != "" {
+		pInt, err := strconv.Atoi(p)
+		if err != nil {
+			response.BadRequest(w, r, errors.New("invalid periods"), nil)
+			return
+		}
+		periods = pInt
+	}
+
+	res, err := h.trackService.ListGoals(r.Context(), periods)
+	if err != nil {
+		if errors.Is(err, store.ErrorInvalid) {
+			response.BadRequest(w, r, err, nil)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Delete a metric goal
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param id path string true "Goal ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/goals/{id} [delete]
+// @Security BearerAuth
+func (h *MetricHandler) deleteGoal(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := h.trackService.DeleteGoal(r.Context(), id); err != nil {
+		if errors.Is(err, store.ErrorNotFound) {
+			response.NotFound(w, r, err)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Create a metric annotation
+// @Description Marks a time range, e.g. a campaign or a release, optionally scoped to a source or channel. A missing ends_at makes the annotation a moment.
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param request body annotation.Request true "body param"
+// @Success 201 {object} annotation.Entity
+// @Failure 400 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/annotations [post]
+// @Security BearerAuth
+func (h *MetricHandler) createAnnotation(w http.ResponseWriter, r *http.Request) {
+	if !h.canWrite(w, r) {
+		return
+	}
+
+	var req annotation.Request
+	if err := render.Bind(r, &req); err != nil {
+		response.BadRequest(w, r, err, req)
+		return
+	}
+
+	// The author is the authenticated user, never a value from the payload
+	claims, _ := middleware.GetUserFromContext(r.Context())
+
+	res, err := h.trackService.CreateAnnotation(r.Context(), req, claims.UserID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.Created(w, r, res)
+}
+
+// @Summary List metric annotations
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param from query string false "Overlapping the range starting at (YYYY-MM-DD or RFC3339)"
+// @Param to query string false "Overlapping the range ending at, a date includes the whole day (YYYY-MM-DD or RFC3339)"
+// @Param tag query string false "Filter by tag"
+// @Param source query string false "Scoped to the source or unscoped"
+// @Param channel query string false "Scoped to the channel or unscoped"
+// @Success 200 {array} annotation.Entity
+// @Failure 400 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/annotations [get]
+// @Security BearerAuth
+func (h *MetricHandler) listAnnotations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filters := annotation.Filters{
+		Tag:     strings.ToLower(query.Get("tag")),
+		Source:  query.Get("source"),
+		Channel: query.Get("channel"),
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, err := parseTime(v, h.trackService.Location())
+		if err != nil {
+			response.BadRequest(w, r, errors.New("from: invalid date"), v)
+			return
+		}
+		filters.From = from
+	}
+
+	if v := query.Get("to"); v != "" {
+		to, err := parseTime(v, h.trackService.Location())
+		if err != nil {
+			response.BadRequest(w, r, errors.New("to: invalid date"), v)
+			return
+		}
+		// A date covers the whole day
+		if _, err = time.Parse("2006-01-02", v); err == nil {
+			to = to.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		filters.To = to
+	}
+
+	res, err := h.trackService.ListAnnotations(r.Context(), filters)
+	if err != nil {
+		if errors.Is(err, store.ErrorInvalid) {
+			response.BadRequest(w, r, err, nil)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Get a metric annotation
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param id path string true "Annotation ID"
+// @Success 200 {object} annotation.Entity
+// @Failure 404 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/annotations/{id} [get]
+// @Security BearerAuth
+func (h *MetricHandler) getAnnotation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	res, err := h.trackService.GetAnnotation(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrorNotFound) {
+			response.NotFound(w, r, err)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Replace a metric annotation
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param id path string true "Annotation ID"
+// @Param request body annotation.Request true "body param"
+// @Success 200 {object} annotation.Entity
+// @Failure 400 {object} response.Object
+// @Failure 404 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/annotations/{id} [put]
+// @Security BearerAuth
+func (h *MetricHandler) updateAnnotation(w http.ResponseWriter, r *http.Request) {
+	if !h.canWrite(w, r) {
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	var req annotation.Request
+	if err := render.Bind(r, &req); err != nil {
+		response.BadRequest(w, r, err, req)
+		return
+	}
+
+	res, err := h.trackService.UpdateAnnotation(r.Context(), id, req)
+	if err != nil {
+		if errors.Is(err, store.ErrorNotFound) {
+			response.NotFound(w, r, err)
+			return
+		}
 		response.InternalServerError(w, r, err)
 		return
 	}
-	response.OK(w, r, map[string]string{"message": "triggerred success"}, nil)
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Delete a metric annotation
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param id path string true "Annotation ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /metrics/annotations/{id} [delete]
+// @Security BearerAuth
+func (h *MetricHandler) deleteAnnotation(w http.ResponseWriter, r *http.Request) {
+	if !h.canWrite(w, r) {
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	if err := h.trackService.DeleteAnnotation(r.Context(), id); err != nil {
+		if errors.Is(err, store.ErrorNotFound) {
+			response.NotFound(w, r, err)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseTime parses a date (YYYY-MM-DD), taken as midnight in loc, or an RFC3339 timestamp
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// canWrite rejects managers, who have read-only access to annotations
+func (h *MetricHandler) canWrite(w http.ResponseWriter, r *http.Request) bool {
+	claims, _ := middleware.GetUserFromContext(r.Context())
+	if claims.Role == user.RoleManager {
+		response.Forbidden(w, r, errors.New("managers have read-only access"))
+		return false
+	}
+	return true
 }
 
 //// @Summary Get metrics in Prometheus format
@@ -0,0 +1,308 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"TrackMe/internal/domain/rule"
+	"TrackMe/internal/domain/user"
+	"TrackMe/internal/service/track"
+	"TrackMe/pkg/jwt"
+	"TrackMe/pkg/server/middleware"
+	"TrackMe/pkg/server/response"
+	"TrackMe/pkg/store"
+)
+
+type RuleHandler struct {
+	trackService track.RuleTrackService
+	tokenManager *jwt.TokenManager
+}
+
+func NewRuleHandler(s track.RuleTrackService, tm *jwt.TokenManager) *RuleHandler {
+	return &RuleHandler{
+		trackService: s,
+		tokenManager: tm,
+	}
+}
+
+func (h *RuleHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	// All routes require authentication, managers can only read
+	r.Use(middleware.AuthMiddleware(h.tokenManager))
+
+	r.Get("/", h.list)
+	r.Post("/", h.create)
+	r.Get("/executions", h.executions)
+
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.get)
+		r.Put("/", h.update)
+		r.Delete("/", h.delete)
+		r.Post("/dry-run", h.dryRun)
+	})
+
+	return r
+}
+
+// @Summary Create a rule
+// @Description Creates a "when X then Y" automation rule. When the trigger (created, updated, transitioned or schedule) fires for a client matching the condition, e.g. `stage == "payment_waiting" && app == "not_installed"`, the actions (transition, deactivate, tag, assign, notify, webhook) are taken in order.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param request body rule.Request true "body param"
+// @Success 201 {object} rule.Entity
+// @Failure 400 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /rules [post]
+// @Security BearerAuth
+func (h *RuleHandler) create(w http.ResponseWriter, r *http.Request) {
+	if !h.canWrite(w, r) {
+		return
+	}
+
+	var req rule.Request
+	if err := render.Bind(r, &req); err != nil {
+		response.BadRequest(w, r, err, req)
+		return
+	}
+
+	res, err := h.trackService.CreateRule(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, store.ErrorInvalid) {
+			response.BadRequest(w, r, err, req)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.Created(w, r, res)
+}
+
+// @Summary List rules
+// @Description Returns the rules in evaluation order, oldest first
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param trigger query string false "Filter by trigger" Enums(created, updated, transitioned, schedule)
+// @Param enabled query boolean false "Filter by enabled state"
+// @Success 200 {array} rule.Entity
+// @Failure 500 {object} response.Object
+// @Router /rules [get]
+// @Security BearerAuth
+func (h *RuleHandler) list(w http.ResponseWriter, r *http.Request) {
+	filters := rule.Filters{Trigger: r.URL.Query().Get("trigger")}
+	if enabled := r.URL.Query().Get("enabled"); enabled != "" {
+		value, err := strconv.ParseBool(enabled)
+		if err != nil {
+			response.BadRequest(w, r, errors.New("invalid enabled: must be true or false"), enabled)
+			return
+		}
+		filters.Enabled = &value
+	}
+
+	res, err := h.trackService.ListRules(r.Context(), filters)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	if res == nil {
+		res = []rule.Entity{}
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Get a rule
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} rule.Entity
+// @Failure 404 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /rules/{id} [get]
+// @Security BearerAuth
+func (h *RuleHandler) get(w http.ResponseWriter, r *http.Request) {
+	res, err := h.trackService.GetRule(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, store.ErrorNotFound) {
+			response.NotFound(w, r, err)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Replace a rule
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body rule.Request true "body param"
+// @Success 200 {object} rule.Entity
+// @Failure 400 {object} response.Object
+// @Failure 403 {object} response.Object
+// @Failure 404 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /rules/{id} [put]
+// @Security BearerAuth
+func (h *RuleHandler) update(w http.ResponseWriter, r *http.Request) {
+	if !h.canWrite(w, r) {
+		return
+	}
+
+	var req rule.Request
+	if err := render.Bind(r, &req); err != nil {
+		response.BadRequest(w, r, err, req)
+		return
+	}
+
+	res, err := h.trackService.UpdateRule(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrorNotFound):
+			response.NotFound(w, r, err)
+		case errors.Is(err, store.ErrorInvalid):
+			response.BadRequest(w, r, err, req)
+		default:
+			response.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary Delete a rule
+// @Description Deletes a rule and its execution log
+// @Tags rules
+// @Param id path string true "Rule ID"
+// @Success 204
+// @Failure 403 {object} response.Object
+// @Failure 404 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /rules/{id} [delete]
+// @Security BearerAuth
+func (h *RuleHandler) delete(w http.ResponseWriter, r *http.Request) {
+	if !h.canWrite(w, r) {
+		return
+	}
+
+	if err := h.trackService.DeleteRule(r.Context(), chi.URLParam(r, "id")); err != nil {
+		if errors.Is(err, store.ErrorNotFound) {
+			response.NotFound(w, r, err)
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Dry-run a rule
+// @Description Evaluates a rule, enabled or not, against a client or, without client_id, every client. Returns the executions of the matching clients with their actions planned but not taken; dry runs are recorded in the execution log.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body rule.DryRunRequest false "body param"
+// @Success 200 {array} rule.Execution
+// @Failure 400 {object} response.Object
+// @Failure 404 {object} response.Object
+// @Failure 500 {object} response.Object
+// @Router /rules/{id}/dry-run [post]
+// @Security BearerAuth
+func (h *RuleHandler) dryRun(w http.ResponseWriter, r *http.Request) {
+	var req rule.DryRunRequest
+	if r.ContentLength != 0 {
+		if err := render.Bind(r, &req); err != nil {
+			response.BadRequest(w, r, err, req)
+			return
+		}
+	}
+
+	res, err := h.trackService.DryRunRule(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrorNotFound):
+			response.NotFound(w, r, err)
+		case errors.Is(err, store.ErrorInvalid):
+			response.BadRequest(w, r, err, req)
+		default:
+			response.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	response.OK(w, r, res, nil)
+}
+
+// @Summary List rule executions
+// @Description Returns the execution log, newest first: every rule that matched a client with the outcome of each action
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param rule_id query string false "Filter by rule ID"
+// @Param client_id query string false "Filter by client ID"
+// @Param limit query integer false "Pagination limit (default 50)"
+// @Param offset query integer false "Pagination offset (default 0)"
+// @Success 200 {array} rule.Execution
+// @Failure 500 {object} response.Object
+// @Router /rules/executions [get]
+// @Security BearerAuth
+func (h *RuleHandler) executions(w http.ResponseWriter, r *http.Request) {
+	filters := rule.ExecutionFilters{
+		RuleID:   r.URL.Query().Get("rule_id"),
+		ClientID: r.URL.Query().Get("client_id"),
+	}
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if lInt, err := strconv.Atoi(l); err == nil && lInt > 0 {
+			limit = lInt
+		}
+	}
+
+	offset := 0
+	if o := r.URL.Query().Get("offset"); o != "" {
+		if oInt, err := strconv.Atoi(o); err == nil && oInt >= 0 {
+			offset = oInt
+		}
+	}
+
+	res, total, err := h.trackService.ListRuleExecutions(r.Context(), filters, limit, offset)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	if res == nil {
+		res = []rule.Execution{}
+	}
+
+	response.OK(w, r, res, map[string]interface{}{
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// canWrite rejects managers, who have read-only access to rules
+func (h *RuleHandler) canWrite(w http.ResponseWriter, r *http.Request) bool {
+	claims, _ := middleware.GetUserFromContext(r.Context())
+	if claims.Role == user.RoleManager {
+		response.Forbidden(w, r, errors.New("managers have read-only access"))
+		return false
+	}
+	return true
+}
@@ -62,6 +62,14 @@ var (
 		},
 	)
 
+	MetricCalculationFailuresTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "trackme_metric_calculation_failures_total",
+			Help: "Total number of metric calculations that failed after all retries",
+		},
+		[]string{"type"},
+	)
+
 	// Cache metrics
 	CacheHitsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -137,6 +145,11 @@ func RecordLoginAttempt(success bool) {
 	LoginAttemptsTotal.WithLabelValues(status).Inc()
 }
 
+// RecordMetricCalculationFailure records a metric calculation that failed after all retries
+func RecordMetricCalculationFailure(metricType string) {
+	MetricCalculationFailuresTotal.WithLabelValues(metricType).Inc()
+}
+
 // RecordCacheAccess records cache hit or miss
 func RecordCacheAccess(cacheName string, hit bool) {
 	if hit {
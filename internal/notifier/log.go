@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"TrackMe/internal/domain/alert"
+	"TrackMe/pkg/log"
+	"context"
+)
+
+// Log writes alerts to the application log, useful in development and tests.
+type Log struct{}
+
+// NewLog creates a new Log notifier.
+func NewLog() *Log {
+	return &Log{}
+}
+
+func (n *Log) Name() string { return "log" }
+
+// Notify logs the alert
+func (n *Log) Notify(ctx context.Context, data alert.Entity) error {
+	logger := log.LoggerFromContext(ctx)
+	logger.Warn().
+		Str("component", "notifier.log").
+		Str("type", data.Type).
+		Str("interval", data.Interval).
+		Interface("metadata", data.Metadata).
+		Float64("value", data.Value).
+		Float64("baseline", data.Baseline).
+		Str("method", data.Method).
+		Float64("score", data.Score).
+		Msg(subject(data))
+	return nil
+}
+
+// Send logs a rule message
+func (n *Log) Send(ctx context.Context, subject, text string) error {
+	logger := log.LoggerFromContext(ctx)
+	logger.Info().
+		Str("component", "notifier.log").
+		Str("text", text).
+		Msg(subject)
+	return nil
+}
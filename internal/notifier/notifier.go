@@ -0,0 +1,55 @@
+// Package notifier delivers metric anomaly alerts and rule messages to external channels.
+package notifier
+
+import (
+	"TrackMe/internal/domain/alert"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// subject summarizes an alert in one line
+func subject(data alert.Entity) string {
+	direction := "rose"
+	if data.Value < data.Baseline {
+		direction = "dropped"
+	}
+
+	res := fmt.Sprintf("%s %s", data.Type, direction)
+	if data.Interval != "" {
+		res = fmt.Sprintf("%s (%s)", res, data.Interval)
+	}
+	if labels := labels(data.Metadata); labels != "" {
+		res += " " + labels
+	}
+	return res
+}
+
+// body describes an alert in a few lines of plain text
+func body(data alert.Entity) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", subject(data))
+	fmt.Fprintf(&b, "Value: %g\n", data.Value)
+	fmt.Fprintf(&b, "Baseline: %g (stddev %g)\n", data.Baseline, data.StdDev)
+	switch data.Method {
+	case alert.MethodPercent:
+		fmt.Fprintf(&b, "Change: %+.1f%% (threshold %g%%)\n", data.Score, data.Threshold)
+	default:
+		fmt.Fprintf(&b, "Z-score: %+.2f (threshold %g)\n", data.Score, data.Threshold)
+	}
+	fmt.Fprintf(&b, "Metric time: %s\n", data.MetricCreatedAt.Format("2006-01-02 15:04:05 MST"))
+	return b.String()
+}
+
+// labels renders metadata as sorted key=value pairs, without the timezone
+func labels(metadata map[string]string) string {
+	var pairs []string
+	for k, v := range metadata {
+		if k == "timezone" {
+			continue
+		}
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
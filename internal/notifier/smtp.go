@@ -0,0 +1,86 @@
+package notifier
+
+import (
+	"TrackMe/internal/domain/alert"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTP emails alerts as plain text through an SMTP server.
+type SMTP struct {
+	addr     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// NewSMTP creates a new SMTP notifier. addr is host:port; credentials are optional.
+func NewSMTP(addr, username, password, from string, to []string) *SMTP {
+	return &SMTP{
+		addr:     addr,
+		username: username,
+		password: password,
+		from:     from,
+		to:       to,
+	}
+}
+
+func (n *SMTP) Name() string { return "smtp" }
+
+// Notify emails the alert to every recipient
+func (n *SMTP) Notify(ctx context.Context, data alert.Entity) error {
+	if err := n.send(ctx, subject(data), body(data)); err != nil {
+		return fmt.Errorf("failed to send alert email: %w", err)
+	}
+	return nil
+}
+
+// Send emails a rule message to every recipient
+func (n *SMTP) Send(ctx context.Context, subject, text string) error {
+	if err := n.send(ctx, subject, text); err != nil {
+		return fmt.Errorf("failed to send rule email: %w", err)
+	}
+	return nil
+}
+
+// send emails a plain text message to every recipient
+func (n *SMTP) send(ctx context.Context, subject, text string) error {
+	var auth smtp.Auth
+	if n.username != "" {
+		host, _, err := net.SplitHostPort(n.addr)
+		if err != nil {
+			return fmt.Errorf("invalid smtp address: %w", err)
+		}
+		auth = smtp.PlainAuth("", n.username, n.password, host)
+	}
+
+	// Line breaks in the subject would start new headers
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+
+	msg := strings.Join([]string{
+		"From: " + n.from,
+		"To: " + strings.Join(n.to, ", "),
+		"Subject: " + mime.QEncoding.Encode("UTF-8", "[TrackMe] "+subject),
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		text,
+	}, "\r\n")
+
+	// net/smtp does not take a context, the send is abandoned when it expires
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(n.addr, auth, n.from, n.to, []byte(msg))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
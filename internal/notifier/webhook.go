@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"TrackMe/internal/domain/alert"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Webhook posts alerts as JSON to a URL: the alert fields plus a one-line "text" summary, which
+// chat webhooks such as Slack display as is.
+type Webhook struct {
+	client *http.Client
+	url    string
+}
+
+// NewWebhook creates a new Webhook notifier.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		client: &http.Client{Timeout: 10 * time.Second},
+		url:    url,
+	}
+}
+
+func (n *Webhook) Name() string { return "webhook" }
+
+// Notify posts the alert
+func (n *Webhook) Notify(ctx context.Context, data alert.Entity) error {
+	payload := struct {
+		alert.Entity
+		Text string `json:"text"`
+	}{data, subject(data)}
+
+	if err := post(ctx, n.client, n.url, payload); err != nil {
+		return fmt.Errorf("failed to post alert: %w", err)
+	}
+	return nil
+}
+
+// Send posts a rule message as {"subject": ..., "text": ...}
+func (n *Webhook) Send(ctx context.Context, subject, text string) error {
+	payload := struct {
+		Subject string `json:"subject"`
+		Text    string `json:"text"`
+	}{subject, subject + "\n" + text}
+
+	if err := post(ctx, n.client, n.url, payload); err != nil {
+		return fmt.Errorf("failed to post rule message: %w", err)
+	}
+	return nil
+}
+
+// Caller posts the payloads of rule webhook actions to their URLs.
+type Caller struct {
+	client *http.Client
+}
+
+// NewCaller creates a new Caller.
+func NewCaller() *Caller {
+	return &Caller{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Post posts the payload as JSON to the URL
+func (c *Caller) Post(ctx context.Context, url string, payload any) error {
+	return post(ctx, c.client, url, payload)
+}
+
+// post posts the payload as JSON and requires a non-error status
+func post(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package clickhouse
+
+import (
+	"TrackMe/internal/domain/client"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// ClientActivityRepository stores client deactivation and reactivation events in ClickHouse
+type ClientActivityRepository struct {
+	conn clickhouse.Conn
+}
+
+func NewClientActivityRepository(conn clickhouse.Conn) *ClientActivityRepository {
+	return &ClientActivityRepository{conn: conn}
+}
+
+// Add inserts an activity event
+func (r *ClientActivityRepository) Add(ctx context.Context, data client.Activity) error {
+	if data.CreatedAt.IsZero() {
+		data.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO client_activity (client_id, event, stage, reason, comment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	return r.conn.Exec(ctx, query,
+		data.ClientID,
+		data.Event,
+		data.Stage,
+		data.Reason,
+		data.Comment,
+		data.CreatedAt,
+	)
+}
+
+// List retrieves the activity events up to asOf, ordered by client and time
+func (r *ClientActivityRepository) List(ctx context.Context, asOf time.Time) ([]client.Activity, error) {
+	query := `
+		SELECT client_id, event, stage, reason, comment, created_at
+		FROM client_activity
+		WHERE created_at <= ?
+		ORDER BY client_id, created_at
+	`
+	rows, err := r.conn.Query(ctx, query, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows driver.Rows) {
+		cerr := rows.Close()
+		if cerr != nil {
+			log.Printf("rows.Close error: %v", cerr)
+		}
+	}(rows)
+
+	var res []client.Activity
+	for rows.Next() {
+		var data client.Activity
+		if err = rows.Scan(&data.ClientID, &data.Event, &data.Stage, &data.Reason, &data.Comment, &data.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		res = append(res, data)
+	}
+
+	return res, rows.Err()
+}
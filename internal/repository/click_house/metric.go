@@ -51,23 +51,36 @@ func (r *MetricRepository) Add(ctx context.Context, data metric.Entity) (string,
 	return data.ID, nil
 }
 
-// List retrieves metrics with optional filters
+// List retrieves metrics with optional filters as a time series ordered by creation time.
+// Every metric is returned in its latest version; with filters.Latest only the most recent
+// metric of every series is returned, newest first.
 func (r *MetricRepository) List(ctx context.Context, filters metric.Filters) ([]metric.Entity, error) {
+	var args []interface{}
+	var conditions []string
+
+	// Keys missing from a row take their default, so old and new rows of a series share the key
+	metadata := "argMax(metadata, created_at)"
+	if len(filters.MetadataDefaults) > 0 {
+		pairs := make([]string, 0, len(filters.MetadataDefaults))
+		for key, value := range filters.MetadataDefaults {
+			pairs = append(pairs, "?, ?")
+			args = append(args, key, value)
+		}
+		metadata = "mapUpdate(map(" + strings.Join(pairs, ", ") + "), " + metadata + ")"
+	}
+
 	query := `
   SELECT
    id,
    type,
    argMax(value, created_at) as value,
    argMax(interval, created_at) as metric_interval,
-   argMax(toDate(created_at), created_at) as created_date,
-   argMax(metadata, created_at) as metadata
+   max(created_at) as metric_created_at,
+   ` + metadata + ` as metric_metadata
   FROM metrics
   WHERE 1=1
  `
 
-	var args []interface{}
-	var conditions []string
-
 	if filters.Type != "" {
 		conditions = append(conditions, "type = ?")
 		args = append(args, filters.Type)
@@ -78,12 +91,40 @@ func (r *MetricRepository) List(ctx context.Context, filters metric.Filters) ([]
 		args = append(args, filters.Interval)
 	}
 
+	if !filters.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filters.From)
+	}
+
+	if !filters.To.IsZero() {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, filters.To)
+	}
+
+	for key, value := range filters.Metadata {
+		conditions = append(conditions, "metadata[?] = ?")
+		args = append(args, key, value)
+	}
+
+	for key, value := range filters.MetadataDefaults {
+		conditions = append(conditions, "(metadata[?] = ? OR NOT mapContains(metadata, ?))")
+		args = append(args, key, value, key)
+	}
+
 	if len(conditions) > 0 {
 		query += " AND " + strings.Join(conditions, " AND ")
 	}
 
 	query += " GROUP BY id, type"
-	query += " ORDER BY created_date DESC"
+
+	if filters.Latest {
+		query += " ORDER BY metric_created_at DESC"
+		// Maps are not comparable, a series is keyed by its sorted key=value pairs
+		query += " LIMIT 1 BY type, metric_interval," +
+			" arrayStringConcat(arraySort(arrayMap((k, v) -> concat(k, '=', v), mapKeys(metric_metadata), mapValues(metric_metadata))), ',')"
+	} else {
+		query += " ORDER BY metric_created_at ASC"
+	}
 
 	rows, err := r.conn.Query(ctx, query, args...)
 	if err != nil {
@@ -102,7 +143,7 @@ func (r *MetricRepository) List(ctx context.Context, filters metric.Filters) ([]
 		var metricType string
 		var value float64
 		var interval string
-		var createdDate time.Time
+		var createdAt time.Time
 		var metadataMap map[string]string
 
 		if err := rows.Scan(
@@ -110,7 +151,7 @@ func (r *MetricRepository) List(ctx context.Context, filters metric.Filters) ([]
 			&metricType,
 			&value,
 			&interval,
-			&createdDate,
+			&createdAt,
 			&metadataMap,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
@@ -120,7 +161,7 @@ func (r *MetricRepository) List(ctx context.Context, filters metric.Filters) ([]
 		m.Type = &t
 		m.Value = &value
 		m.Interval = &interval
-		m.CreatedAt = &createdDate
+		m.CreatedAt = &createdAt
 		m.Metadata = metadataMap
 
 		metrics = append(metrics, m)
@@ -138,3 +179,16 @@ func (r *MetricRepository) Update(ctx context.Context, id string, data metric.En
 	}
 	return data, nil
 }
+
+// DeleteRange removes metrics of the given types and interval created within [from, to)
+func (r *MetricRepository) DeleteRange(ctx context.Context, types []string, interval string, from, to time.Time) error {
+	if len(types) == 0 {
+		return nil
+	}
+
+	query := `
+		DELETE FROM metrics
+		WHERE has(?, type) AND interval = ? AND created_at >= ? AND created_at < ?
+	`
+	return r.conn.Exec(ctx, query, types, interval, from, to)
+}
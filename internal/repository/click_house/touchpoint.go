@@ -0,0 +1,86 @@
+package clickhouse
+
+import (
+	"TrackMe/internal/domain/touchpoint"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// TouchpointRepository stores client touchpoint events in ClickHouse
+type TouchpointRepository struct {
+	conn clickhouse.Conn
+}
+
+func NewTouchpointRepository(conn clickhouse.Conn) *TouchpointRepository {
+	return &TouchpointRepository{conn: conn}
+}
+
+// Add inserts a touchpoint event
+func (r *TouchpointRepository) Add(ctx context.Context, data touchpoint.Entity) error {
+	if data.CreatedAt.IsZero() {
+		data.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO client_touchpoints (client_id, source, channel, origin, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	return r.conn.Exec(ctx, query,
+		data.ClientID,
+		data.Source,
+		data.Channel,
+		data.Origin,
+		data.CreatedAt,
+	)
+}
+
+// List retrieves the touchpoints up to asOf, ordered by client and time
+func (r *TouchpointRepository) List(ctx context.Context, asOf time.Time) ([]touchpoint.Entity, error) {
+	query := `
+		SELECT client_id, source, channel, origin, created_at
+		FROM client_touchpoints
+		WHERE created_at <= ?
+		ORDER BY client_id, created_at
+	`
+	return r.query(ctx, query, asOf)
+}
+
+// ListByClient retrieves the touchpoints of a client, oldest first
+func (r *TouchpointRepository) ListByClient(ctx context.Context, clientID string) ([]touchpoint.Entity, error) {
+	query := `
+		SELECT client_id, source, channel, origin, created_at
+		FROM client_touchpoints
+		WHERE client_id = ?
+		ORDER BY created_at
+	`
+	return r.query(ctx, query, clientID)
+}
+
+func (r *TouchpointRepository) query(ctx context.Context, query string, args ...interface{}) ([]touchpoint.Entity, error) {
+	rows, err := r.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows driver.Rows) {
+		cerr := rows.Close()
+		if cerr != nil {
+			log.Printf("rows.Close error: %v", cerr)
+		}
+	}(rows)
+
+	var res []touchpoint.Entity
+	for rows.Next() {
+		var data touchpoint.Entity
+		if err = rows.Scan(&data.ClientID, &data.Source, &data.Channel, &data.Origin, &data.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		res = append(res, data)
+	}
+
+	return res, rows.Err()
+}
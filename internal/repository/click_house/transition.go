@@ -0,0 +1,292 @@
+package clickhouse
+
+import (
+	"TrackMe/internal/domain/transition"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// TransitionRepository stores stage transition events in ClickHouse
+type TransitionRepository struct {
+	conn clickhouse.Conn
+}
+
+func NewTransitionRepository(conn clickhouse.Conn) *TransitionRepository {
+	return &TransitionRepository{conn: conn}
+}
+
+// Add inserts a transition event
+func (r *TransitionRepository) Add(ctx context.Context, data transition.Entity) error {
+	if data.CreatedAt.IsZero() {
+		data.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO stage_transitions (client_id, from_stage, to_stage, source, channel, reason, comment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	return r.conn.Exec(ctx, query,
+		data.ClientID,
+		data.FromStage,
+		data.ToStage,
+		data.Source,
+		data.Channel,
+		data.Reason,
+		data.Comment,
+		data.CreatedAt,
+	)
+}
+
+// List retrieves the transitions between from and to, ordered by client and time
+func (r *TransitionRepository) List(ctx context.Context, from, to time.Time) ([]transition.Entity, error) {
+	query := `
+		SELECT client_id, from_stage, to_stage, source, channel, reason, comment, created_at
+		FROM stage_transitions
+		WHERE 1=1
+	`
+	var args []interface{}
+	if !from.IsZero() {
+		query += ` AND created_at >= ?`
+		args = append(args, from)
+	}
+	if !to.IsZero() {
+		query += ` AND created_at <= ?`
+		args = append(args, to)
+	}
+	query += ` ORDER BY client_id, created_at`
+
+	rows, err := r.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows driver.Rows) {
+		cerr := rows.Close()
+		if cerr != nil {
+			log.Printf("rows.Close error: %v", cerr)
+		}
+	}(rows)
+
+	var res []transition.Entity
+	for rows.Next() {
+		var data transition.Entity
+		if err = rows.Scan(&data.ClientID, &data.FromStage, &data.ToStage, &data.Source, &data.Channel, &data.Reason, &data.Comment, &data.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		res = append(res, data)
+	}
+
+	return res, rows.Err()
+}
+
+// Clients returns the distinct clients with transitions recorded up to before
+func (r *TransitionRepository) Clients(ctx context.Context, before time.Time) ([]string, error) {
+	rows, err := r.conn.Query(ctx, `SELECT DISTINCT client_id FROM stage_transitions WHERE created_at <= ?`, before)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows driver.Rows) {
+		cerr := rows.Close()
+		if cerr != nil {
+			log.Printf("rows.Close error: %v", cerr)
+		}
+	}(rows)
+
+	var res []string
+	for rows.Next() {
+		var clientID string
+		if err = rows.Scan(&clientID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		res = append(res, clientID)
+	}
+
+	return res, rows.Err()
+}
+
+// StageDurationQuantiles computes per-stage dwell time quantiles in seconds. The stay in a
+// stage ends with the client's next transition, or at asOf when there is none. The extra
+// durations are passed as arrays of stages and seconds and joined to the stays.
+func (r *TransitionRepository) StageDurationQuantiles(ctx context.Context, asOf time.Time, levels []float64, extra map[string][]float64) (map[string][]float64, error) {
+	var (
+		extraStages  = []string{}
+		extraSeconds = []float64{}
+	)
+	for stage, seconds := range extra {
+		for _, v := range seconds {
+			extraStages = append(extraStages, stage)
+			extraSeconds = append(extraSeconds, v)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT stage, quantiles(%s)(seconds)
+		FROM (
+			SELECT stage, toFloat64(dateDiff('second', entered_at, left_at)) AS seconds
+			FROM (
+				SELECT
+					to_stage AS stage,
+					created_at AS entered_at,
+					leadInFrame(created_at, 1, toDateTime(?)) OVER (
+						PARTITION BY client_id ORDER BY created_at
+						ROWS BETWEEN CURRENT ROW AND 1 FOLLOWING
+					) AS left_at
+				FROM stage_transitions
+				WHERE created_at <= ?
+			)
+			UNION ALL
+			SELECT tupleElement(pair, 1) AS stage, tupleElement(pair, 2) AS seconds
+			FROM (SELECT arrayJoin(arrayZip(CAST(? AS Array(String)), CAST(? AS Array(Float64)))) AS pair)
+		)
+		WHERE stage != ''
+		GROUP BY stage
+	`, quantileLevels(levels))
+
+	rows, err := r.conn.Query(ctx, query, asOf, asOf, extraStages, extraSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows driver.Rows) {
+		cerr := rows.Close()
+		if cerr != nil {
+			log.Printf("rows.Close error: %v", cerr)
+		}
+	}(rows)
+
+	res := make(map[string][]float64)
+	for rows.Next() {
+		var (
+			stage  string
+			values []float64
+		)
+		if err = rows.Scan(&stage, &values); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		res[stage] = values
+	}
+
+	return res, rows.Err()
+}
+
+// JourneyDurationQuantiles computes quantiles in seconds of the time clients took from their
+// first transition to reaching lastStage, together with the extra journeys
+func (r *TransitionRepository) JourneyDurationQuantiles(ctx context.Context, lastStage string, asOf time.Time, levels []float64, extra []float64) ([]float64, int, error) {
+	if extra == nil {
+		extra = []float64{}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT quantiles(%s)(seconds), count()
+		FROM (
+			SELECT toFloat64(dateDiff('second', min(created_at), minIf(created_at, to_stage = ?))) AS seconds
+			FROM stage_transitions
+			WHERE created_at <= ?
+			GROUP BY client_id
+			HAVING countIf(to_stage = ?) > 0
+			UNION ALL
+			SELECT arrayJoin(CAST(? AS Array(Float64))) AS seconds
+		)
+	`, quantileLevels(levels))
+
+	var (
+		values []float64
+		count  uint64
+	)
+	if err := r.conn.QueryRow(ctx, query, lastStage, asOf, lastStage, extra).Scan(&values, &count); err != nil {
+		return nil, 0, err
+	}
+
+	return values, int(count), nil
+}
+
+// Stays lists the stays in a stage, ending each with the client's next transition or asOf
+func (r *TransitionRepository) Stays(ctx context.Context, asOf time.Time) ([]transition.Stay, error) {
+	query := `
+		SELECT client_id, stage, entered_at, left_at
+		FROM (
+			SELECT
+				client_id,
+				to_stage AS stage,
+				created_at AS entered_at,
+				leadInFrame(created_at, 1, toDateTime(?)) OVER (
+					PARTITION BY client_id ORDER BY created_at
+					ROWS BETWEEN CURRENT ROW AND 1 FOLLOWING
+				) AS left_at
+			FROM stage_transitions
+			WHERE created_at <= ?
+		)
+		WHERE stage != ''
+	`
+
+	rows, err := r.conn.Query(ctx, query, asOf, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows driver.Rows) {
+		cerr := rows.Close()
+		if cerr != nil {
+			log.Printf("rows.Close error: %v", cerr)
+		}
+	}(rows)
+
+	var res []transition.Stay
+	for rows.Next() {
+		var stay transition.Stay
+		if err = rows.Scan(&stay.ClientID, &stay.Stage, &stay.EnteredAt, &stay.LeftAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		res = append(res, stay)
+	}
+
+	return res, rows.Err()
+}
+
+// Journeys lists, per client that reached lastStage, its first transition and the first time it
+// entered lastStage
+func (r *TransitionRepository) Journeys(ctx context.Context, lastStage string, asOf time.Time) ([]transition.Journey, error) {
+	query := `
+		SELECT client_id, min(created_at) AS started_at, minIf(created_at, to_stage = ?) AS finished_at
+		FROM stage_transitions
+		WHERE created_at <= ?
+		GROUP BY client_id
+		HAVING countIf(to_stage = ?) > 0
+	`
+
+	rows, err := r.conn.Query(ctx, query, lastStage, asOf, lastStage)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows driver.Rows) {
+		cerr := rows.Close()
+		if cerr != nil {
+			log.Printf("rows.Close error: %v", cerr)
+		}
+	}(rows)
+
+	var res []transition.Journey
+	for rows.Next() {
+		var journey transition.Journey
+		if err = rows.Scan(&journey.ClientID, &journey.StartedAt, &journey.FinishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		res = append(res, journey)
+	}
+
+	return res, rows.Err()
+}
+
+// quantileLevels renders quantile levels as parameters of a ClickHouse quantiles function
+func quantileLevels(levels []float64) string {
+	var res string
+	for i, level := range levels {
+		if i > 0 {
+			res += ", "
+		}
+		res += fmt.Sprintf("%g", level)
+	}
+	return res
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"TrackMe/internal/domain/currency"
+)
+
+// CurrencyRepository fetches exchange rates from an external HTTP API.
+//
+// The API is called as GET {url}?base=KZT&date=2006-01-02 with basic auth and must respond with
+// {"base": "KZT", "date": "2006-01-02", "rates": {"USD": 0.0021, ...}}.
+type CurrencyRepository struct {
+	client   *http.Client
+	url      string
+	login    string
+	password string
+}
+
+// NewCurrencyRepository creates a new CurrencyRepository.
+func NewCurrencyRepository(url, login, password string) *CurrencyRepository {
+	return &CurrencyRepository{
+		client:   &http.Client{Timeout: 10 * time.Second},
+		url:      url,
+		login:    login,
+		password: password,
+	}
+}
+
+// Rates retrieves exchange rates quoted against base that are valid on date.
+func (r *CurrencyRepository) Rates(ctx context.Context, base string, date time.Time) (currency.Rates, error) {
+	endpoint, err := url.Parse(r.url)
+	if err != nil {
+		return currency.Rates{}, fmt.Errorf("invalid currency url: %w", err)
+	}
+
+	query := endpoint.Query()
+	query.Set("base", strings.ToUpper(base))
+	query.Set("date", date.Format("2006-01-02"))
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return currency.Rates{}, err
+	}
+	if r.login != "" {
+		req.SetBasicAuth(r.login, r.password)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return currency.Rates{}, fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+	defer func(body io.ReadCloser) {
+		cerr := body.Close()
+		if cerr != nil {
+			log.Printf("body.Close error: %v", cerr)
+		}
+	}(res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		return currency.Rates{}, fmt.Errorf("failed to fetch exchange rates: unexpected status %d", res.StatusCode)
+	}
+
+	var payload struct {
+		Base  string             `json:"base"`
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return currency.Rates{}, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+
+	values := make(map[string]float64, len(payload.Rates))
+	for code, rate := range payload.Rates {
+		values[strings.ToUpper(code)] = rate
+	}
+
+	rates := currency.Rates{
+		Base:   strings.ToUpper(payload.Base),
+		Date:   date,
+		Values: values,
+	}
+	if rates.Base == "" {
+		rates.Base = strings.ToUpper(base)
+	}
+
+	// Some providers ignore the requested base and always quote against their own
+	return rates.Rebase(base)
+}
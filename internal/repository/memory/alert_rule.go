@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"TrackMe/internal/domain/alert"
+)
+
+// AlertRuleRepository serves anomaly detection rules loaded from a yaml file
+type AlertRuleRepository struct {
+	rules []alert.Rule
+}
+
+// NewAlertRuleRepository creates a new AlertRuleRepository with rules loaded from path. A missing
+// file means no rules; an invalid one is an error.
+func NewAlertRuleRepository(path string) (*AlertRuleRepository, error) {
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &AlertRuleRepository{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var config struct {
+		Rules []alert.Rule `yaml:"rules"`
+	}
+
+	if err = yaml.Unmarshal(file, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for i := range config.Rules {
+		if err = config.Rules[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return &AlertRuleRepository{rules: config.Rules}, nil
+}
+
+// List returns every loaded rule
+func (r *AlertRuleRepository) List(ctx context.Context) ([]alert.Rule, error) {
+	return r.rules, nil
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"TrackMe/internal/domain/currency"
+)
+
+// CurrencyRepository serves exchange rates loaded from a static yaml file for offline use
+type CurrencyRepository struct {
+	rates currency.Rates
+}
+
+// NewCurrencyRepository creates a new CurrencyRepository with rates loaded from path
+func NewCurrencyRepository(path string) (*CurrencyRepository, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var config struct {
+		Base  string             `yaml:"base"`
+		Rates map[string]float64 `yaml:"rates"`
+	}
+
+	if err = yaml.Unmarshal(file, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	values := make(map[string]float64, len(config.Rates))
+	for code, rate := range config.Rates {
+		values[strings.ToUpper(code)] = rate
+	}
+
+	return &CurrencyRepository{
+		rates: currency.Rates{
+			Base:   strings.ToUpper(config.Base),
+			Values: values,
+		},
+	}, nil
+}
+
+// Rates returns the static rates quoted against base; the date only stamps the result
+func (r *CurrencyRepository) Rates(ctx context.Context, base string, date time.Time) (currency.Rates, error) {
+	rates, err := r.rates.Rebase(base)
+	if err != nil {
+		return currency.Rates{}, fmt.Errorf("base currency %s: %w", base, err)
+	}
+	rates.Date = date
+
+	return rates, nil
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"TrackMe/internal/domain/metric"
+)
+
+// MetricDefinitionRepository serves declarative metric definitions loaded from a yaml file
+type MetricDefinitionRepository struct {
+	definitions []metric.Definition
+}
+
+// NewMetricDefinitionRepository creates a new MetricDefinitionRepository with definitions loaded
+// from path. A missing file means no definitions; an invalid one is an error.
+func NewMetricDefinitionRepository(path string) (*MetricDefinitionRepository, error) {
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &MetricDefinitionRepository{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var config struct {
+		Metrics []metric.Definition `yaml:"metrics"`
+	}
+
+	if err = yaml.Unmarshal(file, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(config.Metrics))
+	for _, d := range config.Metrics {
+		if err = d.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if seen[d.ID] {
+			return nil, fmt.Errorf("%s: metric %s is defined twice", path, d.ID)
+		}
+		seen[d.ID] = true
+	}
+
+	return &MetricDefinitionRepository{definitions: config.Metrics}, nil
+}
+
+// List returns every loaded definition
+func (r *MetricDefinitionRepository) List(ctx context.Context) ([]metric.Definition, error) {
+	return r.definitions, nil
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"TrackMe/internal/domain/reason"
+)
+
+// ReasonRepository serves the reason taxonomy loaded from a yaml file
+type ReasonRepository struct {
+	taxonomy reason.Taxonomy
+}
+
+// NewReasonRepository creates a new ReasonRepository with the taxonomy loaded from path. A missing
+// file means an empty taxonomy; an invalid one is an error.
+func NewReasonRepository(path string) (*ReasonRepository, error) {
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &ReasonRepository{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var taxonomy reason.Taxonomy
+	if err = yaml.Unmarshal(file, &taxonomy); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err = taxonomy.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &ReasonRepository{taxonomy: taxonomy}, nil
+}
+
+// Taxonomy returns the loaded taxonomy
+func (r *ReasonRepository) Taxonomy(ctx context.Context) (reason.Taxonomy, error) {
+	return r.taxonomy, nil
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"TrackMe/internal/domain/score"
+)
+
+// ScoringRepository serves the lead scoring model loaded from a yaml file
+type ScoringRepository struct {
+	model *score.Model
+}
+
+// NewScoringRepository creates a new ScoringRepository with the model loaded from path. A missing
+// file or a model without rules disables scoring; an invalid one is an error.
+func NewScoringRepository(path string) (*ScoringRepository, error) {
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &ScoringRepository{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var model score.Model
+	if err = yaml.Unmarshal(file, &model); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err = model.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if len(model.Rules) == 0 {
+		return &ScoringRepository{}, nil
+	}
+	return &ScoringRepository{model: &model}, nil
+}
+
+// Model returns the loaded model
+func (r *ScoringRepository) Model(ctx context.Context) (*score.Model, error) {
+	return r.model, nil
+}
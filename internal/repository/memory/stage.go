@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"sync"
 
 	"gopkg.in/yaml.v2"
@@ -38,6 +39,8 @@ func NewStageRepository() *StageRepository {
 			Name        string   `yaml:"name"`
 			Order       int      `yaml:"order"`
 			Transitions []string `yaml:"transitions"`
+			SLAHours    *float64 `yaml:"sla_hours"`
+			SLABusiness *float64 `yaml:"sla_business_hours"`
 		} `yaml:"stages"`
 	}
 
@@ -53,6 +56,8 @@ func NewStageRepository() *StageRepository {
 			Name:               &s.Name,
 			Order:              &s.Order,
 			AllowedTransitions: s.Transitions,
+			SLAHours:           s.SLAHours,
+			SLABusinessHours:   s.SLABusiness,
 		}
 		repo.db.Store(s.ID, stageEntity)
 	}
@@ -60,7 +65,7 @@ func NewStageRepository() *StageRepository {
 	return repo
 }
 
-// List retrieves all stages from the in-memory database
+// List retrieves all stages from the in-memory database, ordered by their order
 func (r *StageRepository) List(ctx context.Context) ([]stage.Entity, error) {
 	var stages []stage.Entity
 
@@ -73,6 +78,10 @@ func (r *StageRepository) List(ctx context.Context) ([]stage.Entity, error) {
 		return true
 	})
 
+	sort.Slice(stages, func(i, j int) bool {
+		return *stages[i].Order < *stages[j].Order
+	})
+
 	return stages, nil
 }
 
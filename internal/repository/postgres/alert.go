@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"TrackMe/internal/domain/alert"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AlertRepository handles persistence of metric anomaly alerts in PostgreSQL.
+type AlertRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAlertRepository creates a new AlertRepository.
+func NewAlertRepository(db *pgxpool.Pool) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+// Add inserts an alert into the database.
+func (r *AlertRepository) Add(ctx context.Context, data alert.Entity) (string, error) {
+	if data.ID == "" {
+		data.ID = uuid.NewString()
+	}
+
+	metadata, err := json.Marshal(data.Metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	query := `INSERT INTO alerts (id, metric_id, metric_type, metric_interval, metadata, value, baseline, stddev,
+			method, score, threshold, metric_created_at, detected_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err = r.db.Exec(ctx, query,
+		data.ID,
+		data.MetricID,
+		data.Type,
+		data.Interval,
+		metadata,
+		data.Value,
+		data.Baseline,
+		data.StdDev,
+		data.Method,
+		data.Score,
+		data.Threshold,
+		data.MetricCreatedAt,
+		data.DetectedAt,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	return data.ID, nil
+}
+
+// List retrieves alerts with pagination, newest first, optionally filtered by metric type.
+func (r *AlertRepository) List(ctx context.Context, metricType string, limit, offset int) ([]alert.Entity, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var total int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM alerts WHERE $1 = '' OR metric_type = $1`, metricType).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count alerts: %w", err)
+	}
+
+	query := `SELECT id, metric_id, metric_type, metric_interval, metadata, value, baseline, stddev,
+			method, score, threshold, metric_created_at, detected_at
+		FROM alerts
+		WHERE $1 = '' OR metric_type = $1
+		ORDER BY detected_at DESC LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, metricType, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []alert.Entity
+	for rows.Next() {
+		var (
+			data        alert.Entity
+			metadataRaw []byte
+		)
+		err = rows.Scan(
+			&data.ID,
+			&data.MetricID,
+			&data.Type,
+			&data.Interval,
+			&metadataRaw,
+			&data.Value,
+			&data.Baseline,
+			&data.StdDev,
+			&data.Method,
+			&data.Score,
+			&data.Threshold,
+			&data.MetricCreatedAt,
+			&data.DetectedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		if metadataRaw != nil {
+			if err = json.Unmarshal(metadataRaw, &data.Metadata); err != nil {
+				return nil, 0, fmt.Errorf("failed to unmarshal metadata: %w", err)
+			}
+		}
+		alerts = append(alerts, data)
+	}
+
+	return alerts, total, rows.Err()
+}
@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"TrackMe/internal/domain/annotation"
+	"TrackMe/pkg/store"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AnnotationRepository handles persistence of metric annotations in PostgreSQL.
+type AnnotationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAnnotationRepository creates a new AnnotationRepository.
+func NewAnnotationRepository(db *pgxpool.Pool) *AnnotationRepository {
+	return &AnnotationRepository{db: db}
+}
+
+const annotationColumns = `id, title, description, tags, starts_at, ends_at, source, channel, created_by, created_at, updated_at`
+
+// scanAnnotation scans an annotation row selected with annotationColumns.
+func scanAnnotation(row pgx.Row) (annotation.Entity, error) {
+	var data annotation.Entity
+
+	err := row.Scan(
+		&data.ID,
+		&data.Title,
+		&data.Description,
+		&data.Tags,
+		&data.StartsAt,
+		&data.EndsAt,
+		&data.Source,
+		&data.Channel,
+		&data.CreatedBy,
+		&data.CreatedAt,
+		&data.UpdatedAt,
+	)
+	if err != nil {
+		return annotation.Entity{}, err
+	}
+	if data.Tags == nil {
+		data.Tags = []string{}
+	}
+
+	return data, nil
+}
+
+// Create inserts an annotation into the database.
+func (r *AnnotationRepository) Create(ctx context.Context, data annotation.Entity) (annotation.Entity, error) {
+	if data.ID == "" {
+		data.ID = uuid.NewString()
+	}
+	if data.Tags == nil {
+		data.Tags = []string{}
+	}
+
+	query := `INSERT INTO metric_annotations (id, title, description, tags, starts_at, ends_at, source, channel, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + annotationColumns
+
+	result, err := scanAnnotation(r.db.QueryRow(ctx, query,
+		data.ID,
+		data.Title,
+		data.Description,
+		data.Tags,
+		data.StartsAt,
+		data.EndsAt,
+		data.Source,
+		data.Channel,
+		data.CreatedBy,
+		data.CreatedAt,
+		data.UpdatedAt,
+	))
+	if err != nil {
+		return annotation.Entity{}, fmt.Errorf("failed to create annotation: %w", err)
+	}
+
+	return result, nil
+}
+
+// Get retrieves an annotation by ID.
+func (r *AnnotationRepository) Get(ctx context.Context, id string) (annotation.Entity, error) {
+	query := `SELECT ` + annotationColumns + ` FROM metric_annotations WHERE id = $1`
+
+	data, err := scanAnnotation(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return annotation.Entity{}, store.ErrorNotFound
+		}
+		return annotation.Entity{}, fmt.Errorf("failed to get annotation: %w", err)
+	}
+
+	return data, nil
+}
+
+// List retrieves the annotations matching the filters, ordered by start.
+func (r *AnnotationRepository) List(ctx context.Context, filters annotation.Filters) ([]annotation.Entity, error) {
+	query := `SELECT ` + annotationColumns + ` FROM metric_annotations WHERE 1=1`
+
+	args := []interface{}{}
+	argCount := 1
+
+	if !filters.From.IsZero() {
+		query += fmt.Sprintf(" AND ends_at >= $%d", argCount)
+		args = append(args, filters.From)
+		argCount++
+	}
+
+	if !filters.To.IsZero() {
+		query += fmt.Sprintf(" AND starts_at <= $%d", argCount)
+		args = append(args, filters.To)
+		argCount++
+	}
+
+	if filters.Tag != "" {
+		query += fmt.Sprintf(" AND $%d = ANY(tags)", argCount)
+		args = append(args, filters.Tag)
+		argCount++
+	}
+
+	// Unscoped annotations apply to every source and channel
+	if filters.Source != "" {
+		query += fmt.Sprintf(" AND (source = '' OR source = $%d)", argCount)
+		args = append(args, filters.Source)
+		argCount++
+	}
+
+	if filters.Channel != "" {
+		query += fmt.Sprintf(" AND (channel = '' OR channel = $%d)", argCount)
+		args = append(args, filters.Channel)
+		argCount++
+	}
+
+	query += " ORDER BY starts_at, created_at"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query annotations: %w", err)
+	}
+	defer rows.Close()
+
+	annotations := []annotation.Entity{}
+	for rows.Next() {
+		data, err := scanAnnotation(rows)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, data)
+	}
+
+	return annotations, rows.Err()
+}
+
+// Update replaces an annotation by ID, keeping its author and creation time.
+func (r *AnnotationRepository) Update(ctx context.Context, id string, data annotation.Entity) (annotation.Entity, error) {
+	if data.Tags == nil {
+		data.Tags = []string{}
+	}
+
+	query := `UPDATE metric_annotations SET
+		title = $1, description = $2, tags = $3, starts_at = $4, ends_at = $5, source = $6, channel = $7, updated_at = $8
+		WHERE id = $9
+		RETURNING ` + annotationColumns
+
+	result, err := scanAnnotation(r.db.QueryRow(ctx, query,
+		data.Title,
+		data.Description,
+		data.Tags,
+		data.StartsAt,
+		data.EndsAt,
+		data.Source,
+		data.Channel,
+		data.UpdatedAt,
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return annotation.Entity{}, store.ErrorNotFound
+		}
+		return annotation.Entity{}, fmt.Errorf("failed to update annotation: %w", err)
+	}
+
+	return result, nil
+}
+
+// Delete removes an annotation by ID.
+func (r *AnnotationRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.db.Exec(ctx, `DELETE FROM metric_annotations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete annotation: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return store.ErrorNotFound
+	}
+	return nil
+}
@@ -30,9 +30,25 @@ func NewClientRepository(db *pgxpool.Pool) *ClientRepository {
 	return &ClientRepository{db: db}
 }
 
-// List retrieves all clients from the database.
+// List retrieves a page of the clients matching the filters and their total number. A
+// non-positive limit defaults to 10.
 func (r *ClientRepository) List(ctx context.Context, filters client.Filters, limit, offset int) ([]client.Entity, int, error) {
-	query := `SELECT id, name, email, current_stage, last_updated, is_active, source, channel, app, last_login, contracts FROM clients WHERE 1=1`
+	if limit <= 0 {
+		limit = 10
+	}
+	return r.list(ctx, filters, limit, offset)
+}
+
+// ListAll retrieves every client matching the filters.
+func (r *ClientRepository) ListAll(ctx context.Context, filters client.Filters) ([]client.Entity, error) {
+	clients, _, err := r.list(ctx, filters, 0, 0)
+	return clients, err
+}
+
+// list retrieves the clients matching the filters and their total number, every one of them
+// when limit is 0.
+func (r *ClientRepository) list(ctx context.Context, filters client.Filters, limit, offset int) ([]client.Entity, int, error) {
+	query := `SELECT id, name, email, registration_date, current_stage, last_updated, is_active, source, channel, app, last_login, contracts, score, tags, assignee_id FROM clients WHERE 1=1`
 	countQuery := `SELECT COUNT(*) FROM clients WHERE 1=1`
 
 	args := []interface{}{}
@@ -95,6 +111,27 @@ func (r *ClientRepository) List(ctx context.Context, filters client.Filters, lim
 		argCount++
 	}
 
+	if filters.MinScore != nil {
+		query += fmt.Sprintf(" AND score >= $%d", argCount)
+		countQuery += fmt.Sprintf(" AND score >= $%d", argCount)
+		args = append(args, *filters.MinScore)
+		argCount++
+	}
+
+	if filters.Tag != "" {
+		query += fmt.Sprintf(" AND $%d = ANY(tags)", argCount)
+		countQuery += fmt.Sprintf(" AND $%d = ANY(tags)", argCount)
+		args = append(args, filters.Tag)
+		argCount++
+	}
+
+	if filters.AssigneeID != "" {
+		query += fmt.Sprintf(" AND assignee_id::text = $%d", argCount)
+		countQuery += fmt.Sprintf(" AND assignee_id::text = $%d", argCount)
+		args = append(args, filters.AssigneeID)
+		argCount++
+	}
+
 	// Get total count
 	var total int
 	row := r.db.QueryRow(ctx, countQuery, args...)
@@ -102,12 +139,15 @@ func (r *ClientRepository) List(ctx context.Context, filters client.Filters, lim
 		return nil, 0, err
 	}
 
-	if limit <= 0 {
-		limit = 10
+	if filters.Sort == client.SortScore {
+		query += " ORDER BY score DESC NULLS LAST, last_updated DESC"
+	} else {
+		query += " ORDER BY last_updated DESC"
+	}
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1)
+		args = append(args, limit, offset)
 	}
-
-	query += fmt.Sprintf(" ORDER BY last_updated DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
-	args = append(args, limit, offset)
 
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
@@ -122,6 +162,7 @@ func (r *ClientRepository) List(ctx context.Context, filters client.Filters, lim
 			&temp.ID,
 			&temp.Name,
 			&temp.Email,
+			&temp.RegistrationDate,
 			&temp.CurrentStage,
 			&temp.LastUpdated,
 			&temp.IsActive,
@@ -130,6 +171,9 @@ func (r *ClientRepository) List(ctx context.Context, filters client.Filters, lim
 			&temp.App,
 			&temp.LastLogin,
 			&temp.ContractsRaw,
+			&temp.Score,
+			&temp.Tags,
+			&temp.AssigneeID,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -163,6 +207,9 @@ func (r *ClientRepository) Create(ctx context.Context, data client.Entity) (clie
 		active := true
 		data.IsActive = &active
 	}
+	if data.Tags == nil {
+		data.Tags = []string{}
+	}
 
 	var contractsJSON []byte
 	if data.Contracts != nil {
@@ -175,9 +222,9 @@ func (r *ClientRepository) Create(ctx context.Context, data client.Entity) (clie
 
 	query := `INSERT INTO clients (
 		id, name, email, current_stage, is_active, 
-		source, channel, app, last_login, contracts
-	) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
-	  RETURNING id, last_updated`
+		source, channel, app, last_login, contracts, registration_date, score, tags, assignee_id
+	) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,COALESCE($11, NOW()),$12,$13,$14)
+	  RETURNING id, last_updated, registration_date`
 
 	args := []interface{}{
 		data.ID,
@@ -190,16 +237,21 @@ func (r *ClientRepository) Create(ctx context.Context, data client.Entity) (clie
 		data.App,
 		data.LastLogin,
 		contractsJSON,
+		data.RegistrationDate,
+		data.Score,
+		data.Tags,
+		data.AssigneeID,
 	}
 
 	var temp ClientEntity
-	err := r.db.QueryRow(ctx, query, args...).Scan(&temp.ID, &temp.LastUpdated)
+	err := r.db.QueryRow(ctx, query, args...).Scan(&temp.ID, &temp.LastUpdated, &temp.RegistrationDate)
 	if err != nil {
 		return client.Entity{}, fmt.Errorf("failed to insert client: %w", err)
 	}
 
 	data.ID = temp.ID
 	data.LastUpdated = temp.LastUpdated
+	data.RegistrationDate = temp.RegistrationDate
 	return data, nil
 }
 
@@ -247,8 +299,8 @@ func (r *ClientRepository) Count(ctx context.Context, filter bson.M) (int64, err
 
 // Get retrieves a client by ID.
 func (r *ClientRepository) Get(ctx context.Context, id string) (client.Entity, error) {
-	query := `SELECT id, name, email, current_stage, last_updated,
-		is_active, source, channel, app, last_login, contracts 
+	query := `SELECT id, name, email, registration_date, current_stage, last_updated,
+		is_active, source, channel, app, last_login, contracts, score, tags, assignee_id
 		FROM clients WHERE id=$1`
 
 	var temp ClientEntity
@@ -256,6 +308,7 @@ func (r *ClientRepository) Get(ctx context.Context, id string) (client.Entity, e
 		&temp.ID,
 		&temp.Name,
 		&temp.Email,
+		&temp.RegistrationDate,
 		&temp.CurrentStage,
 		&temp.LastUpdated,
 		&temp.IsActive,
@@ -264,6 +317,9 @@ func (r *ClientRepository) Get(ctx context.Context, id string) (client.Entity, e
 		&temp.App,
 		&temp.LastLogin,
 		&temp.ContractsRaw,
+		&temp.Score,
+		&temp.Tags,
+		&temp.AssigneeID,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -285,8 +341,8 @@ func (r *ClientRepository) Get(ctx context.Context, id string) (client.Entity, e
 
 // GetByEmail retrieves a client by email.
 func (r *ClientRepository) GetByEmail(ctx context.Context, email string) (client.Entity, error) {
-	query := `SELECT id, name, email, current_stage, last_updated,
-		is_active, source, channel, app, last_login, contracts 
+	query := `SELECT id, name, email, registration_date, current_stage, last_updated,
+		is_active, source, channel, app, last_login, contracts, score, tags, assignee_id
 		FROM clients WHERE email=$1`
 
 	var temp ClientEntity
@@ -294,6 +350,7 @@ func (r *ClientRepository) GetByEmail(ctx context.Context, email string) (client
 		&temp.ID,
 		&temp.Name,
 		&temp.Email,
+		&temp.RegistrationDate,
 		&temp.CurrentStage,
 		&temp.LastUpdated,
 		&temp.IsActive,
@@ -302,6 +359,9 @@ func (r *ClientRepository) GetByEmail(ctx context.Context, email string) (client
 		&temp.App,
 		&temp.LastLogin,
 		&temp.ContractsRaw,
+		&temp.Score,
+		&temp.Tags,
+		&temp.AssigneeID,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -325,11 +385,15 @@ func (r *ClientRepository) GetByEmail(ctx context.Context, email string) (client
 func (r *ClientRepository) Update(ctx context.Context, id string, data client.Entity) (client.Entity, error) {
 	query := `UPDATE clients SET 
 		name=$1, email=$2, current_stage=$3, is_active=$4,
-		source=$5, channel=$6, app=$7, last_login=$8, contracts=$9,
-		last_updated=NOW()
-		WHERE id=$10
-		RETURNING id, name, email, current_stage, last_updated,
-		is_active, source, channel, app, last_login, contracts`
+		source=$5, channel=$6, app=$7, last_login=$8, contracts=$9, score=$10,
+		tags=$11, assignee_id=$12, last_updated=NOW()
+		WHERE id=$13
+		RETURNING id, name, email, registration_date, current_stage, last_updated,
+		is_active, source, channel, app, last_login, contracts, score, tags, assignee_id`
+
+	if data.Tags == nil {
+		data.Tags = []string{}
+	}
 
 	var contractsJSON []byte
 	if data.Contracts != nil {
@@ -350,6 +414,9 @@ func (r *ClientRepository) Update(ctx context.Context, id string, data client.En
 		data.App,
 		data.LastLogin,
 		contractsJSON,
+		data.Score,
+		data.Tags,
+		data.AssigneeID,
 		id,
 	}
 
@@ -358,6 +425,7 @@ func (r *ClientRepository) Update(ctx context.Context, id string, data client.En
 		&temp.ID,
 		&temp.Name,
 		&temp.Email,
+		&temp.RegistrationDate,
 		&temp.CurrentStage,
 		&temp.LastUpdated,
 		&temp.IsActive,
@@ -366,6 +434,9 @@ func (r *ClientRepository) Update(ctx context.Context, id string, data client.En
 		&temp.App,
 		&temp.LastLogin,
 		&temp.ContractsRaw,
+		&temp.Score,
+		&temp.Tags,
+		&temp.AssigneeID,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -385,6 +456,49 @@ func (r *ClientRepository) Update(ctx context.Context, id string, data client.En
 	return temp.Entity, nil
 }
 
+// UpdateScore sets the score of a client without touching its last update.
+func (r *ClientRepository) UpdateScore(ctx context.Context, id string, score *int) error {
+	cmdTag, err := r.db.Exec(ctx, "UPDATE clients SET score=$1 WHERE id=$2", score, id)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return store.ErrorNotFound
+	}
+	return nil
+}
+
+// UpdateTags replaces the tags of a client without touching its last update.
+func (r *ClientRepository) UpdateTags(ctx context.Context, id string, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	cmdTag, err := r.db.Exec(ctx, "UPDATE clients SET tags=$1 WHERE id=$2", tags, id)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return store.ErrorNotFound
+	}
+	return nil
+}
+
+// Assign sets the user responsible for a client without touching its last update.
+func (r *ClientRepository) Assign(ctx context.Context, id string, userID string) error {
+	cmdTag, err := r.db.Exec(ctx, "UPDATE clients SET assignee_id=$1 WHERE id=$2", userID, id)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return store.ErrorNotFound
+	}
+	return nil
+}
+
 // Delete removes a client.
 func (r *ClientRepository) Delete(ctx context.Context, id string) error {
 	cmdTag, err := r.db.Exec(ctx, "DELETE FROM clients WHERE id=$1", id)
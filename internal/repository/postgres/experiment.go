@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"TrackMe/internal/domain/experiment"
+	"TrackMe/pkg/store"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExperimentRepository handles persistence of experiments and their assignments in PostgreSQL.
+type ExperimentRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewExperimentRepository creates a new ExperimentRepository.
+func NewExperimentRepository(db *pgxpool.Pool) *ExperimentRepository {
+	return &ExperimentRepository{db: db}
+}
+
+const (
+	experimentColumns = `id, name, description, goal_stage, variants, created_at`
+	assignmentColumns = `experiment_id, client_id, variant, method, assigned_at`
+)
+
+// scanExperiment scans an experiment row selected with experimentColumns.
+func scanExperiment(row pgx.Row) (experiment.Entity, error) {
+	var (
+		data        experiment.Entity
+		variantsRaw []byte
+	)
+
+	err := row.Scan(
+		&data.ID,
+		&data.Name,
+		&data.Description,
+		&data.GoalStage,
+		&variantsRaw,
+		&data.CreatedAt,
+	)
+	if err != nil {
+		return experiment.Entity{}, err
+	}
+
+	if variantsRaw != nil {
+		if err = json.Unmarshal(variantsRaw, &data.Variants); err != nil {
+			return experiment.Entity{}, fmt.Errorf("failed to unmarshal variants: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// scanAssignment scans an assignment row selected with assignmentColumns.
+func scanAssignment(row pgx.Row) (experiment.Assignment, error) {
+	var data experiment.Assignment
+
+	err := row.Scan(
+		&data.ExperimentID,
+		&data.ClientID,
+		&data.Variant,
+		&data.Method,
+		&data.AssignedAt,
+	)
+	return data, err
+}
+
+// Create inserts an experiment into the database.
+func (r *ExperimentRepository) Create(ctx context.Context, data experiment.Entity) (experiment.Entity, error) {
+	if data.ID == "" {
+		data.ID = uuid.NewString()
+	}
+
+	variants, err := json.Marshal(data.Variants)
+	if err != nil {
+		return experiment.Entity{}, fmt.Errorf("failed to marshal variants: %w", err)
+	}
+
+	query := `INSERT INTO experiments (id, name, description, goal_stage, variants, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + experimentColumns
+
+	result, err := scanExperiment(r.db.QueryRow(ctx, query,
+		data.ID,
+		data.Name,
+		data.Description,
+		data.GoalStage,
+		variants,
+		data.CreatedAt,
+	))
+	if err != nil {
+		return experiment.Entity{}, fmt.Errorf("failed to create experiment: %w", err)
+	}
+
+	return result, nil
+}
+
+// Get retrieves an experiment by ID.
+func (r *ExperimentRepository) Get(ctx context.Context, id string) (experiment.Entity, error) {
+	query := `SELECT ` + experimentColumns + ` FROM experiments WHERE id = $1`
+
+	data, err := scanExperiment(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return experiment.Entity{}, store.ErrorNotFound
+		}
+		return experiment.Entity{}, fmt.Errorf("failed to get experiment: %w", err)
+	}
+
+	return data, nil
+}
+
+// List retrieves every experiment, newest first.
+func (r *ExperimentRepository) List(ctx context.Context) ([]experiment.Entity, error) {
+	query := `SELECT ` + experimentColumns + ` FROM experiments ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query experiments: %w", err)
+	}
+	defer rows.Close()
+
+	experiments := []experiment.Entity{}
+	for rows.Next() {
+		data, err := scanExperiment(rows)
+		if err != nil {
+			return nil, err
+		}
+		experiments = append(experiments, data)
+	}
+
+	return experiments, rows.Err()
+}
+
+// Assign stores the assignment of a client, replacing its previous one in the experiment.
+func (r *ExperimentRepository) Assign(ctx context.Context, data experiment.Assignment) (experiment.Assignment, error) {
+	query := `INSERT INTO experiment_assignments (experiment_id, client_id, variant, method, assigned_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (experiment_id, client_id) DO UPDATE SET
+			variant = EXCLUDED.variant, method = EXCLUDED.method, assigned_at = EXCLUDED.assigned_at
+		RETURNING ` + assignmentColumns
+
+	result, err := scanAssignment(r.db.QueryRow(ctx, query,
+		data.ExperimentID,
+		data.ClientID,
+		data.Variant,
+		data.Method,
+		data.AssignedAt,
+	))
+	if err != nil {
+		return experiment.Assignment{}, fmt.Errorf("failed to assign client: %w", err)
+	}
+
+	return result, nil
+}
+
+// Assignment retrieves the assignment of a client in an experiment.
+func (r *ExperimentRepository) Assignment(ctx context.Context, experimentID, clientID string) (experiment.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM experiment_assignments WHERE experiment_id = $1 AND client_id = $2`
+
+	data, err := scanAssignment(r.db.QueryRow(ctx, query, experimentID, clientID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return experiment.Assignment{}, store.ErrorNotFound
+		}
+		return experiment.Assignment{}, fmt.Errorf("failed to get assignment: %w", err)
+	}
+
+	return data, nil
+}
+
+// Assignments retrieves every assignment of an experiment, oldest first.
+func (r *ExperimentRepository) Assignments(ctx context.Context, experimentID string) ([]experiment.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM experiment_assignments WHERE experiment_id = $1 ORDER BY assigned_at`
+
+	rows, err := r.db.Query(ctx, query, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []experiment.Assignment
+	for rows.Next() {
+		data, err := scanAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, data)
+	}
+
+	return assignments, rows.Err()
+}
@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"TrackMe/internal/domain/job"
+	"TrackMe/pkg/store"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// JobRepository handles persistence of metric calculation and backfill jobs in PostgreSQL.
+type JobRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewJobRepository creates a new JobRepository.
+func NewJobRepository(db *pgxpool.Pool) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+const jobColumns = `id, metric_interval, as_of, backfill_from, backfill_to, status, steps, error, created_at, started_at,
+	finished_at, expires_at`
+
+// scanJob scans a job row selected with jobColumns.
+func scanJob(row pgx.Row) (job.Entity, error) {
+	var (
+		data     job.Entity
+		stepsRaw []byte
+	)
+
+	err := row.Scan(
+		&data.ID,
+		&data.Interval,
+		&data.AsOf,
+		&data.From,
+		&data.To,
+		&data.Status,
+		&stepsRaw,
+		&data.Error,
+		&data.CreatedAt,
+		&data.StartedAt,
+		&data.FinishedAt,
+		&data.ExpiresAt,
+	)
+	if err != nil {
+		return job.Entity{}, err
+	}
+
+	if stepsRaw != nil {
+		if err = json.Unmarshal(stepsRaw, &data.Steps); err != nil {
+			return job.Entity{}, fmt.Errorf("failed to unmarshal steps: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// List retrieves jobs with pagination, newest first.
+func (r *JobRepository) List(ctx context.Context, limit, offset int) ([]job.Entity, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM metric_jobs`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	query := `SELECT ` + jobColumns + ` FROM metric_jobs ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []job.Entity
+	for rows.Next() {
+		data, err := scanJob(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		jobs = append(jobs, data)
+	}
+
+	return jobs, total, rows.Err()
+}
+
+// Create inserts a new job into the database.
+func (r *JobRepository) Create(ctx context.Context, data job.Entity) (job.Entity, error) {
+	if data.ID == "" {
+		data.ID = uuid.NewString()
+	}
+
+	steps, err := json.Marshal(data.Steps)
+	if err != nil {
+		return job.Entity{}, fmt.Errorf("failed to marshal steps: %w", err)
+	}
+
+	query := `INSERT INTO metric_jobs (id, metric_interval, as_of, backfill_from, backfill_to, status, steps, error,
+			created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + jobColumns
+
+	result, err := scanJob(r.db.QueryRow(ctx, query,
+		data.ID,
+		data.Interval,
+		data.AsOf,
+		data.From,
+		data.To,
+		data.Status,
+		steps,
+		data.Error,
+		data.CreatedAt,
+		data.ExpiresAt,
+	))
+	if err != nil {
+		// Detect an active job for the same interval
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return job.Entity{}, store.ErrorAlreadyExists
+		}
+		return job.Entity{}, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	return result, nil
+}
+
+// Get retrieves a job by ID.
+func (r *JobRepository) Get(ctx context.Context, id string) (job.Entity, error) {
+	query := `SELECT ` + jobColumns + ` FROM metric_jobs WHERE id = $1`
+
+	data, err := scanJob(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return job.Entity{}, store.ErrorNotFound
+		}
+		return job.Entity{}, err
+	}
+
+	return data, nil
+}
+
+// Update modifies the state of an existing job.
+func (r *JobRepository) Update(ctx context.Context, id string, data job.Entity) (job.Entity, error) {
+	steps, err := json.Marshal(data.Steps)
+	if err != nil {
+		return job.Entity{}, fmt.Errorf("failed to marshal steps: %w", err)
+	}
+
+	query := `UPDATE metric_jobs SET
+		status = $1, steps = $2, error = $3, started_at = $4, finished_at = $5
+		WHERE id = $6
+		RETURNING ` + jobColumns
+
+	result, err := scanJob(r.db.QueryRow(ctx, query,
+		data.Status,
+		steps,
+		data.Error,
+		data.StartedAt,
+		data.FinishedAt,
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return job.Entity{}, store.ErrorNotFound
+		}
+		return job.Entity{}, fmt.Errorf("failed to update job: %w", err)
+	}
+
+	return result, nil
+}
+
+// ExpireActive fails pending or running jobs of the interval that expired before the given time,
+// releasing the interval after a crash left a job behind.
+func (r *JobRepository) ExpireActive(ctx context.Context, interval string, now time.Time) error {
+	query := `UPDATE metric_jobs SET status = $1, error = $2, finished_at = NOW()
+		WHERE metric_interval = $3 AND status IN ($4, $5) AND expires_at < $6`
+
+	_, err := r.db.Exec(ctx, query,
+		job.StatusFailed,
+		"job expired before finishing",
+		interval,
+		job.StatusPending,
+		job.StatusRunning,
+		now,
+	)
+	return err
+}
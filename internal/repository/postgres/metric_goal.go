@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"TrackMe/internal/domain/metric"
+	"TrackMe/pkg/store"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MetricGoalRepository handles persistence of metric goals in PostgreSQL.
+type MetricGoalRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewMetricGoalRepository creates a new MetricGoalRepository.
+func NewMetricGoalRepository(db *pgxpool.Pool) *MetricGoalRepository {
+	return &MetricGoalRepository{db: db}
+}
+
+// Create inserts a goal into the database.
+func (r *MetricGoalRepository) Create(ctx context.Context, data metric.Goal) (metric.Goal, error) {
+	if data.ID == "" {
+		data.ID = uuid.NewString()
+	}
+	if data.Metadata == nil {
+		data.Metadata = map[string]string{}
+	}
+
+	metadata, err := json.Marshal(data.Metadata)
+	if err != nil {
+		return metric.Goal{}, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	query := `INSERT INTO metric_goals (id, metric_type, metric_interval, operator, target, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err = r.db.Exec(ctx, query,
+		data.ID,
+		data.Type,
+		data.Interval,
+		data.Operator,
+		data.Target,
+		metadata,
+		data.CreatedAt,
+	)
+	if err != nil {
+		return metric.Goal{}, fmt.Errorf("failed to create metric goal: %w", err)
+	}
+
+	return data, nil
+}
+
+// List retrieves every goal, oldest first.
+func (r *MetricGoalRepository) List(ctx context.Context) ([]metric.Goal, error) {
+	query := `SELECT id, metric_type, metric_interval, operator, target, metadata, created_at
+		FROM metric_goals ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metric goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []metric.Goal
+	for rows.Next() {
+		var (
+			data        metric.Goal
+			metadataRaw []byte
+		)
+		err = rows.Scan(
+			&data.ID,
+			&data.Type,
+			&data.Interval,
+			&data.Operator,
+			&data.Target,
+			&metadataRaw,
+			&data.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if metadataRaw != nil {
+			if err = json.Unmarshal(metadataRaw, &data.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+			}
+		}
+		goals = append(goals, data)
+	}
+
+	return goals, rows.Err()
+}
+
+// Delete removes a goal by its ID.
+func (r *MetricGoalRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.db.Exec(ctx, `DELETE FROM metric_goals WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete metric goal: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return store.ErrorNotFound
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"TrackMe/internal/domain/metric"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MetricRunRepository handles persistence of metric run summaries in PostgreSQL.
+type MetricRunRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewMetricRunRepository creates a new MetricRunRepository.
+func NewMetricRunRepository(db *pgxpool.Pool) *MetricRunRepository {
+	return &MetricRunRepository{db: db}
+}
+
+const metricRunColumns = `id, job_id, metric_interval, as_of, status, results, started_at, finished_at`
+
+// scanMetricRun scans a run row selected with metricRunColumns.
+func scanMetricRun(row pgx.Row) (metric.Run, error) {
+	var (
+		data       metric.Run
+		resultsRaw []byte
+	)
+
+	err := row.Scan(
+		&data.ID,
+		&data.JobID,
+		&data.Interval,
+		&data.AsOf,
+		&data.Status,
+		&resultsRaw,
+		&data.StartedAt,
+		&data.FinishedAt,
+	)
+	if err != nil {
+		return metric.Run{}, err
+	}
+
+	if resultsRaw != nil {
+		if err = json.Unmarshal(resultsRaw, &data.Results); err != nil {
+			return metric.Run{}, fmt.Errorf("failed to unmarshal results: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// List retrieves runs with pagination, newest first, optionally filtered by interval.
+func (r *MetricRunRepository) List(ctx context.Context, interval string, limit, offset int) ([]metric.Run, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var total int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM metric_runs WHERE $1 = '' OR metric_interval = $1`, interval).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count metric runs: %w", err)
+	}
+
+	query := `SELECT ` + metricRunColumns + ` FROM metric_runs
+		WHERE $1 = '' OR metric_interval = $1
+		ORDER BY started_at DESC LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, interval, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query metric runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []metric.Run
+	for rows.Next() {
+		data, err := scanMetricRun(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		runs = append(runs, data)
+	}
+
+	return runs, total, rows.Err()
+}
+
+// Add inserts a finished run into the database.
+func (r *MetricRunRepository) Add(ctx context.Context, data metric.Run) (string, error) {
+	if data.ID == "" {
+		data.ID = uuid.NewString()
+	}
+
+	results, err := json.Marshal(data.Results)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal results: %w", err)
+	}
+
+	query := `INSERT INTO metric_runs (id, job_id, metric_interval, as_of, status, results, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err = r.db.Exec(ctx, query,
+		data.ID,
+		data.JobID,
+		data.Interval,
+		data.AsOf,
+		data.Status,
+		results,
+		data.StartedAt,
+		data.FinishedAt,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create metric run: %w", err)
+	}
+
+	return data.ID, nil
+}
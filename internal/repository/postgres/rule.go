@@ -0,0 +1,300 @@
+package postgres
+
+import (
+	"TrackMe/internal/domain/rule"
+	"TrackMe/pkg/store"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RuleRepository handles persistence of automation rules and their execution log in PostgreSQL.
+type RuleRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewRuleRepository creates a new RuleRepository.
+func NewRuleRepository(db *pgxpool.Pool) *RuleRepository {
+	return &RuleRepository{db: db}
+}
+
+const (
+	ruleColumns      = `id, name, description, enabled, trigger, schedule, condition, actions, created_at, updated_at`
+	executionColumns = `id, rule_id, client_id, trigger, dry_run, results, created_at`
+)
+
+// scanRule scans a rule row selected with ruleColumns.
+func scanRule(row pgx.Row) (rule.Entity, error) {
+	var (
+		data       rule.Entity
+		actionsRaw []byte
+	)
+
+	err := row.Scan(
+		&data.ID,
+		&data.Name,
+		&data.Description,
+		&data.Enabled,
+		&data.Trigger,
+		&data.Schedule,
+		&data.Condition,
+		&actionsRaw,
+		&data.CreatedAt,
+		&data.UpdatedAt,
+	)
+	if err != nil {
+		return rule.Entity{}, err
+	}
+
+	if actionsRaw != nil {
+		if err = json.Unmarshal(actionsRaw, &data.Actions); err != nil {
+			return rule.Entity{}, fmt.Errorf("failed to unmarshal actions: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// scanExecution scans an execution row selected with executionColumns.
+func scanExecution(row pgx.Row) (rule.Execution, error) {
+	var (
+		data       rule.Execution
+		resultsRaw []byte
+	)
+
+	err := row.Scan(
+		&data.ID,
+		&data.RuleID,
+		&data.ClientID,
+		&data.Trigger,
+		&data.DryRun,
+		&resultsRaw,
+		&data.CreatedAt,
+	)
+	if err != nil {
+		return rule.Execution{}, err
+	}
+
+	if resultsRaw != nil {
+		if err = json.Unmarshal(resultsRaw, &data.Results); err != nil {
+			return rule.Execution{}, fmt.Errorf("failed to unmarshal results: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// Create inserts a rule into the database.
+func (r *RuleRepository) Create(ctx context.Context, data rule.Entity) (rule.Entity, error) {
+	if data.ID == "" {
+		data.ID = uuid.NewString()
+	}
+
+	actions, err := json.Marshal(data.Actions)
+	if err != nil {
+		return rule.Entity{}, fmt.Errorf("failed to marshal actions: %w", err)
+	}
+
+	query := `INSERT INTO rules (id, name, description, enabled, trigger, schedule, condition, actions, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + ruleColumns
+
+	result, err := scanRule(r.db.QueryRow(ctx, query,
+		data.ID,
+		data.Name,
+		data.Description,
+		data.Enabled,
+		data.Trigger,
+		data.Schedule,
+		data.Condition,
+		actions,
+		data.CreatedAt,
+		data.UpdatedAt,
+	))
+	if err != nil {
+		return rule.Entity{}, fmt.Errorf("failed to create rule: %w", err)
+	}
+
+	return result, nil
+}
+
+// Get retrieves a rule by ID.
+func (r *RuleRepository) Get(ctx context.Context, id string) (rule.Entity, error) {
+	query := `SELECT ` + ruleColumns + ` FROM rules WHERE id = $1`
+
+	data, err := scanRule(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return rule.Entity{}, store.ErrorNotFound
+		}
+		return rule.Entity{}, fmt.Errorf("failed to get rule: %w", err)
+	}
+
+	return data, nil
+}
+
+// List retrieves the rules matching the filters, oldest first.
+func (r *RuleRepository) List(ctx context.Context, filters rule.Filters) ([]rule.Entity, error) {
+	query := `SELECT ` + ruleColumns + ` FROM rules WHERE 1=1`
+
+	args := []interface{}{}
+	argCount := 1
+
+	if filters.Trigger != "" {
+		query += fmt.Sprintf(" AND trigger = $%d", argCount)
+		args = append(args, filters.Trigger)
+		argCount++
+	}
+
+	if filters.Enabled != nil {
+		query += fmt.Sprintf(" AND enabled = $%d", argCount)
+		args = append(args, *filters.Enabled)
+		argCount++
+	}
+
+	query += " ORDER BY created_at, id"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []rule.Entity
+	for rows.Next() {
+		data, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, data)
+	}
+
+	return rules, rows.Err()
+}
+
+// Update replaces a rule.
+func (r *RuleRepository) Update(ctx context.Context, id string, data rule.Entity) (rule.Entity, error) {
+	actions, err := json.Marshal(data.Actions)
+	if err != nil {
+		return rule.Entity{}, fmt.Errorf("failed to marshal actions: %w", err)
+	}
+
+	query := `UPDATE rules SET name = $1, description = $2, enabled = $3, trigger = $4, schedule = $5,
+			condition = $6, actions = $7, updated_at = $8
+		WHERE id = $9
+		RETURNING ` + ruleColumns
+
+	result, err := scanRule(r.db.QueryRow(ctx, query,
+		data.Name,
+		data.Description,
+		data.Enabled,
+		data.Trigger,
+		data.Schedule,
+		data.Condition,
+		actions,
+		data.UpdatedAt,
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return rule.Entity{}, store.ErrorNotFound
+		}
+		return rule.Entity{}, fmt.Errorf("failed to update rule: %w", err)
+	}
+
+	return result, nil
+}
+
+// Delete removes a rule, its executions are removed by the foreign key.
+func (r *RuleRepository) Delete(ctx context.Context, id string) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return store.ErrorNotFound
+	}
+	return nil
+}
+
+// RuleExecutionRepository handles persistence of the rule execution log in PostgreSQL.
+type RuleExecutionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewRuleExecutionRepository creates a new RuleExecutionRepository.
+func NewRuleExecutionRepository(db *pgxpool.Pool) *RuleExecutionRepository {
+	return &RuleExecutionRepository{db: db}
+}
+
+// Add inserts an execution into the database.
+func (r *RuleExecutionRepository) Add(ctx context.Context, data rule.Execution) (rule.Execution, error) {
+	if data.ID == "" {
+		data.ID = uuid.NewString()
+	}
+
+	results, err := json.Marshal(data.Results)
+	if err != nil {
+		return rule.Execution{}, fmt.Errorf("failed to marshal results: %w", err)
+	}
+
+	query := `INSERT INTO rule_executions (id, rule_id, client_id, trigger, dry_run, results, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + executionColumns
+
+	result, err := scanExecution(r.db.QueryRow(ctx, query,
+		data.ID,
+		data.RuleID,
+		data.ClientID,
+		data.Trigger,
+		data.DryRun,
+		results,
+		data.CreatedAt,
+	))
+	if err != nil {
+		return rule.Execution{}, fmt.Errorf("failed to add rule execution: %w", err)
+	}
+
+	return result, nil
+}
+
+// List retrieves the executions matching the filters, newest first, and their total count.
+func (r *RuleExecutionRepository) List(ctx context.Context, filters rule.ExecutionFilters, limit, offset int) ([]rule.Execution, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	where := ` WHERE ($1 = '' OR rule_id::text = $1) AND ($2 = '' OR client_id = $2)`
+
+	var total int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM rule_executions`+where, filters.RuleID, filters.ClientID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count rule executions: %w", err)
+	}
+
+	query := `SELECT ` + executionColumns + ` FROM rule_executions` + where + `
+		ORDER BY created_at DESC LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(ctx, query, filters.RuleID, filters.ClientID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query rule executions: %w", err)
+	}
+	defer rows.Close()
+
+	var executions []rule.Execution
+	for rows.Next() {
+		data, err := scanExecution(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		executions = append(executions, data)
+	}
+
+	return executions, total, rows.Err()
+}
@@ -1,11 +1,22 @@
 package repository
 
 import (
+	"TrackMe/internal/domain/alert"
+	"TrackMe/internal/domain/annotation"
 	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/currency"
+	"TrackMe/internal/domain/experiment"
+	"TrackMe/internal/domain/job"
 	"TrackMe/internal/domain/metric"
+	"TrackMe/internal/domain/reason"
+	"TrackMe/internal/domain/rule"
+	"TrackMe/internal/domain/score"
 	"TrackMe/internal/domain/stage"
+	"TrackMe/internal/domain/touchpoint"
+	"TrackMe/internal/domain/transition"
 	"TrackMe/internal/domain/user"
 	clickhouse "TrackMe/internal/repository/click_house"
+	"TrackMe/internal/repository/http"
 	"TrackMe/internal/repository/memory"
 	"TrackMe/internal/repository/postgres"
 	"TrackMe/pkg/store"
@@ -24,6 +35,23 @@ type Repository struct {
 	Client     client.Repository
 	User       user.Repository
 	Metric     metric.Repository
+	Job        job.Repository
+	MetricRun  metric.RunRepository
+	Definition metric.DefinitionRepository
+	Transition transition.Repository
+	Touchpoint touchpoint.Repository
+	Activity   client.ActivityRepository
+	Reason     reason.Repository
+	Scoring    score.Repository
+	Currency   currency.Provider
+	Alert      alert.Repository
+	AlertRule  alert.RuleRepository
+	Goal       metric.GoalRepository
+	Annotation annotation.Repository
+	Experiment experiment.Repository
+	Rule       rule.Repository
+
+	RuleExecution rule.ExecutionRepository
 }
 
 // New takes a variable amount of Configuration functions and returns a new Repository
@@ -68,6 +96,60 @@ func WithMemoryStore() Configuration {
 	}
 }
 
+// WithHTTPCurrency applies an exchange-rate API client to the Repository
+func WithHTTPCurrency(url, login, password string) Configuration {
+	return func(s *Repository) (err error) {
+		s.Currency = http.NewCurrencyRepository(url, login, password)
+
+		return
+	}
+}
+
+// WithStaticCurrency applies exchange rates loaded from a yaml file to the Repository
+func WithStaticCurrency(path string) Configuration {
+	return func(s *Repository) (err error) {
+		s.Currency, err = memory.NewCurrencyRepository(path)
+
+		return
+	}
+}
+
+// WithMetricDefinitions applies declarative metric definitions loaded from a yaml file to the Repository
+func WithMetricDefinitions(path string) Configuration {
+	return func(s *Repository) (err error) {
+		s.Definition, err = memory.NewMetricDefinitionRepository(path)
+
+		return
+	}
+}
+
+// WithReasons applies the rollback and deactivation reason taxonomy loaded from a yaml file to the Repository
+func WithReasons(path string) Configuration {
+	return func(s *Repository) (err error) {
+		s.Reason, err = memory.NewReasonRepository(path)
+
+		return
+	}
+}
+
+// WithScoringModel applies the lead scoring model loaded from a yaml file to the Repository
+func WithScoringModel(path string) Configuration {
+	return func(s *Repository) (err error) {
+		s.Scoring, err = memory.NewScoringRepository(path)
+
+		return
+	}
+}
+
+// WithAlertRules applies anomaly detection rules loaded from a yaml file to the Repository
+func WithAlertRules(path string) Configuration {
+	return func(s *Repository) (err error) {
+		s.AlertRule, err = memory.NewAlertRuleRepository(path)
+
+		return
+	}
+}
+
 // WithClickHouseStore sets ClickHouse repositories
 func WithClickHouseStore(addr, userName, password, db string) Configuration {
 	return func(s *Repository) (err error) {
@@ -80,6 +162,9 @@ func WithClickHouseStore(addr, userName, password, db string) Configuration {
 		// s.Client = clickhouse.NewClientRepository(s.clickhouse.Conn)
 		// s.User = clickhouse.NewUserRepository(s.clickhouse.Conn)
 		s.Metric = clickhouse.NewMetricRepository(s.clickhouse.Conn)
+		s.Transition = clickhouse.NewTransitionRepository(s.clickhouse.Conn)
+		s.Touchpoint = clickhouse.NewTouchpointRepository(s.clickhouse.Conn)
+		s.Activity = clickhouse.NewClientActivityRepository(s.clickhouse.Conn)
 
 		return nil
 	}
@@ -96,6 +181,14 @@ func WithPostgresStore(dsn string) Configuration {
 
 		s.Client = postgres.NewClientRepository(s.postgres.Client)
 		s.User = postgres.NewUserRepository(s.postgres.Client)
+		s.Job = postgres.NewJobRepository(s.postgres.Client)
+		s.MetricRun = postgres.NewMetricRunRepository(s.postgres.Client)
+		s.Alert = postgres.NewAlertRepository(s.postgres.Client)
+		s.Goal = postgres.NewMetricGoalRepository(s.postgres.Client)
+		s.Annotation = postgres.NewAnnotationRepository(s.postgres.Client)
+		s.Experiment = postgres.NewExperimentRepository(s.postgres.Client)
+		s.Rule = postgres.NewRuleRepository(s.postgres.Client)
+		s.RuleExecution = postgres.NewRuleExecutionRepository(s.postgres.Client)
 
 		return nil
 	}
@@ -0,0 +1,206 @@
+package track
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/metric"
+	"TrackMe/internal/domain/reason"
+	"TrackMe/internal/domain/rule"
+	"TrackMe/pkg/log"
+	"TrackMe/pkg/store"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WithActivityRepository applies a given client activity event repository to the Service
+func WithActivityRepository(activity client.ActivityRepository) Configuration {
+	return func(s *Service) error {
+		s.activity = activity
+		return nil
+	}
+}
+
+// DeactivateClient marks an active client inactive, optionally with a deactivation reason code
+func (s *Service) DeactivateClient(ctx context.Context, id string, req client.ActivityRequest) (client.Response, error) {
+	return s.setClientActivity(ctx, id, client.Deactivated, req)
+}
+
+// ReactivateClient marks an inactive client active again, optionally with a reason code
+func (s *Service) ReactivateClient(ctx context.Context, id string, req client.ActivityRequest) (client.Response, error) {
+	return s.setClientActivity(ctx, id, client.Reactivated, req)
+}
+
+// DeactivateInactiveClients deactivates the active clients whose last login is older than the
+// threshold with the inactive reason and returns how many were deactivated. Clients that never
+// logged in, and clients reactivated within the threshold, are left alone.
+func (s *Service) DeactivateInactiveClients(ctx context.Context, threshold time.Duration) (int, error) {
+	logger := log.LoggerFromContext(ctx).With().
+		Dur("threshold", threshold).
+		Str("component", "service.track.activity").
+		Logger()
+
+	isActive := true
+	clients, err := s.clientRepository.ListAll(ctx, client.Filters{IsActive: &isActive})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	now := s.clock()
+	cutoff := now.Add(-threshold)
+
+	// A reactivation does not change the last login, without this the client would be
+	// deactivated again on the next run
+	reactivatedAt := make(map[string]time.Time)
+	if s.activity != nil {
+		events, err := s.activity.List(ctx, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list client activity: %w", err)
+		}
+		for _, e := range events {
+			if e.Event == client.Reactivated && e.CreatedAt.After(reactivatedAt[e.ClientID]) {
+				reactivatedAt[e.ClientID] = e.CreatedAt
+			}
+		}
+	}
+
+	var deactivated int
+	for _, c := range clients {
+		if c.LastLogin == nil || c.LastLogin.IsZero() || !c.LastLogin.Before(cutoff) {
+			continue
+		}
+		if reactivatedAt[c.ID].After(cutoff) {
+			continue
+		}
+
+		req := client.ActivityRequest{
+			Reason:  client.ReasonInactive,
+			Comment: "no login since " + c.LastLogin.In(s.location).Format(time.DateOnly),
+		}
+		if _, err = s.setClientActivity(ctx, c.ID, client.Deactivated, req); err != nil {
+			logger.Warn().Err(err).Str("client_id", c.ID).Msg("failed to deactivate inactive client")
+			continue
+		}
+		deactivated++
+	}
+
+	logger.Info().Int("deactivated", deactivated).Msg("Inactive clients deactivated")
+	return deactivated, nil
+}
+
+// setClientActivity switches the activity of a client and records the event
+func (s *Service) setClientActivity(ctx context.Context, id, event string, req client.ActivityRequest) (client.Response, error) {
+	logger := log.LoggerFromContext(ctx).With().
+		Str("client_id", id).
+		Str("event", event).
+		Str("component", "service.track.activity").
+		Logger()
+
+	if _, err := uuid.Parse(id); err != nil {
+		return client.Response{}, store.ErrorNotFound
+	}
+
+	existing, err := s.clientRepository.Get(ctx, id)
+	if err != nil {
+		return client.Response{}, err
+	}
+
+	active := event == client.Reactivated
+	if existing.IsActive != nil && *existing.IsActive == active {
+		return client.Response{}, client.ErrAlreadyInState
+	}
+
+	currentStage := deref(existing.CurrentStage)
+	if event == client.Deactivated && req.Reason != client.ReasonInactive {
+		if err = s.reasons.Check(reason.Deactivation, req.Reason, currentStage); err != nil {
+			return client.Response{}, err
+		}
+	}
+	existing.IsActive = &active
+
+	if err = s.scoreClient(ctx, &existing); err != nil {
+		return client.Response{}, err
+	}
+
+	result, err := s.clientRepository.Update(ctx, id, existing)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to update client activity")
+		return client.Response{}, err
+	}
+
+	if s.activity != nil {
+		data := client.Activity{
+			ClientID:  id,
+			Event:     event,
+			Stage:     currentStage,
+			Reason:    req.Reason,
+			Comment:   req.Comment,
+			CreatedAt: s.clock(),
+		}
+		if result.LastUpdated != nil {
+			data.CreatedAt = *result.LastUpdated
+		}
+		// Events feed churn metrics and must not fail the change that already happened
+		if err = s.activity.Add(ctx, data); err != nil {
+			logger.Warn().Err(err).Msg("failed to record client activity")
+		}
+	}
+
+	s.evaluateRules(ctx, rule.TriggerUpdated, result, deref(result.CurrentStage))
+
+	logger.Info().Str("reason", req.Reason).Msg("client activity changed")
+	return client.ParseFromEntity(result), nil
+}
+
+// calculateActivityRates calculates the churn rate, the share of the clients active at the start
+// of the period that were deactivated in it, and the reactivation rate, the share of the clients
+// inactive at the start that were reactivated in it. Clients without activity events fall back to
+// their current state, an inactive client last updated in the period counting as churned. Without
+// any activity events no reactivation rate is stored.
+func (s *Service) calculateActivityRates(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.activity").Logger()
+
+	clients, err := s.clientRepository.ListAll(ctx, client.Filters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	events := make(map[string][]client.Activity)
+	if s.activity != nil {
+		list, err := s.activity.List(ctx, p.AsOf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list client activity: %w", err)
+		}
+		for _, e := range list {
+			events[e.ClientID] = append(events[e.ClientID], e)
+		}
+	}
+	fromEvents := len(events) > 0
+
+	counts := client.CountActivity(clients, events, p.Start, p.AsOf)
+
+	logger.Info().
+		Bool("from_events", fromEvents).
+		Int("active_at_start", counts.ActiveAtStart).
+		Int("churned", counts.Churned).
+		Int("inactive_at_start", counts.InactiveAtStart).
+		Int("reactivated", counts.Reactivated).
+		Msg("Client activity calculation results")
+
+	churnMetric, err := s.createMetric("", metric.ChurnRate, counts.ChurnRate(), p.Interval, p.AsOf, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create churn rate metric: %w", err)
+	}
+	res := []metric.Entity{churnMetric}
+
+	if fromEvents {
+		reactivationMetric, err := s.createMetric("", metric.ReactivationRate, counts.ReactivationRate(), p.Interval, p.AsOf, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create reactivation rate metric: %w", err)
+		}
+		res = append(res, reactivationMetric)
+	}
+
+	return res, nil
+}
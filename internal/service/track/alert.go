@@ -0,0 +1,147 @@
+package track
+
+import (
+	"TrackMe/internal/domain/alert"
+	"TrackMe/internal/domain/metric"
+	"TrackMe/pkg/log"
+	"TrackMe/pkg/store"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// notifyTimeout bounds the delivery of an alert through a single notifier
+const notifyTimeout = 15 * time.Second
+
+// WithAlertRepository applies a given alert repository to the Service
+func WithAlertRepository(alerts alert.Repository) Configuration {
+	return func(s *Service) error {
+		s.alerts = alerts
+		return nil
+	}
+}
+
+// WithAlertRules applies the anomaly detection rules of the repository, one per metric type.
+// Rules must refer to metric types a registered calculator produces.
+func WithAlertRules(rules alert.RuleRepository) Configuration {
+	return func(s *Service) error {
+		list, err := rules.List(context.Background())
+		if err != nil {
+			return err
+		}
+
+		for _, r := range list {
+			if !s.calculators.HasType(r.Type) {
+				return fmt.Errorf("alert rule: unknown metric type %s", r.Type)
+			}
+			if _, ok := s.alertRules[r.Type]; ok {
+				return fmt.Errorf("alert rule: metric type %s has more than one rule", r.Type)
+			}
+			s.alertRules[r.Type] = r
+		}
+		return nil
+	}
+}
+
+// WithNotifiers applies the channels anomaly alerts are sent through
+func WithNotifiers(notifiers ...alert.Notifier) Configuration {
+	return func(s *Service) error {
+		s.notifiers = append(s.notifiers, notifiers...)
+		return nil
+	}
+}
+
+// ListAlerts retrieves detected anomalies, newest first, optionally filtered by metric type
+func (s *Service) ListAlerts(ctx context.Context, metricType string, limit, offset int) ([]alert.Entity, int, error) {
+	if s.alerts == nil {
+		return nil, 0, errors.New("alert repository is not configured")
+	}
+
+	return s.alerts.List(ctx, metricType, limit, offset)
+}
+
+// detectAnomalies compares every stored metric that has a rule with the preceding values of its
+// series, then records and sends an alert for every anomaly. Failures are logged only, so they
+// never fail a calculation.
+func (s *Service) detectAnomalies(ctx context.Context, stored []metric.Entity) {
+	if len(s.alertRules) == 0 {
+		return
+	}
+
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.alert").Logger()
+
+	for _, m := range stored {
+		rule, ok := s.alertRules[string(*m.Type)]
+		if !ok {
+			continue
+		}
+
+		history, err := s.seriesHistory(ctx, m, rule.Window)
+		if err != nil {
+			logger.Error().Err(err).Str("type", string(*m.Type)).Msg("failed to load metric history")
+			continue
+		}
+
+		data, anomalous := rule.Detect(*m.Value, history)
+		if !anomalous {
+			continue
+		}
+
+		data.MetricID = m.ID
+		data.Type = string(*m.Type)
+		data.Interval = *m.Interval
+		data.Metadata = m.Metadata
+		data.MetricCreatedAt = *m.CreatedAt
+		data.DetectedAt = s.clock()
+
+		if s.alerts != nil {
+			if data.ID, err = s.alerts.Add(ctx, data); err != nil {
+				logger.Error().Err(err).Str("type", data.Type).Msg("failed to store alert")
+			}
+		}
+
+		logger.Warn().
+			Str("type", data.Type).
+			Interface("metadata", data.Metadata).
+			Float64("value", data.Value).
+			Float64("baseline", data.Baseline).
+			Float64("score", data.Score).
+			Msg("metric anomaly detected")
+
+		for _, n := range s.notifiers {
+			notifyCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+			if err = n.Notify(notifyCtx, data); err != nil {
+				logger.Error().Err(err).Str("notifier", n.Name()).Str("type", data.Type).Msg("failed to send alert")
+			}
+			cancel()
+		}
+	}
+}
+
+// seriesHistory returns up to window values of the series of m created before it, oldest first.
+// The series is the metrics of the same type and interval with exactly the same metadata.
+func (s *Service) seriesHistory(ctx context.Context, m metric.Entity, window int) ([]float64, error) {
+	entities, err := s.MetricRepository.List(ctx, metric.Filters{
+		Type:     string(*m.Type),
+		Interval: *m.Interval,
+		To:       m.CreatedAt.Add(-time.Second),
+		Metadata: m.Metadata,
+	})
+	if err != nil && !errors.Is(err, store.ErrorNotFound) {
+		return nil, err
+	}
+
+	var values []float64
+	for _, e := range entities {
+		if e.ID == m.ID || e.Value == nil || !maps.Equal(e.Metadata, m.Metadata) {
+			continue
+		}
+		values = append(values, *e.Value)
+	}
+	if len(values) > window {
+		values = values[len(values)-window:]
+	}
+	return values, nil
+}
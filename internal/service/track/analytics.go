@@ -0,0 +1,435 @@
+package track
+
+import (
+	"TrackMe/internal/domain/analytics"
+	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/metric"
+	"TrackMe/internal/domain/stage"
+	"TrackMe/internal/domain/transition"
+	"TrackMe/pkg/log"
+	"TrackMe/pkg/store"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+type AnalyticsTrackService interface {
+	CohortAnalysis(ctx context.Context, by, metricName, stageID string, periods int) (analytics.CohortMatrix, error)
+	ClientFlows(ctx context.Context, from, to time.Time, limit int) (analytics.Flows, error)
+	Bottlenecks(ctx context.Context) (analytics.BottleneckReport, error)
+	ForecastCompletions(ctx context.Context, horizonDays int) (analytics.Forecast, error)
+	Location() *time.Location
+}
+
+const (
+	// maxCohortPeriods limits how many registration periods a cohort analysis may span
+	maxCohortPeriods = 52
+
+	// defaultFlowRange is the range of a flow analysis without a start
+	defaultFlowRange = 30 * 24 * time.Hour
+
+	// bottleneckWeek is the window a bottleneck report compares with the one before it
+	bottleneckWeek = 7 * 24 * time.Hour
+
+	// maxForecastDays limits the horizon of a completion forecast
+	maxForecastDays = 365
+)
+
+// CohortAnalysis groups clients by the by period (day, week, month) of their registration, over
+// the current period and the periods-1 before it, and reports per cohort the share that reached
+// stageID (conversion, the last stage when empty) or stayed active after every following period.
+func (s *Service) CohortAnalysis(ctx context.Context, by, metricName, stageID string, periods int) (analytics.CohortMatrix, error) {
+	logger := log.LoggerFromContext(ctx).With().
+		Str("by", by).
+		Str("metric", metricName).
+		Str("component", "service.track.analytics.cohorts").
+		Logger()
+
+	if metricName != analytics.CohortConversion && metricName != analytics.CohortActive {
+		return analytics.CohortMatrix{}, fmt.Errorf("%w metric: %s (valid values: %s, %s)", store.ErrorInvalid, metricName, analytics.CohortConversion, analytics.CohortActive)
+	}
+	if periods < 1 || periods > maxCohortPeriods {
+		return analytics.CohortMatrix{}, fmt.Errorf("%w periods: must be between 1 and %d", store.ErrorInvalid, maxCohortPeriods)
+	}
+
+	window, err := s.recentPeriods(by, periods)
+	if err != nil {
+		return analytics.CohortMatrix{}, err
+	}
+	slices.Reverse(window)
+
+	res := analytics.CohortMatrix{By: by, Metric: metricName}
+	now := s.clock()
+
+	clients, err := s.clientRepository.ListAll(ctx, client.Filters{})
+	if err != nil {
+		return analytics.CohortMatrix{}, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	var reached map[string]time.Time
+	if metricName == analytics.CohortConversion {
+		stages, err := s.StageRepository.List(ctx)
+		if err != nil {
+			return analytics.CohortMatrix{}, err
+		}
+		if len(stages) == 0 {
+			return analytics.CohortMatrix{}, fmt.Errorf("%w stage: no stages configured", store.ErrorInvalid)
+		}
+		if stageID == "" {
+			stageID = stages[len(stages)-1].ID
+		}
+		res.Stage = stageID
+
+		if reached, err = s.stageReachedAt(ctx, stages, stageID, clients, window[0].Start, now); err != nil {
+			return analytics.CohortMatrix{}, err
+		}
+	}
+
+	members := make([]analytics.CohortMember, 0, len(clients))
+	for _, c := range clients {
+		if c.RegistrationDate == nil {
+			continue
+		}
+		m := analytics.CohortMember{Registered: *c.RegistrationDate}
+		if t, ok := reached[c.ID]; ok {
+			m.Reached = &t
+		}
+		for _, seen := range []*time.Time{c.LastLogin, c.LastUpdated} {
+			if seen != nil && !seen.IsZero() && (m.LastActive == nil || seen.After(*m.LastActive)) {
+				m.LastActive = seen
+			}
+		}
+		members = append(members, m)
+	}
+
+	res.Cohorts = analytics.Cohorts(window, members, metricName, now)
+
+	logger.Info().
+		Int("clients", len(members)).
+		Int("cohorts", len(res.Cohorts)).
+		Msg("Cohort analysis results")
+
+	return res, nil
+}
+
+// ClientFlows aggregates the stage transitions between from and to into Sankey nodes and links,
+// the limit most common client paths and the round trips between stages. A zero to means now and
+// a zero from 30 days before to.
+func (s *Service) ClientFlows(ctx context.Context, from, to time.Time, limit int) (analytics.Flows, error) {
+	logger := log.LoggerFromContext(ctx).With().
+		Str("component", "service.track.analytics.flows").
+		Logger()
+
+	if s.transitions == nil {
+		return analytics.Flows{}, errors.New("transition repository is not configured")
+	}
+
+	if to.IsZero() {
+		to = s.clock()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultFlowRange)
+	}
+	if to.Before(from) {
+		return analytics.Flows{}, fmt.Errorf("%w range: from must not be after to", store.ErrorInvalid)
+	}
+
+	stages, err := s.StageRepository.List(ctx)
+	if err != nil {
+		return analytics.Flows{}, err
+	}
+
+	order := stageOrders(stages)
+	nodes := flowNodes(stages, order)
+
+	events, err := s.transitions.List(ctx, from, to)
+	if err != nil {
+		return analytics.Flows{}, fmt.Errorf("failed to list stage transitions: %w", err)
+	}
+
+	res := analytics.BuildFlows(nodes, events, limit)
+	res.From, res.To = from.In(s.location), to.In(s.location)
+
+	logger.Info().
+		Int("transitions", len(events)).
+		Int("clients", res.Clients).
+		Int("rollbacks", res.Rollbacks).
+		Msg("Client flow results")
+
+	return res, nil
+}
+
+// Bottlenecks ranks the stages by median dwell time, queue size, outflow rate and rollback rate
+// over the last 7 days and compares them with the 7 days before. Dwell times and queues are the
+// latest stored stage-duration (p50) and clients-per-stage metrics at the end of each week; exits
+// come from the stage transition events.
+func (s *Service) Bottlenecks(ctx context.Context) (analytics.BottleneckReport, error) {
+	logger := log.LoggerFromContext(ctx).With().
+		Str("component", "service.track.analytics.bottlenecks").
+		Logger()
+
+	stages, err := s.StageRepository.List(ctx)
+	if err != nil {
+		return analytics.BottleneckReport{}, err
+	}
+
+	order := stageOrders(stages)
+	nodes := flowNodes(stages, order)
+
+	now := s.clock()
+	current, err := s.stageLoads(ctx, order, now.Add(-bottleneckWeek), now)
+	if err != nil {
+		return analytics.BottleneckReport{}, err
+	}
+	previous, err := s.stageLoads(ctx, order, now.Add(-2*bottleneckWeek), now.Add(-bottleneckWeek))
+	if err != nil {
+		return analytics.BottleneckReport{}, err
+	}
+
+	res := analytics.BottleneckReport{
+		AsOf:   now.In(s.location),
+		Stages: analytics.RankBottlenecks(nodes, current, previous),
+	}
+
+	if len(res.Stages) > 0 {
+		logger.Info().
+			Str("top", res.Stages[0].Stage).
+			Float64("score", res.Stages[0].Current.Score).
+			Msg("Bottleneck report results")
+	}
+
+	return res, nil
+}
+
+// ForecastCompletions fits a daily Markov model of stage transitions on the transition history
+// and predicts how many active clients reach the last stage within horizonDays, per stage and per
+// client, with a 90% band.
+func (s *Service) ForecastCompletions(ctx context.Context, horizonDays int) (analytics.Forecast, error) {
+	logger := log.LoggerFromContext(ctx).With().
+		Int("horizon_days", horizonDays).
+		Str("component", "service.track.analytics.forecast").
+		Logger()
+
+	if horizonDays < 1 || horizonDays > maxForecastDays {
+		return analytics.Forecast{}, fmt.Errorf("%w horizon: must be between 1 and %d days", store.ErrorInvalid, maxForecastDays)
+	}
+
+	stages, err := s.StageRepository.List(ctx)
+	if err != nil {
+		return analytics.Forecast{}, err
+	}
+	if len(stages) == 0 {
+		return analytics.Forecast{}, errors.New("no stages configured")
+	}
+
+	ids := make([]string, len(stages))
+	for i, st := range stages {
+		ids[i] = st.ID
+	}
+	target := ids[len(ids)-1]
+
+	now := s.clock()
+	var events []transition.Entity
+	if s.transitions != nil {
+		if events, err = s.transitions.List(ctx, time.Time{}, now); err != nil {
+			return analytics.Forecast{}, fmt.Errorf("failed to list stage transitions: %w", err)
+		}
+	}
+
+	model := analytics.FitMarkov(ids, target, events, now)
+	probability, expected := model.Completion(horizonDays)
+
+	clients, err := s.activeClients(ctx, "")
+	if err != nil {
+		return analytics.Forecast{}, err
+	}
+
+	inStage := make(map[string]int)
+	var forecasts []analytics.ClientForecast
+	for _, c := range clients {
+		if c.CurrentStage == nil || *c.CurrentStage == target {
+			continue
+		}
+		p, ok := probability[*c.CurrentStage]
+		if !ok {
+			continue
+		}
+		inStage[*c.CurrentStage]++
+		f := analytics.ClientForecast{ClientID: c.ID, Stage: *c.CurrentStage, Probability: p}
+		if c.Name != nil {
+			f.Name = *c.Name
+		}
+		forecasts = append(forecasts, f)
+	}
+	sort.SliceStable(forecasts, func(i, j int) bool {
+		return forecasts[i].Probability > forecasts[j].Probability
+	})
+
+	res := analytics.NewForecast(forecasts)
+	res.AsOf = now.In(s.location)
+	res.HorizonDays = horizonDays
+	res.Target = target
+	res.Transitions = len(events)
+	if res.Clients == nil {
+		res.Clients = []analytics.ClientForecast{}
+	}
+
+	for _, id := range ids[:len(ids)-1] {
+		next := model.Next[id]
+		if next == nil {
+			next = map[string]float64{}
+		}
+		res.Stages = append(res.Stages, analytics.StageForecast{
+			Stage:                    id,
+			Clients:                  inStage[id],
+			ExitRate:                 model.ExitRate[id],
+			Transitions:              next,
+			CompletionProbability:    probability[id],
+			ExpectedDaysToCompletion: expected[id],
+		})
+	}
+
+	logger.Info().
+		Int("transitions", len(events)).
+		Int("clients", res.ActiveClients).
+		Float64("expected_completions", res.ExpectedCompletions).
+		Msg("Completion forecast results")
+
+	return res, nil
+}
+
+// stageLoads measures every stage over the window [from, to]
+func (s *Service) stageLoads(ctx context.Context, order map[string]int, from, to time.Time) (map[string]analytics.StageLoad, error) {
+	dwell, err := s.latestStageValues(ctx, metric.StageDuration, to, map[string]string{"percentile": "p50"})
+	if err != nil {
+		return nil, err
+	}
+	queue, err := s.latestStageValues(ctx, metric.ClientsPerStage, to, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	exits := make(map[string]int)
+	rollbacks := make(map[string]int)
+	if s.transitions != nil {
+		events, err := s.transitions.List(ctx, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stage transitions: %w", err)
+		}
+		for _, e := range events {
+			if e.FromStage == "" || e.FromStage == e.ToStage {
+				continue
+			}
+			exits[e.FromStage]++
+			if order[e.ToStage] < order[e.FromStage] {
+				rollbacks[e.FromStage]++
+			}
+		}
+	}
+
+	res := make(map[string]analytics.StageLoad, len(order))
+	for id := range order {
+		res[id] = analytics.NewStageLoad(dwell[id], queue[id], exits[id], rollbacks[id])
+	}
+	return res, nil
+}
+
+// latestStageValues returns per stage the value of the newest stored snapshot metric of the type
+// created at or before to and matching metadata
+func (s *Service) latestStageValues(ctx context.Context, metricType metric.Type, to time.Time, metadata map[string]string) (map[string]float64, error) {
+	entities, err := s.MetricRepository.List(ctx, metric.Filters{
+		Type:     string(metricType),
+		To:       to,
+		Metadata: metadata,
+		Latest:   true,
+	})
+	if err != nil && !errors.Is(err, store.ErrorNotFound) {
+		return nil, fmt.Errorf("failed to list %s metrics: %w", metricType, err)
+	}
+
+	res := make(map[string]float64)
+	newest := make(map[string]time.Time)
+	for _, m := range entities {
+		id := m.Metadata["stage"]
+		if id == "" || m.Value == nil || m.CreatedAt == nil {
+			continue
+		}
+		if t, ok := newest[id]; ok && !m.CreatedAt.After(t) {
+			continue
+		}
+		newest[id] = *m.CreatedAt
+		res[id] = *m.Value
+	}
+	return res, nil
+}
+
+// stageReachedAt returns per client the first time it reached stageID or a later stage. Transition
+// events are used for clients that have them; a client without events on stageID or a later stage
+// is assumed to have reached it at its last update.
+func (s *Service) stageReachedAt(ctx context.Context, stages []stage.Entity, stageID string, clients []client.Entity, from, now time.Time) (map[string]time.Time, error) {
+	order := stageOrders(stages)
+	target, ok := order[stageID]
+	if !ok {
+		return nil, fmt.Errorf("%w stage: %s", store.ErrorInvalid, stageID)
+	}
+
+	res := make(map[string]time.Time)
+
+	withEvents, err := s.clientsWithTransitions(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(withEvents) > 0 {
+		events, err := s.transitions.List(ctx, from, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stage transitions: %w", err)
+		}
+		for _, e := range events {
+			if o, ok := order[e.ToStage]; !ok || o < target {
+				continue
+			}
+			if t, ok := res[e.ClientID]; !ok || e.CreatedAt.Before(t) {
+				res[e.ClientID] = e.CreatedAt
+			}
+		}
+	}
+
+	for _, c := range clients {
+		if withEvents[c.ID] || c.CurrentStage == nil || c.LastUpdated == nil {
+			continue
+		}
+		if o, ok := order[*c.CurrentStage]; ok && o >= target {
+			res[c.ID] = *c.LastUpdated
+		}
+	}
+	return res, nil
+}
+
+// flowNodes describes the stages of the pipeline as analysis nodes
+func flowNodes(stages []stage.Entity, order map[string]int) []analytics.FlowNode {
+	nodes := make([]analytics.FlowNode, len(stages))
+	for i, st := range stages {
+		nodes[i] = analytics.FlowNode{ID: st.ID, Name: st.ID, Order: order[st.ID]}
+		if st.Name != nil {
+			nodes[i].Name = *st.Name
+		}
+	}
+	return nodes
+}
+
+// stageOrders maps stage IDs to their order in the pipeline
+func stageOrders(stages []stage.Entity) map[string]int {
+	res := make(map[string]int, len(stages))
+	for i, st := range stages {
+		order := i + 1
+		if st.Order != nil {
+			order = *st.Order
+		}
+		res[st.ID] = order
+	}
+	return res
+}
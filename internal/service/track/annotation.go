@@ -0,0 +1,131 @@
+package track
+
+import (
+	"TrackMe/internal/domain/annotation"
+	"TrackMe/internal/domain/metric"
+	"TrackMe/pkg/store"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// WithAnnotationRepository applies a given metric annotation repository to the Service
+func WithAnnotationRepository(annotations annotation.Repository) Configuration {
+	return func(s *Service) error {
+		s.annotations = annotations
+		return nil
+	}
+}
+
+// CreateAnnotation marks a time range, e.g. a campaign or a release, on the metrics on behalf of the user
+func (s *Service) CreateAnnotation(ctx context.Context, req annotation.Request, userID string) (annotation.Entity, error) {
+	if s.annotations == nil {
+		return annotation.Entity{}, errors.New("annotation repository is not configured")
+	}
+
+	now := s.clock()
+	data := annotation.New(req)
+	data.CreatedBy = &userID
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	data, err := s.annotations.Create(ctx, data)
+	if err != nil {
+		return annotation.Entity{}, err
+	}
+
+	return s.annotationResponse(data), nil
+}
+
+// GetAnnotation retrieves an annotation by ID
+func (s *Service) GetAnnotation(ctx context.Context, id string) (annotation.Entity, error) {
+	if s.annotations == nil {
+		return annotation.Entity{}, errors.New("annotation repository is not configured")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return annotation.Entity{}, store.ErrorNotFound
+	}
+
+	data, err := s.annotations.Get(ctx, id)
+	if err != nil {
+		return annotation.Entity{}, err
+	}
+
+	return s.annotationResponse(data), nil
+}
+
+// ListAnnotations retrieves the annotations matching the filters, ordered by start
+func (s *Service) ListAnnotations(ctx context.Context, filters annotation.Filters) ([]annotation.Entity, error) {
+	if s.annotations == nil {
+		return nil, errors.New("annotation repository is not configured")
+	}
+	if !filters.From.IsZero() && !filters.To.IsZero() && filters.To.Before(filters.From) {
+		return nil, fmt.Errorf("%w range: from must not be after to", store.ErrorInvalid)
+	}
+
+	data, err := s.annotations.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range data {
+		data[i] = s.annotationResponse(data[i])
+	}
+	return data, nil
+}
+
+// UpdateAnnotation replaces an annotation by ID
+func (s *Service) UpdateAnnotation(ctx context.Context, id string, req annotation.Request) (annotation.Entity, error) {
+	if s.annotations == nil {
+		return annotation.Entity{}, errors.New("annotation repository is not configured")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return annotation.Entity{}, store.ErrorNotFound
+	}
+
+	data := annotation.New(req)
+	data.UpdatedAt = s.clock()
+
+	data, err := s.annotations.Update(ctx, id, data)
+	if err != nil {
+		return annotation.Entity{}, err
+	}
+
+	return s.annotationResponse(data), nil
+}
+
+// DeleteAnnotation removes an annotation by ID
+func (s *Service) DeleteAnnotation(ctx context.Context, id string) error {
+	if s.annotations == nil {
+		return errors.New("annotation repository is not configured")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return store.ErrorNotFound
+	}
+
+	return s.annotations.Delete(ctx, id)
+}
+
+// MetricAnnotations retrieves the annotations overlapping the range of a metrics request that
+// apply to its source and channel. It returns nothing when annotations are not configured.
+func (s *Service) MetricAnnotations(ctx context.Context, filters metric.Filters) ([]annotation.Entity, error) {
+	if s.annotations == nil {
+		return nil, nil
+	}
+
+	return s.ListAnnotations(ctx, annotation.Filters{
+		From:    filters.From,
+		To:      filters.To,
+		Source:  filters.Metadata["source"],
+		Channel: filters.Metadata["channel"],
+	})
+}
+
+// annotationResponse converts the range of an annotation to the reporting timezone
+func (s *Service) annotationResponse(data annotation.Entity) annotation.Entity {
+	data.StartsAt = data.StartsAt.In(s.location)
+	data.EndsAt = data.EndsAt.In(s.location)
+	return data
+}
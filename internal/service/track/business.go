@@ -0,0 +1,189 @@
+package track
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/metric"
+	"TrackMe/pkg/log"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SLA modes of the sla-breaches metric
+const (
+	slaModeCalendar = "calendar"
+	slaModeBusiness = "business"
+)
+
+// calculateStageBusinessDuration stores the p50, p75, p90 and p95 working hours clients spend in
+// each stage, measured in the service calendar. Stays come from transition events for clients
+// that have them; for active clients without events the time spent in their current stage is used.
+func (s *Service) calculateStageBusinessDuration(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.stage_business_duration").Logger()
+
+	stays, eventClients, err := s.stageStays(ctx, p.AsOf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Working hours per stage
+	stageDurations := make(map[string][]float64)
+	for _, stay := range stays {
+		stageDurations[stay.Stage] = append(stageDurations[stay.Stage], s.calendar.BusinessDuration(stay.EnteredAt, stay.LeftAt).Hours())
+	}
+
+	logger.Info().
+		Int("event_clients", eventClients).
+		Int("stays", len(stays)).
+		Int("stages", len(stageDurations)).
+		Msg("Stage business duration calculation results")
+
+	var res []metric.Entity
+	for stageID, durations := range stageDurations {
+		quantiles := metric.Quantiles(durations, percentileLevels()...)
+		for i, pct := range durationPercentiles {
+			if i >= len(quantiles) {
+				break
+			}
+			m, err := s.createMetric("", metric.StageBusinessDuration, quantiles[i], "", p.AsOf, map[string]string{
+				"stage":      stageID,
+				"percentile": pct.Label,
+			})
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, m)
+		}
+	}
+
+	return res, nil
+}
+
+// calculateTotalBusinessDuration stores the p50, p75, p90 and p95 working hours clients take to
+// reach the last stage, measured in the service calendar. Journeys come from transition events
+// for clients that have them; for active clients on the last stage without events registration to
+// their last update is used.
+func (s *Service) calculateTotalBusinessDuration(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.total_business_duration").Logger()
+
+	stages, err := s.StageRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stages) == 0 {
+		return nil, nil
+	}
+
+	lastStage := stages[len(stages)-1].ID
+
+	journeys, eventClients, err := s.journeys(ctx, lastStage, p.AsOf)
+	if err != nil {
+		return nil, err
+	}
+
+	durations := make([]float64, 0, len(journeys))
+	for _, j := range journeys {
+		durations = append(durations, s.calendar.BusinessDuration(j.StartedAt, j.FinishedAt).Hours())
+	}
+
+	logger.Info().
+		Int("event_clients", eventClients).
+		Int("clients", len(durations)).
+		Msg("Total business duration calculation results")
+
+	// Without finished journeys every percentile is zero, like total-duration
+	quantiles := metric.Quantiles(durations, percentileLevels()...)
+	if len(quantiles) == 0 {
+		quantiles = make([]float64, len(durationPercentiles))
+	}
+
+	var res []metric.Entity
+	for i, pct := range durationPercentiles {
+		if i >= len(quantiles) {
+			break
+		}
+		m, err := s.createMetric("", metric.TotalBusinessDuration, quantiles[i], "", p.AsOf, map[string]string{"percentile": pct.Label})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create total business duration metric: %w", err)
+		}
+		res = append(res, m)
+	}
+
+	return res, nil
+}
+
+// calculateSLABreaches stores, per stage with an SLA, the number of active clients that have been
+// in the stage longer than allowed. Stages may set a calendar-hours SLA, a working-hours SLA or
+// both; each is checked and stored separately under the mode metadata key.
+func (s *Service) calculateSLABreaches(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.sla_breaches").Logger()
+
+	stages, err := s.StageRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := s.activeClients(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Time in the current stage, per stage
+	inStage := make(map[string][]time.Time)
+	for _, c := range clients {
+		if c.CurrentStage == nil || c.LastUpdated == nil {
+			continue
+		}
+		inStage[*c.CurrentStage] = append(inStage[*c.CurrentStage], *c.LastUpdated)
+	}
+
+	var res []metric.Entity
+	for _, st := range stages {
+		checks := []struct {
+			mode  string
+			limit *float64
+			hours func(since time.Time) float64
+		}{
+			{slaModeCalendar, st.SLAHours, func(since time.Time) float64 { return p.AsOf.Sub(since).Hours() }},
+			{slaModeBusiness, st.SLABusinessHours, func(since time.Time) float64 { return s.calendar.BusinessDuration(since, p.AsOf).Hours() }},
+		}
+
+		for _, check := range checks {
+			if check.limit == nil {
+				continue
+			}
+
+			var breaches int
+			for _, since := range inStage[st.ID] {
+				if check.hours(since) > *check.limit {
+					breaches++
+				}
+			}
+
+			m, err := s.createMetric("", metric.SLABreaches, float64(breaches), "", p.AsOf, map[string]string{
+				"stage":     st.ID,
+				"mode":      check.mode,
+				"sla_hours": strconv.FormatFloat(*check.limit, 'f', -1, 64),
+			})
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, m)
+		}
+	}
+
+	logger.Info().
+		Int("checks", len(res)).
+		Msg("SLA breaches calculation results")
+
+	return res, nil
+}
+
+// activeClients lists the active clients, on the given stage when it is set
+func (s *Service) activeClients(ctx context.Context, stageID string) ([]client.Entity, error) {
+	isActive := true
+	clients, err := s.clientRepository.ListAll(ctx, client.Filters{Stage: stageID, IsActive: &isActive})
+	return clients, err
+}
@@ -2,10 +2,15 @@ package track
 
 import (
 	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/reason"
+	"TrackMe/internal/domain/rule"
+	"TrackMe/internal/domain/touchpoint"
+	"TrackMe/internal/domain/transition"
 	"TrackMe/pkg/log"
 	"TrackMe/pkg/store"
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +21,11 @@ type ClientTrackService interface {
 	CreateClient(ctx context.Context, req client.Request) (client.Response, error)
 	UpdateClient(ctx context.Context, id string, req client.Request) (client.Response, error)
 	DeleteClient(ctx context.Context, id string) error
+	AddTouchpoint(ctx context.Context, clientID string, req touchpoint.Request) (touchpoint.Entity, error)
+	ListTouchpoints(ctx context.Context, clientID string) ([]touchpoint.Entity, error)
+	DeactivateClient(ctx context.Context, id string, req client.ActivityRequest) (client.Response, error)
+	ReactivateClient(ctx context.Context, id string, req client.ActivityRequest) (client.Response, error)
+	ReasonTaxonomy(ctx context.Context) reason.Taxonomy
 }
 
 // ListClients retrieves all clients from the repository.
@@ -81,8 +91,13 @@ func (s *Service) CreateClient(ctx context.Context, req client.Request) (client.
 		}
 	}
 
-	if newClient.IsActive != nil {
-		*newClient.IsActive = true
+	// New clients start active, deactivation goes through DeactivateClient with a reason
+	isActive := true
+	newClient.IsActive = &isActive
+
+	if err = s.scoreClient(ctx, &newClient); err != nil {
+		logger.Error().Err(err).Msg("failed to score client")
+		return client.Response{}, err
 	}
 
 	result, err := s.clientRepository.Create(ctx, newClient)
@@ -91,6 +106,12 @@ func (s *Service) CreateClient(ctx context.Context, req client.Request) (client.
 		return client.Response{}, err
 	}
 
+	if req.Stage != "" {
+		s.recordTransition(ctx, result, "", client.Request{})
+	}
+	s.recordTouchpoint(ctx, result, client.Entity{})
+	s.evaluateRules(ctx, rule.TriggerCreated, result, "")
+
 	logger.Info().Str("client_id", result.ID).Msg("client created successfully")
 	return client.ParseFromEntity(result), nil
 }
@@ -142,6 +163,14 @@ func (s *Service) UpdateClient(ctx context.Context, id string, req client.Reques
 	}
 
 	updated.CurrentStage = &newStage
+	if req.ReasonCode != "" || req.Comment != "" {
+		if req.Stage != "prev" {
+			return client.Response{}, fmt.Errorf("%w reason_code: reasons are only given when moving back to a previous stage", store.ErrorInvalid)
+		}
+		if err = s.reasons.Check(reason.Rollback, req.ReasonCode, *existing.CurrentStage); err != nil {
+			return client.Response{}, err
+		}
+	}
 	if req.Stage == "prev" {
 		err := s.calculateRollbackCount(ctx, now)
 		if err != nil {
@@ -149,8 +178,14 @@ func (s *Service) UpdateClient(ctx context.Context, id string, req client.Reques
 		}
 	}
 
-	if updated.IsActive != nil {
-		*updated.IsActive = true
+	// Activity only changes through DeactivateClient and ReactivateClient, tags and the assignee
+	// through rules
+	updated.Tags = existing.Tags
+	updated.AssigneeID = existing.AssigneeID
+	updated.IsActive = existing.IsActive
+	if updated.IsActive == nil {
+		isActive := true
+		updated.IsActive = &isActive
 	}
 	if *updated.Name == "" {
 		*updated.Name = "Guest_" + updated.ID
@@ -158,15 +193,66 @@ func (s *Service) UpdateClient(ctx context.Context, id string, req client.Reques
 
 	updated.LastUpdated = &now
 
+	if err = s.scoreClient(ctx, &updated); err != nil {
+		logger.Error().Err(err).Msg("failed to score client")
+		return client.Response{}, err
+	}
+
 	result, err := s.clientRepository.Update(ctx, id, updated)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to update client")
 		return client.Response{}, err
 	}
 
+	if newStage != *existing.CurrentStage {
+		s.recordTransition(ctx, result, *existing.CurrentStage, req)
+	}
+	s.recordTouchpoint(ctx, result, existing)
+
+	if newStage != *existing.CurrentStage {
+		s.evaluateRules(ctx, rule.TriggerTransitioned, result, *existing.CurrentStage)
+	}
+	s.evaluateRules(ctx, rule.TriggerUpdated, result, *existing.CurrentStage)
+
 	return client.ParseFromEntity(result), nil
 }
 
+// recordTransition stores the event of a client entering its current stage from fromStage, with
+// the reason code and comment of the request. Failures are logged only, events feed analytics and
+// must not block client writes.
+func (s *Service) recordTransition(ctx context.Context, c client.Entity, fromStage string, req client.Request) {
+	if s.transitions == nil || c.CurrentStage == nil {
+		return
+	}
+
+	event := transition.Entity{
+		ClientID:  c.ID,
+		FromStage: fromStage,
+		ToStage:   *c.CurrentStage,
+		Reason:    req.ReasonCode,
+		Comment:   req.Comment,
+		CreatedAt: time.Now(),
+	}
+	if c.LastUpdated != nil {
+		event.CreatedAt = *c.LastUpdated
+	}
+	if c.Source != nil {
+		event.Source = *c.Source
+	}
+	if c.Channel != nil {
+		event.Channel = *c.Channel
+	}
+
+	if err := s.transitions.Add(ctx, event); err != nil {
+		logger := log.LoggerFromContext(ctx)
+		logger.Warn().
+			Err(err).
+			Str("client_id", c.ID).
+			Str("to_stage", event.ToStage).
+			Msg("failed to record stage transition")
+	}
+}
+
 // DeleteClient removes a client from the repository.
 func (s *Service) DeleteClient(ctx context.Context, id string) error {
 	logger := log.LoggerFromContext(ctx).With().
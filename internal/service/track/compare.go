@@ -0,0 +1,70 @@
+package track
+
+import (
+	"TrackMe/internal/domain/metric"
+	"TrackMe/pkg/log"
+	"TrackMe/pkg/store"
+	"context"
+	"fmt"
+)
+
+// maxComparePeriods limits how many periods a comparison may span
+const maxComparePeriods = 60
+
+// CompareMetrics compares the current period of every series of the metric type with the
+// preceding periods. Snapshot metrics are bucketed into the interval's periods as well.
+func (s *Service) CompareMetrics(ctx context.Context, metricType, interval string, periods int) ([]metric.Comparison, error) {
+	logger := log.LoggerFromContext(ctx).With().
+		Str("type", metricType).
+		Str("interval", interval).
+		Str("component", "service.track.metric.compare").
+		Logger()
+
+	if metricType == "" {
+		return nil, fmt.Errorf("%w type: cannot be blank", store.ErrorInvalid)
+	}
+	if !s.calculators.HasType(metricType) {
+		return nil, fmt.Errorf("%w type: %s", store.ErrorInvalid, metricType)
+	}
+	if periods < 2 || periods > maxComparePeriods {
+		return nil, fmt.Errorf("%w periods: must be between 2 and %d", store.ErrorInvalid, maxComparePeriods)
+	}
+
+	window, err := s.recentPeriods(interval, periods)
+	if err != nil {
+		return nil, err
+	}
+
+	// The range is left open-ended so the cache key stays stable within the current period
+	filters := metric.Filters{
+		Type:     metricType,
+		Interval: s.storedInterval(metricType, interval),
+		From:     window[periods-1].Start,
+	}
+
+	var entities []metric.Entity
+	if s.MetricCache != nil {
+		entities, err = s.MetricCache.List(ctx, filters)
+	} else {
+		entities, err = s.MetricRepository.List(ctx, filters)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to list metrics")
+		return nil, err
+	}
+
+	return metric.Compare(metricType, interval, window, entities), nil
+}
+
+// storedInterval returns the interval metrics of the type are stored with when calculated for
+// interval: empty for snapshot calculators, the interval itself otherwise
+func (s *Service) storedInterval(metricType, interval string) string {
+	for _, c := range s.calculators.Calculators() {
+		for _, t := range c.Types() {
+			if string(t) == metricType && metric.IsSnapshot(c) {
+				return ""
+			}
+		}
+	}
+	return interval
+}
@@ -0,0 +1,158 @@
+package track
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/currency"
+	"TrackMe/internal/domain/metric"
+	"TrackMe/pkg/log"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// monthlyAmountField is the numeric client field averaged in the base currency, as the contracts
+// of the clients may be in different currencies
+const monthlyAmountField = "monthly_amount"
+
+// definitionCalculator computes a declarative metric definition over the client repository
+type definitionCalculator struct {
+	service    *Service
+	definition metric.Definition
+}
+
+// newDefinitionCalculator validates the client fields a definition refers to
+func newDefinitionCalculator(s *Service, d metric.Definition) (*definitionCalculator, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, cond := range []metric.Condition{d.Filters, d.Numerator} {
+		for field := range cond {
+			if !slices.Contains(client.Fields, field) {
+				return nil, fmt.Errorf("%s: unknown client field %s (valid values: %v)", d.ID, field, client.Fields)
+			}
+		}
+	}
+	if d.Kind == metric.KindAvg && !slices.Contains(client.NumericFields, d.Field) && !slices.Contains(client.BusinessNumericFields, d.Field) {
+		return nil, fmt.Errorf("%s: unknown numeric field %s (valid values: %v)", d.ID, d.Field, slices.Concat(client.NumericFields, client.BusinessNumericFields))
+	}
+	if d.PeriodField != "" && !slices.Contains(client.DateFields, d.PeriodField) {
+		return nil, fmt.Errorf("%s: unknown date field %s (valid values: %v)", d.ID, d.PeriodField, client.DateFields)
+	}
+
+	return &definitionCalculator{service: s, definition: d}, nil
+}
+
+func (c *definitionCalculator) Name() string { return "definition:" + c.definition.ID }
+func (c *definitionCalculator) Types() []metric.Type {
+	return []metric.Type{metric.Type(c.definition.ID)}
+}
+
+func (c *definitionCalculator) Intervals() []string {
+	if len(c.definition.Intervals) == 0 {
+		return []string{"day", "week", "month"}
+	}
+	return c.definition.Intervals
+}
+
+// Calculate evaluates the definition over every client matching its filters
+func (c *definitionCalculator) Calculate(ctx context.Context, period metric.Period) ([]metric.Entity, error) {
+	d := c.definition
+
+	clients, err := c.service.clientRepository.ListAll(ctx, client.Filters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	var rates currency.Rates
+	if d.Kind == metric.KindAvg && d.Field == monthlyAmountField {
+		if rates, err = c.service.exchangeRates(ctx, period.AsOf); err != nil {
+			return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+		}
+	}
+
+	var matched, numerator int
+	var sum float64
+	var measured, skippedContracts int
+	for _, cl := range clients {
+		if !matchesCondition(cl, d.Filters) || !inPeriod(cl, d.PeriodField, period) {
+			continue
+		}
+		matched++
+
+		switch d.Kind {
+		case metric.KindRatio:
+			if matchesCondition(cl, d.Numerator) {
+				numerator++
+			}
+		case metric.KindAvg:
+			v, ok := cl.NumericField(d.Field, period.AsOf)
+			switch {
+			case d.Field == monthlyAmountField:
+				var skipped int
+				v, skipped = clientMRR(cl, period.AsOf, rates)
+				skippedContracts += skipped
+			case slices.Contains(client.BusinessNumericFields, d.Field):
+				v, ok = cl.BusinessNumericField(d.Field, period.AsOf, c.service.calendar.BusinessDuration)
+			}
+			if ok {
+				sum += v
+				measured++
+			}
+		}
+	}
+
+	if skippedContracts > 0 {
+		logger := log.LoggerFromContext(ctx)
+		logger.Warn().
+			Str("definition", d.ID).
+			Int("skipped_contracts", skippedContracts).
+			Msg("Contracts in currencies without an exchange rate are not counted")
+	}
+
+	var value float64
+	switch d.Kind {
+	case metric.KindCount:
+		value = float64(matched)
+	case metric.KindRatio:
+		if matched > 0 {
+			value = float64(numerator) / float64(matched)
+		}
+	case metric.KindAvg:
+		if measured > 0 {
+			value = sum / float64(measured)
+		}
+	}
+
+	metadata := map[string]string{"kind": d.Kind}
+	if d.Name != "" {
+		metadata["name"] = d.Name
+	}
+
+	m, err := c.service.createMetric("", metric.Type(d.ID), value, period.Interval, period.AsOf, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return []metric.Entity{m}, nil
+}
+
+// matchesCondition reports whether every field of the condition has one of its accepted values
+func matchesCondition(c client.Entity, cond metric.Condition) bool {
+	for field, values := range cond {
+		value, ok := c.Field(field)
+		if !ok || !slices.Contains(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// inPeriod reports whether the client's date field falls within the period up to its as-of time
+func inPeriod(c client.Entity, field string, period metric.Period) bool {
+	if field == "" {
+		return true
+	}
+	t, ok := c.DateField(field)
+	return ok && !t.Before(period.Start) && !t.After(period.AsOf)
+}
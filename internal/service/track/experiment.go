@@ -0,0 +1,260 @@
+package track
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/experiment"
+	"TrackMe/internal/domain/stage"
+	"TrackMe/pkg/log"
+	"TrackMe/pkg/store"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExperimentTrackService interface {
+	CreateExperiment(ctx context.Context, req experiment.Request) (experiment.Entity, error)
+	GetExperiment(ctx context.Context, id string) (experiment.Entity, error)
+	ListExperiments(ctx context.Context) ([]experiment.Entity, error)
+	AssignClient(ctx context.Context, id string, req experiment.AssignRequest) (experiment.Assignment, error)
+	ExperimentResults(ctx context.Context, id string, alpha float64) (experiment.Results, error)
+}
+
+// WithExperimentRepository applies a given experiment repository to the Service
+func WithExperimentRepository(experiments experiment.Repository) Configuration {
+	return func(s *Service) error {
+		s.experiments = experiments
+		return nil
+	}
+}
+
+// CreateExperiment creates an experiment measured on reaching its goal stage, the last stage
+// of the pipeline when none is given
+func (s *Service) CreateExperiment(ctx context.Context, req experiment.Request) (experiment.Entity, error) {
+	if s.experiments == nil {
+		return experiment.Entity{}, errors.New("experiment repository is not configured")
+	}
+
+	stages, err := s.StageRepository.List(ctx)
+	if err != nil {
+		return experiment.Entity{}, err
+	}
+	if len(stages) == 0 {
+		return experiment.Entity{}, fmt.Errorf("%w goal_stage: no stages configured", store.ErrorInvalid)
+	}
+	if req.GoalStage == "" {
+		req.GoalStage = stages[len(stages)-1].ID
+	}
+	if _, ok := stageOrders(stages)[req.GoalStage]; !ok {
+		return experiment.Entity{}, fmt.Errorf("%w goal_stage: %s", store.ErrorInvalid, req.GoalStage)
+	}
+
+	data := experiment.New(req)
+	data.CreatedAt = s.clock()
+
+	return s.experiments.Create(ctx, data)
+}
+
+// GetExperiment retrieves an experiment by ID
+func (s *Service) GetExperiment(ctx context.Context, id string) (experiment.Entity, error) {
+	if s.experiments == nil {
+		return experiment.Entity{}, errors.New("experiment repository is not configured")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return experiment.Entity{}, store.ErrorNotFound
+	}
+
+	return s.experiments.Get(ctx, id)
+}
+
+// ListExperiments retrieves every experiment, newest first
+func (s *Service) ListExperiments(ctx context.Context) ([]experiment.Entity, error) {
+	if s.experiments == nil {
+		return nil, errors.New("experiment repository is not configured")
+	}
+
+	return s.experiments.List(ctx)
+}
+
+// AssignClient places a client in a variant of an experiment. With an explicit variant the
+// previous assignment of the client is replaced; otherwise the client keeps its assignment or
+// is assigned by hashing its ID.
+func (s *Service) AssignClient(ctx context.Context, id string, req experiment.AssignRequest) (experiment.Assignment, error) {
+	data, err := s.GetExperiment(ctx, id)
+	if err != nil {
+		return experiment.Assignment{}, err
+	}
+
+	if _, err = s.clientRepository.Get(ctx, req.ClientID); err != nil {
+		if errors.Is(err, store.ErrorNotFound) {
+			return experiment.Assignment{}, fmt.Errorf("%w client_id: %s", store.ErrorInvalid, req.ClientID)
+		}
+		return experiment.Assignment{}, err
+	}
+
+	assignment := experiment.Assignment{
+		ExperimentID: data.ID,
+		ClientID:     req.ClientID,
+		Variant:      req.Variant,
+		Method:       experiment.MethodManual,
+		AssignedAt:   s.clock(),
+	}
+
+	if req.Variant == "" {
+		existing, err := s.experiments.Assignment(ctx, data.ID, req.ClientID)
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, store.ErrorNotFound) {
+			return experiment.Assignment{}, err
+		}
+		assignment.Variant = data.Variant(req.ClientID)
+		assignment.Method = experiment.MethodHash
+	} else if !data.HasVariant(req.Variant) {
+		return experiment.Assignment{}, fmt.Errorf("%w variant: %s", store.ErrorInvalid, req.Variant)
+	}
+
+	return s.experiments.Assign(ctx, assignment)
+}
+
+// ExperimentResults computes the funnel conversion and stage durations of every variant of an
+// experiment from what happened to its clients after their assignment, and tests the conversion
+// of every variant against the control at the alpha significance level
+func (s *Service) ExperimentResults(ctx context.Context, id string, alpha float64) (experiment.Results, error) {
+	logger := log.LoggerFromContext(ctx).With().
+		Str("experiment_id", id).
+		Str("component", "service.track.experiment").
+		Logger()
+
+	if alpha <= 0 || alpha >= 1 {
+		return experiment.Results{}, fmt.Errorf("%w alpha: must be between 0 and 1", store.ErrorInvalid)
+	}
+
+	data, err := s.GetExperiment(ctx, id)
+	if err != nil {
+		return experiment.Results{}, err
+	}
+
+	assignments, err := s.experiments.Assignments(ctx, data.ID)
+	if err != nil {
+		return experiment.Results{}, err
+	}
+
+	stages, err := s.StageRepository.List(ctx)
+	if err != nil {
+		return experiment.Results{}, err
+	}
+
+	now := s.clock()
+	outcomes, err := s.experimentOutcomes(ctx, stages, assignments, now)
+	if err != nil {
+		return experiment.Results{}, err
+	}
+
+	order := stageOrders(stages)
+	funnel := make([]experiment.Stage, 0, len(stages))
+	for _, node := range flowNodes(stages, order) {
+		funnel = append(funnel, experiment.Stage{ID: node.ID, Name: node.Name, Order: node.Order})
+	}
+
+	res := experiment.Analyze(data, funnel, assignments, outcomes, alpha, now.In(s.location))
+
+	logger.Info().
+		Int("assignments", len(assignments)).
+		Int("variants", len(res.Variants)).
+		Msg("Experiment results")
+
+	return res, nil
+}
+
+// experimentOutcomes returns per assigned client the furthest stage it was in since its
+// assignment and the time it spent in the stages it entered after it. Transition events are used
+// for clients that have them; for the others the current stage of the client and the time it
+// spent there since its last update are used.
+func (s *Service) experimentOutcomes(ctx context.Context, stages []stage.Entity, assignments []experiment.Assignment, now time.Time) (map[string]experiment.Outcome, error) {
+	order := stageOrders(stages)
+
+	assignedAt := make(map[string]time.Time, len(assignments))
+	for _, a := range assignments {
+		assignedAt[a.ClientID] = a.AssignedAt
+	}
+
+	res := make(map[string]experiment.Outcome, len(assignments))
+	outcome := func(clientID string) experiment.Outcome {
+		o, ok := res[clientID]
+		if !ok {
+			o = experiment.Outcome{Stays: make(map[string][]float64)}
+		}
+		return o
+	}
+
+	clients, err := s.clientRepository.ListAll(ctx, client.Filters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	withEvents, err := s.clientsWithTransitions(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(withEvents) > 0 {
+		// The stage a client was in at its assignment counts as reached as well, so the whole
+		// history of the client is needed
+		events, err := s.transitions.List(ctx, time.Time{}, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stage transitions: %w", err)
+		}
+
+		current := make(map[string]int)
+		for _, e := range events {
+			at, ok := assignedAt[e.ClientID]
+			if !ok {
+				continue
+			}
+			o := outcome(e.ClientID)
+			if e.CreatedAt.After(at) {
+				o.Reached = max(o.Reached, order[e.ToStage])
+			} else {
+				current[e.ClientID] = order[e.ToStage]
+			}
+			res[e.ClientID] = o
+		}
+		for clientID, o := range res {
+			o.Reached = max(o.Reached, current[clientID])
+			res[clientID] = o
+		}
+
+		stays, err := s.transitions.Stays(ctx, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stage stays: %w", err)
+		}
+		for _, st := range stays {
+			at, ok := assignedAt[st.ClientID]
+			if !ok || st.EnteredAt.Before(at) {
+				continue
+			}
+			o := outcome(st.ClientID)
+			o.Stays[st.Stage] = append(o.Stays[st.Stage], st.LeftAt.Sub(st.EnteredAt).Hours())
+			res[st.ClientID] = o
+		}
+	}
+
+	// Clients without events, such as clients last updated before transitions were recorded, fall
+	// back to their current stage
+	for _, c := range clients {
+		at, ok := assignedAt[c.ID]
+		if !ok || withEvents[c.ID] || c.CurrentStage == nil {
+			continue
+		}
+		o := outcome(c.ID)
+		o.Reached = order[*c.CurrentStage]
+		if c.LastUpdated != nil && !c.LastUpdated.Before(at) {
+			o.Stays[*c.CurrentStage] = []float64{now.Sub(*c.LastUpdated).Hours()}
+		}
+		res[c.ID] = o
+	}
+	return res, nil
+}
@@ -0,0 +1,148 @@
+package track
+
+import (
+	"TrackMe/internal/domain/metric"
+	"TrackMe/pkg/log"
+	"TrackMe/pkg/store"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// maxGoalPeriods limits how many periods the progress of a goal may span
+const maxGoalPeriods = 60
+
+// WithGoalRepository applies a given metric goal repository to the Service
+func WithGoalRepository(goals metric.GoalRepository) Configuration {
+	return func(s *Service) error {
+		s.goals = goals
+		return nil
+	}
+}
+
+// CreateGoal sets a target for a metric type per day, week or month
+func (s *Service) CreateGoal(ctx context.Context, req metric.GoalRequest) (metric.Goal, error) {
+	if s.goals == nil {
+		return metric.Goal{}, errors.New("goal repository is not configured")
+	}
+	if !s.calculators.HasType(req.Type) {
+		return metric.Goal{}, fmt.Errorf("%w type: %s", store.ErrorInvalid, req.Type)
+	}
+
+	data := metric.NewGoal(req)
+	data.CreatedAt = s.clock()
+
+	return s.goals.Create(ctx, data)
+}
+
+// DeleteGoal removes a goal
+func (s *Service) DeleteGoal(ctx context.Context, id string) error {
+	if s.goals == nil {
+		return errors.New("goal repository is not configured")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return store.ErrorNotFound
+	}
+
+	return s.goals.Delete(ctx, id)
+}
+
+// ListGoals returns every goal with, per series of its metric, the standing in the current period
+// including a projection to its end, and the misses among the periods-1 closed periods before it
+func (s *Service) ListGoals(ctx context.Context, periods int) ([]metric.GoalProgress, error) {
+	if s.goals == nil {
+		return nil, errors.New("goal repository is not configured")
+	}
+	if periods < 1 || periods > maxGoalPeriods {
+		return nil, fmt.Errorf("%w periods: must be between 1 and %d", store.ErrorInvalid, maxGoalPeriods)
+	}
+
+	goals, err := s.goals.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock()
+	res := make([]metric.GoalProgress, 0, len(goals))
+	for _, g := range goals {
+		window, err := s.recentPeriods(g.Interval, periods)
+		if err != nil {
+			return nil, err
+		}
+
+		entities, err := s.goalMetrics(ctx, g, window[len(window)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, g.Progress(window, entities, now))
+	}
+
+	return res, nil
+}
+
+// annotateGoals sets the goal status of every response a goal applies to. Failures are logged
+// only, the metrics are returned without goals.
+func (s *Service) annotateGoals(ctx context.Context, responses []metric.Response) {
+	if s.goals == nil || len(responses) == 0 {
+		return
+	}
+
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.goal").Logger()
+
+	goals, err := s.goals.List(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to list metric goals")
+		return
+	}
+
+	now := s.clock()
+	for _, g := range goals {
+		current, err := s.periodOf(g.Interval, now)
+		if err != nil {
+			continue
+		}
+
+		recent, err := s.goalMetrics(ctx, g, current)
+		if err != nil {
+			logger.Warn().Err(err).Str("goal_id", g.ID).Msg("failed to list goal metrics")
+			continue
+		}
+
+		g.Annotate(responses, current, recent, now)
+	}
+}
+
+// goalMetrics lists the metrics a goal applies to created since the start of the period
+func (s *Service) goalMetrics(ctx context.Context, g metric.Goal, since metric.Period) ([]metric.Entity, error) {
+	filters := metric.Filters{
+		Type:     g.Type,
+		Interval: s.storedInterval(g.Type, g.Interval),
+		From:     since.Start,
+		Metadata: g.Metadata,
+	}
+
+	var (
+		entities []metric.Entity
+		err      error
+	)
+	if s.MetricCache != nil {
+		entities, err = s.MetricCache.List(ctx, filters)
+	} else {
+		entities, err = s.MetricRepository.List(ctx, filters)
+	}
+	if err != nil && !errors.Is(err, store.ErrorNotFound) {
+		return nil, err
+	}
+
+	// Responses carry creation times in the reporting timezone, periods are compared in it as well
+	for i := range entities {
+		if entities[i].CreatedAt != nil {
+			t := entities[i].CreatedAt.In(s.location)
+			entities[i].CreatedAt = &t
+		}
+	}
+	return entities, nil
+}
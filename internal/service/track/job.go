@@ -0,0 +1,181 @@
+package track
+
+import (
+	"TrackMe/internal/domain/job"
+	"TrackMe/pkg/log"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// metricJobTimeout bounds a single metric calculation job and backfillJobTimeout a backfill.
+// Unfinished jobs older than their timeout are considered abandoned (e.g. after a restart) and
+// no longer block their interval.
+const (
+	metricJobTimeout   = 30 * time.Minute
+	backfillJobTimeout = 6 * time.Hour
+)
+
+// StartMetricJob creates a metric calculation job for the interval and runs it in the background.
+// It fails with store.ErrorAlreadyExists while another job for the interval is pending or running.
+func (s *Service) StartMetricJob(ctx context.Context, req job.Request) (job.Response, error) {
+	data, err := s.createMetricJob(ctx, req)
+	if err != nil {
+		return job.Response{}, err
+	}
+
+	// The job outlives the request that started it
+	go func() {
+		runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), metricJobTimeout)
+		defer cancel()
+
+		_ = s.runMetricJob(runCtx, data)
+	}()
+
+	return job.ParseFromEntity(data), nil
+}
+
+// RunMetricJob creates a metric calculation job for the interval and runs it to completion.
+// A zero asOf means the time the job starts.
+func (s *Service) RunMetricJob(ctx context.Context, interval string, asOf time.Time) error {
+	data, err := s.createMetricJob(ctx, job.Request{Interval: interval, AsOf: asOf})
+	if err != nil {
+		return err
+	}
+
+	return s.runMetricJob(ctx, data)
+}
+
+// GetMetricJob retrieves a metric calculation job by ID
+func (s *Service) GetMetricJob(ctx context.Context, id string) (job.Response, error) {
+	if s.jobRepository == nil {
+		return job.Response{}, errors.New("job repository is not configured")
+	}
+
+	data, err := s.jobRepository.Get(ctx, id)
+	if err != nil {
+		return job.Response{}, err
+	}
+
+	return job.ParseFromEntity(data), nil
+}
+
+// ListMetricJobs retrieves the history of metric calculation jobs, newest first
+func (s *Service) ListMetricJobs(ctx context.Context, limit, offset int) ([]job.Response, int, error) {
+	if s.jobRepository == nil {
+		return nil, 0, errors.New("job repository is not configured")
+	}
+
+	data, total, err := s.jobRepository.List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return job.ParseFromEntities(data), total, nil
+}
+
+// createMetricJob persists a pending job with one pending step per calculation of the interval
+func (s *Service) createMetricJob(ctx context.Context, req job.Request) (job.Entity, error) {
+	if err := req.Bind(nil); err != nil {
+		return job.Entity{}, err
+	}
+
+	data := job.New(req)
+	for _, c := range s.calculators.For(req.Interval) {
+		data.Steps = append(data.Steps, job.Step{Name: c.Name(), Status: job.StatusPending})
+	}
+
+	return s.createJob(ctx, data, metricJobTimeout)
+}
+
+// createJob persists a pending job expiring after timeout. It fails with store.ErrorAlreadyExists
+// while another job for the interval is pending or running.
+func (s *Service) createJob(ctx context.Context, data job.Entity, timeout time.Duration) (job.Entity, error) {
+	if s.jobRepository == nil {
+		return job.Entity{}, errors.New("job repository is not configured")
+	}
+
+	// Release the interval held by jobs that can no longer be running
+	now := s.clock()
+	if err := s.jobRepository.ExpireActive(ctx, data.Interval, now); err != nil {
+		return job.Entity{}, fmt.Errorf("failed to expire stale jobs: %w", err)
+	}
+
+	data.CreatedAt = now
+	data.ExpiresAt = now.Add(timeout)
+	return s.jobRepository.Create(ctx, data)
+}
+
+// runMetricJob calculates the metrics of a created job
+func (s *Service) runMetricJob(ctx context.Context, data job.Entity) error {
+	var asOf time.Time
+	if data.AsOf != nil {
+		asOf = *data.AsOf
+	}
+
+	return s.runJob(ctx, data, func(ctx context.Context, progress stepProgress) error {
+		return s.calculateMetrics(ctx, data.Interval, s.asOf(asOf), data.ID, progress, true)
+	})
+}
+
+// runJob runs the work of a created job, persisting its state whenever a step changes status
+func (s *Service) runJob(ctx context.Context, data job.Entity, work func(ctx context.Context, progress stepProgress) error) error {
+	logger := log.LoggerFromContext(ctx).With().
+		Str("component", "service.track.job").
+		Str("job_id", data.ID).
+		Str("interval", data.Interval).
+		Bool("backfill", data.IsBackfill()).
+		Logger()
+
+	// State updates must land even when the calculation context is cancelled
+	saveCtx := context.WithoutCancel(ctx)
+	save := func() {
+		if _, err := s.jobRepository.Update(saveCtx, data.ID, data); err != nil {
+			logger.Error().Err(err).Msg("failed to update metric job")
+		}
+	}
+
+	startedAt := s.clock()
+	data.Status = job.StatusRunning
+	data.StartedAt = &startedAt
+	save()
+
+	progress := func(name, status string, err error) {
+		now := s.clock()
+		for i := range data.Steps {
+			if data.Steps[i].Name != name {
+				continue
+			}
+			data.Steps[i].Status = status
+			if status == job.StatusRunning {
+				data.Steps[i].StartedAt = &now
+			} else {
+				data.Steps[i].FinishedAt = &now
+			}
+			if err != nil {
+				data.Steps[i].Error = err.Error()
+			}
+		}
+		save()
+	}
+
+	err := work(ctx, progress)
+
+	finishedAt := s.clock()
+	data.FinishedAt = &finishedAt
+	data.Status = job.StatusSucceeded
+	if err != nil {
+		data.Status = job.StatusFailed
+		data.Error = err.Error()
+	}
+	save()
+
+	if err != nil {
+		logger.Error().Err(err).Msg("metric job failed")
+		return err
+	}
+
+	logger.Info().Dur("duration", finishedAt.Sub(startedAt)).Msg("metric job succeeded")
+	return nil
+}
@@ -1,8 +1,14 @@
 package track
 
 import (
+	"TrackMe/internal/domain/alert"
+	"TrackMe/internal/domain/annotation"
 	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/job"
 	"TrackMe/internal/domain/metric"
+	"TrackMe/internal/domain/touchpoint"
+	"TrackMe/internal/domain/transition"
+	"TrackMe/internal/metrics"
 	"TrackMe/pkg/log"
 	"TrackMe/pkg/store"
 	"context"
@@ -17,7 +23,24 @@ import (
 // Define an interface that matches the methods used by MetricHandler
 type MetricTrackService interface {
 	ListMetrics(ctx context.Context, filters metric.Filters) ([]metric.Response, error)
-	CalculateAllMetrics(ctx context.Context, interval string) error
+	CompareMetrics(ctx context.Context, metricType, interval string, periods int) ([]metric.Comparison, error)
+	CalculateAllMetrics(ctx context.Context, interval string, asOf time.Time) error
+	BackfillMetrics(ctx context.Context, req job.BackfillRequest) (job.Response, error)
+	StartMetricJob(ctx context.Context, req job.Request) (job.Response, error)
+	GetMetricJob(ctx context.Context, id string) (job.Response, error)
+	ListMetricJobs(ctx context.Context, limit, offset int) ([]job.Response, int, error)
+	ListMetricRuns(ctx context.Context, interval string, limit, offset int) ([]metric.Run, int, error)
+	ListAlerts(ctx context.Context, metricType string, limit, offset int) ([]alert.Entity, int, error)
+	CreateGoal(ctx context.Context, req metric.GoalRequest) (metric.Goal, error)
+	ListGoals(ctx context.Context, periods int) ([]metric.GoalProgress, error)
+	DeleteGoal(ctx context.Context, id string) error
+	CreateAnnotation(ctx context.Context, req annotation.Request, userID string) (annotation.Entity, error)
+	GetAnnotation(ctx context.Context, id string) (annotation.Entity, error)
+	ListAnnotations(ctx context.Context, filters annotation.Filters) ([]annotation.Entity, error)
+	UpdateAnnotation(ctx context.Context, id string, req annotation.Request) (annotation.Entity, error)
+	DeleteAnnotation(ctx context.Context, id string) error
+	MetricAnnotations(ctx context.Context, filters metric.Filters) ([]annotation.Entity, error)
+	Location() *time.Location
 }
 
 // ListMetrics retrieves all metric from the repository.
@@ -27,6 +50,25 @@ func (s *Service) ListMetrics(ctx context.Context, filters metric.Filters) ([]me
 		Str("component", "service.client").
 		Logger()
 
+	if filters.Type != "" && !s.calculators.HasType(filters.Type) {
+		return nil, fmt.Errorf("%w type: %s", store.ErrorInvalid, filters.Type)
+	}
+
+	if !filters.From.IsZero() && !filters.To.IsZero() && filters.To.Before(filters.From) {
+		return nil, fmt.Errorf("%w range: from must not be after to", store.ErrorInvalid)
+	}
+
+	// Attributed conversions are stored per model, last-touch is returned unless another is requested.
+	// Rows calculated before attribution models existed carry no model and count as last-touch.
+	if filters.Type == string(metric.SourceConversion) || filters.Type == string(metric.ChannelConversion) {
+		model := filters.Metadata["model"]
+		if model == "" {
+			filters.MetadataDefaults = map[string]string{"model": touchpoint.LastTouch}
+		} else if err := touchpoint.ValidModel(model); err != nil {
+			return nil, err
+		}
+	}
+
 	var entities []metric.Entity
 	var err error
 
@@ -35,7 +77,8 @@ func (s *Service) ListMetrics(ctx context.Context, filters metric.Filters) ([]me
 		entities, err = s.MetricCache.List(ctx, filters)
 		if err == nil {
 			logger.Debug().Msg("metrics retrieved from cache")
-			responses := metric.ParseFromEntities(entities)
+			responses := s.metricResponses(entities)
+			s.annotateGoals(ctx, responses)
 			return responses, nil
 		}
 		// Log cache miss but continue with repository
@@ -59,126 +102,190 @@ func (s *Service) ListMetrics(ctx context.Context, filters metric.Filters) ([]me
 		}(ctx, filters, entities)
 	}
 
-	responses := metric.ParseFromEntities(entities)
+	responses := s.metricResponses(entities)
+	s.annotateGoals(ctx, responses)
 	return responses, nil
 }
 
-func (s *Service) CalculateAllMetrics(ctx context.Context, interval string) error {
-	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric").Logger()
-	now := time.Now()
-
-	if err := s.calculateClientsPerStage(ctx, now); err != nil {
-		logger.Error().Err(err).Msg("failed to calculate clients per stage")
-		return err
+// metricResponses converts metrics to responses with creation times in the reporting timezone
+func (s *Service) metricResponses(entities []metric.Entity) []metric.Response {
+	responses := metric.ParseFromEntities(entities)
+	for i := range responses {
+		responses[i].CreatedAt = responses[i].CreatedAt.In(s.location)
 	}
+	return responses
+}
 
-	if err := s.calculateStageDuration(ctx, now); err != nil {
-		logger.Error().Err(err).Msg("failed to calculate stage duration")
-		return err
-	}
+// CalculateAllMetrics calculates and stores every metric for the interval as of the given
+// timestamp. A zero asOf means the current time of the service clock.
+func (s *Service) CalculateAllMetrics(ctx context.Context, interval string, asOf time.Time) error {
+	return s.calculateMetrics(ctx, interval, s.asOf(asOf), "", nil, true)
+}
 
-	if interval != "day" {
-		if err := s.aggregateRollBackCount(ctx, now, interval); err != nil {
-			logger.Error().Err(err).Msg("failed to aggregate rollback count")
-			return err
-		}
-	}
+// MetricIntervals returns the intervals at least one registered calculator runs for
+func (s *Service) MetricIntervals() []string {
+	return s.calculators.Intervals()
+}
 
-	if err := s.calculateDropout(ctx, now, interval); err != nil {
-		logger.Error().Err(err).Msg("failed to calculate dropout")
-		return err
+// Location returns the reporting timezone of metric periods and scheduled calculations
+func (s *Service) Location() *time.Location {
+	return s.location
+}
+
+// asOf returns the timestamp a calculation runs at in the reporting timezone, defaulting to the
+// service clock
+func (s *Service) asOf(t time.Time) time.Time {
+	if t.IsZero() {
+		t = s.clock()
 	}
+	return t.In(s.location)
+}
 
-	if err := s.calculateConversion(ctx, now, interval); err != nil {
-		logger.Error().Err(err).Msg("failed to calculate conversion")
-		return err
+// periodOf returns the period of the interval that contains t, with boundaries at midnight of
+// the reporting timezone
+func (s *Service) periodOf(interval string, t time.Time) (metric.Period, error) {
+	return metric.PeriodOf(interval, t.In(s.location))
+}
+
+// recentPeriods returns the current period of the interval and the n-1 periods preceding it,
+// newest first
+func (s *Service) recentPeriods(interval string, n int) ([]metric.Period, error) {
+	current, err := s.periodOf(interval, s.clock())
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.calculateTotalDuration(ctx, now); err != nil {
-		logger.Error().Err(err).Msg("failed to calculate total duration")
-		return err
+	periods := make([]metric.Period, n)
+	periods[0] = current
+	for i := 1; i < n; i++ {
+		if periods[i], err = s.periodOf(interval, periods[i-1].Start.Add(-1)); err != nil {
+			return nil, err
+		}
 	}
+	return periods, nil
+}
 
-	if err := s.calculateStatusUpdates(ctx, now, interval); err != nil {
-		logger.Error().Err(err).Msg("failed to calculate status updates")
-		return err
+// stepProgress is notified when a calculation step changes status; err is set when it failed
+type stepProgress func(name, status string, err error)
+
+// storeCalculated stores the metrics a calculator returned, completing missing IDs, intervals,
+// creation times and metadata
+func (s *Service) storeCalculated(ctx context.Context, c metric.Calculator, period metric.Period, entities []metric.Entity) error {
+	interval := period.Interval
+	if metric.IsSnapshot(c) {
+		interval = ""
 	}
 
-	if interval == "day" {
-		if err := s.calculateDAU(ctx, now); err != nil {
-			logger.Error().Err(err).Msg("failed to calculate DAU")
-			return err
+	for i := range entities {
+		m := &entities[i]
+		if m.Type == nil || m.Value == nil {
+			return fmt.Errorf("calculator %s returned a metric without type or value", c.Name())
 		}
-	}
-	if interval == "month" {
-		if err := s.calculateMAU(ctx, now); err != nil {
-			logger.Error().Err(err).Msg("failed to calculate MAU")
-			return err
+		if m.ID == "" {
+			m.ID = uuid.New().String()
+		}
+		if m.Interval == nil {
+			m.Interval = &interval
+		}
+		if m.CreatedAt == nil {
+			asOf := period.AsOf
+			m.CreatedAt = &asOf
+		}
+		if m.Metadata == nil {
+			m.Metadata = make(map[string]string)
+		}
+		if _, ok := m.Metadata["timezone"]; !ok {
+			m.Metadata["timezone"] = s.location.String()
 		}
 	}
 
-	if err := s.calculateSourceConversion(ctx, now, interval); err != nil {
-		logger.Error().Err(err).Msg("failed to calculate source conversion")
-		return err
+	// Rows are only stored once every metric is complete, so an invalid one stores nothing
+	for _, m := range entities {
+		if _, err := s.MetricRepository.Add(ctx, m); err != nil {
+			return fmt.Errorf("failed to store %s metric: %w", *m.Type, err)
+		}
 	}
 
-	if err := s.calculateChannelConversion(ctx, now, interval); err != nil {
-		logger.Error().Err(err).Msg("failed to calculate channel conversion")
-		return err
-	}
+	return nil
+}
+
+// calculateMetrics runs every calculation step for the interval at timestamp, reporting each
+// step to progress when it is set. A failing step does not stop the steps after it; the run
+// summary is stored, the affected metric caches are invalidated and the step errors are joined.
+// Anomalies are detected only when detect is set and the period is the current one, so
+// recalculating past periods never raises alerts.
+func (s *Service) calculateMetrics(ctx context.Context, interval string, timestamp time.Time, jobID string, progress stepProgress, detect bool) error {
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric").Logger()
 
-	if err := s.calculateAppInstallRate(ctx, now); err != nil {
-		logger.Error().Err(err).Msg("failed to calculate app install rate")
+	period, err := s.periodOf(interval, timestamp)
+	if err != nil {
 		return err
 	}
 
-	if err := s.calculateAutoPaymentRate(ctx, now); err != nil {
-		logger.Error().Err(err).Msg("failed to calculate auto payment rate")
-		return err
+	run := metric.Run{
+		Interval:  interval,
+		AsOf:      timestamp,
+		StartedAt: s.clock(),
+	}
+	if jobID != "" {
+		run.JobID = &jobID
 	}
 
-	// At the end of CalculateAllMetrics function
-	if s.MetricCache != nil {
-		// Define metric types and intervals that need invalidation
-		metricsToInvalidate := []struct {
-			Type     string
-			Interval string
-		}{
-			{string(metric.RollbackCount), interval},
-			{string(metric.ClientsPerStage), ""},
-			{string(metric.StageDuration), ""},
-			{string(metric.Dropout), interval},
-			{string(metric.Conversion), interval},
-			{string(metric.TotalDuration), ""},
-			{string(metric.StatusUpdates), interval},
-			{string(metric.SourceConversion), interval},
-			{string(metric.ChannelConversion), interval},
-			{string(metric.AppInstallRate), ""},
-			{string(metric.AutoPaymentRate), ""},
+	var (
+		errs   []error
+		stored []metric.Entity
+	)
+	for _, c := range s.calculators.For(interval) {
+		name := c.Name()
+		if progress != nil {
+			progress(name, job.StatusRunning, nil)
 		}
 
-		// Add DAU/MAU based on interval
-		switch interval {
-		case "day":
-			metricsToInvalidate = append(metricsToInvalidate, struct {
-				Type     string
-				Interval string
-			}{string(metric.DAU), "day"})
-		case "month":
-			metricsToInvalidate = append(metricsToInvalidate, struct {
-				Type     string
-				Interval string
-			}{string(metric.MAU), "month"})
+		result, entities, err := s.runStep(ctx, c, period)
+		run.Results = append(run.Results, result)
+
+		if err != nil {
+			logger.Error().Err(err).Str("step", name).Int("attempts", result.Attempts).Msg("failed to calculate metric")
+			// A failed calculator counts as a failure of every metric type it produces
+			for _, t := range c.Types() {
+				metrics.RecordMetricCalculationFailure(string(t))
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			if progress != nil {
+				progress(name, job.StatusFailed, err)
+			}
+			continue
 		}
 
-		// Invalidate all affected caches
-		for _, m := range metricsToInvalidate {
-			if err := s.MetricCache.InvalidateListCache(ctx, metric.Filters{
+		stored = append(stored, entities...)
+		if progress != nil {
+			progress(name, job.StatusSucceeded, nil)
+		}
+	}
+
+	// Bookkeeping must happen even when the calculation context expired
+	saveCtx := context.WithoutCancel(ctx)
+
+	run.FinishedAt = s.clock()
+	run.Status = run.Summarize()
+	if s.runRepository != nil {
+		if _, err := s.runRepository.Add(saveCtx, run); err != nil {
+			logger.Error().Err(err).Msg("failed to store metric run")
+		}
+	}
+
+	if detect && period.Contains(run.StartedAt) {
+		s.detectAnomalies(saveCtx, stored)
+	}
+
+	// Invalidate all affected caches, including after partial failures
+	if s.MetricCache != nil {
+		for _, m := range s.calculatedMetrics(interval) {
+			if err := s.MetricCache.InvalidateListCache(saveCtx, metric.Filters{
 				Type:     m.Type,
 				Interval: m.Interval,
 			}); err != nil {
-				fromContext := log.LoggerFromContext(ctx)
-				fromContext.Warn().
+				logger.Warn().
 					Str("type", m.Type).
 					Str("interval", m.Interval).
 					Err(err).
@@ -187,11 +294,253 @@ func (s *Service) CalculateAllMetrics(ctx context.Context, interval string) erro
 		}
 	}
 
+	return errors.Join(errs...)
+}
+
+// ListMetricRuns retrieves the summaries of past calculation runs, newest first
+func (s *Service) ListMetricRuns(ctx context.Context, interval string, limit, offset int) ([]metric.Run, int, error) {
+	if s.runRepository == nil {
+		return nil, 0, errors.New("metric run repository is not configured")
+	}
+
+	return s.runRepository.List(ctx, interval, limit, offset)
+}
+
+// runStep runs the calculator of a step, bounding every attempt by the step timeout and retrying
+// a failed attempt up to the configured number of times with a linear backoff. The metrics of the
+// successful attempt are stored once: retrying a partially stored step would duplicate its rows.
+func (s *Service) runStep(ctx context.Context, c metric.Calculator, period metric.Period) (metric.RunResult, []metric.Entity, error) {
+	result := metric.RunResult{
+		Name:      c.Name(),
+		StartedAt: s.clock(),
+	}
+
+	var (
+		entities []metric.Entity
+		err      error
+	)
+	for attempt := 0; attempt <= s.stepRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+		if ctx.Err() != nil {
+			if err == nil {
+				err = ctx.Err()
+			}
+			break
+		}
+
+		result.Attempts++
+		stepCtx, cancel := context.WithTimeout(ctx, s.stepTimeout)
+		entities, err = c.Calculate(stepCtx, period)
+		cancel()
+
+		if err == nil {
+			break
+		}
+	}
+
+	if err == nil {
+		stepCtx, cancel := context.WithTimeout(ctx, s.stepTimeout)
+		err = s.storeCalculated(stepCtx, c, period, entities)
+		cancel()
+	}
+
+	result.FinishedAt = s.clock()
+	result.DurationMs = result.FinishedAt.Sub(result.StartedAt).Milliseconds()
+	result.Status = metric.RunSucceeded
+	if err != nil {
+		result.Status = metric.RunFailed
+		result.Error = err.Error()
+		entities = nil
+	}
+
+	return result, entities, err
+}
+
+// calculatedMetric identifies the metric rows a CalculateAllMetrics run stores.
+// Snapshot metrics are stored with an empty interval.
+type calculatedMetric struct {
+	Type     string
+	Interval string
+}
+
+// calculatedMetrics returns the metric types and intervals a run of the registered calculators stores for interval
+func (s *Service) calculatedMetrics(interval string) []calculatedMetric {
+	var metrics []calculatedMetric
+	for _, c := range s.calculators.For(interval) {
+		stored := interval
+		if metric.IsSnapshot(c) {
+			stored = ""
+		}
+		for _, t := range c.Types() {
+			metrics = append(metrics, calculatedMetric{string(t), stored})
+		}
+	}
+	return metrics
+}
+
+// maxBackfillPeriods limits how many periods a single backfill may recompute
+const maxBackfillPeriods = 400
+
+// BackfillMetrics creates a job recomputing metrics for every interval period between from and to
+// (inclusive) and runs it in the background, one step per period. Rows previously stored for a
+// period are replaced, so repeating a backfill is idempotent. Like a calculation job, it fails with
+// store.ErrorAlreadyExists while another job for the interval is pending or running.
+func (s *Service) BackfillMetrics(ctx context.Context, req job.BackfillRequest) (job.Response, error) {
+	if err := req.Bind(nil); err != nil {
+		return job.Response{}, err
+	}
+
+	periods, err := s.backfillPeriods(req)
+	if err != nil {
+		return job.Response{}, err
+	}
+
+	data := job.NewBackfill(req)
+	for _, p := range periods {
+		data.Steps = append(data.Steps, job.Step{Name: backfillStep(p), Status: job.StatusPending})
+	}
+
+	data, err = s.createJob(ctx, data, backfillJobTimeout)
+	if err != nil {
+		return job.Response{}, err
+	}
+
+	// The job outlives the request that started it
+	go func() {
+		runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backfillJobTimeout)
+		defer cancel()
+
+		_ = s.runBackfill(runCtx, data, periods)
+	}()
+
+	return job.ParseFromEntity(data), nil
+}
+
+// backfillPeriods returns the periods a backfill recomputes, up to the current one. Closed periods
+// are calculated as of their last second, the current one as of now.
+func (s *Service) backfillPeriods(req job.BackfillRequest) ([]metric.Period, error) {
+	first, err := s.periodOf(req.Interval, req.From)
+	if err != nil {
+		return nil, err
+	}
+	last, err := s.periodOf(req.Interval, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock()
+	var periods []metric.Period
+	for p := first; !p.Start.After(last.Start) && p.Start.Before(now); p = p.Next() {
+		if len(periods) == maxBackfillPeriods {
+			return nil, fmt.Errorf("%w range: more than %d %s periods", store.ErrorInvalid, maxBackfillPeriods, req.Interval)
+		}
+		p.AsOf = p.Last()
+		if p.AsOf.After(now) {
+			p.AsOf = now
+		}
+		periods = append(periods, p)
+	}
+
+	return periods, nil
+}
+
+// backfillStep names the job step of a backfilled period after its first day
+func backfillStep(p metric.Period) string {
+	return p.Start.Format("2006-01-02")
+}
+
+// runBackfill recalculates the periods of a created backfill job. A failing period does not stop
+// the periods after it.
+func (s *Service) runBackfill(ctx context.Context, data job.Entity, periods []metric.Period) error {
+	logger := log.LoggerFromContext(ctx).With().
+		Str("job_id", data.ID).
+		Str("interval", data.Interval).
+		Str("component", "service.track.metric.backfill").
+		Logger()
+
+	return s.runJob(ctx, data, func(ctx context.Context, progress stepProgress) error {
+		var errs []error
+		for _, p := range periods {
+			step := backfillStep(p)
+			progress(step, job.StatusRunning, nil)
+
+			err := s.deleteCalculatedMetrics(ctx, p)
+			if err == nil {
+				err = s.calculateMetrics(ctx, p.Interval, p.AsOf, data.ID, nil, false)
+			}
+			if err != nil {
+				logger.Error().Err(err).Time("period", p.Start).Msg("failed to recalculate metrics")
+				errs = append(errs, fmt.Errorf("%s: %w", step, err))
+				progress(step, job.StatusFailed, err)
+				continue
+			}
+
+			logger.Info().Time("period", p.Start).Time("as_of", p.AsOf).Msg("period recalculated")
+			progress(step, job.StatusSucceeded, nil)
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// deleteCalculatedMetrics removes the rows a CalculateAllMetrics run for the period stores.
+// Interval metrics are removed for the whole period, snapshots only at the period's as-of timestamp.
+func (s *Service) deleteCalculatedMetrics(ctx context.Context, p metric.Period) error {
+	var intervalTypes, snapshotTypes []string
+	for _, m := range s.calculatedMetrics(p.Interval) {
+		if m.Interval == "" {
+			snapshotTypes = append(snapshotTypes, m.Type)
+		} else {
+			intervalTypes = append(intervalTypes, m.Type)
+		}
+	}
+
+	if err := s.MetricRepository.DeleteRange(ctx, intervalTypes, p.Interval, p.Start, p.End); err != nil {
+		return fmt.Errorf("failed to delete %s metrics: %w", p.Interval, err)
+	}
+
+	if err := s.MetricRepository.DeleteRange(ctx, snapshotTypes, "", p.AsOf, p.AsOf.Add(time.Second)); err != nil {
+		return fmt.Errorf("failed to delete snapshot metrics: %w", err)
+	}
+
 	return nil
 }
 
-func (s *Service) calculateDAU(ctx context.Context, timestamp time.Time) error {
-	startOfDay := time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, timestamp.Location())
+// builtinCalculators returns the calculators every service runs, in execution order
+func (s *Service) builtinCalculators() []metric.Calculator {
+	allIntervals := []string{"day", "week", "month"}
+
+	return []metric.Calculator{
+		metric.NewCalculator("clients-per-stage", []metric.Type{metric.ClientsPerStage}, nil, s.calculateClientsPerStage),
+		metric.NewCalculator("stage-duration", []metric.Type{metric.StageDuration}, nil, s.calculateStageDuration),
+		// Daily rollback counts are incremented on every transition, only aggregates are calculated
+		metric.NewCalculator("rollback-count", []metric.Type{metric.RollbackCount}, []string{"week", "month"}, s.aggregateRollBackCount),
+		metric.NewCalculator("dropout", []metric.Type{metric.Dropout}, allIntervals, s.calculateDropout),
+		metric.NewCalculator("conversion", []metric.Type{metric.Conversion}, allIntervals, s.calculateConversion),
+		metric.NewCalculator("total-duration", []metric.Type{metric.TotalDuration}, nil, s.calculateTotalDuration),
+		metric.NewCalculator("status-updates", []metric.Type{metric.StatusUpdates}, allIntervals, s.calculateStatusUpdates),
+		metric.NewCalculator("dau", []metric.Type{metric.DAU}, []string{"day"}, s.calculateDAU),
+		metric.NewCalculator("mau", []metric.Type{metric.MAU}, []string{"month"}, s.calculateMAU),
+		metric.NewCalculator("source-conversion", []metric.Type{metric.SourceConversion}, allIntervals, s.calculateSourceConversion),
+		metric.NewCalculator("channel-conversion", []metric.Type{metric.ChannelConversion}, allIntervals, s.calculateChannelConversion),
+		metric.NewCalculator("app-install-rate", []metric.Type{metric.AppInstallRate}, nil, s.calculateAppInstallRate),
+		metric.NewCalculator("autopayment-rate", []metric.Type{metric.AutoPaymentRate}, nil, s.calculateAutoPaymentRate),
+		metric.NewCalculator("revenue", revenueTypes, allIntervals, s.calculateRevenue),
+		metric.NewCalculator("stage-business-duration", []metric.Type{metric.StageBusinessDuration}, nil, s.calculateStageBusinessDuration),
+		metric.NewCalculator("total-business-duration", []metric.Type{metric.TotalBusinessDuration}, nil, s.calculateTotalBusinessDuration),
+		metric.NewCalculator("sla-breaches", []metric.Type{metric.SLABreaches}, nil, s.calculateSLABreaches),
+		metric.NewCalculator("client-activity", []metric.Type{metric.ChurnRate, metric.ReactivationRate}, allIntervals, s.calculateActivityRates),
+		metric.NewCalculator("rollback-reasons", []metric.Type{metric.RollbackReasons}, allIntervals, s.calculateRollbackReasons),
+		metric.NewCalculator("dropout-reasons", []metric.Type{metric.DropoutReasons}, allIntervals, s.calculateDropoutReasons),
+	}
+}
+
+func (s *Service) calculateDAU(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
+	startOfDay := time.Date(p.AsOf.Year(), p.AsOf.Month(), p.AsOf.Day(), 0, 0, 0, 0, p.AsOf.Location())
 
 	count, err := s.clientRepository.Count(ctx, bson.M{
 		"last_login": bson.M{
@@ -199,24 +548,18 @@ func (s *Service) calculateDAU(ctx context.Context, timestamp time.Time) error {
 		},
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	metricEntity, err := s.createMetric("", metric.DAU, float64(count), "day", timestamp, nil)
+	metricEntity, err := s.createMetric("", metric.DAU, float64(count), p.Interval, p.AsOf, nil)
 	if err != nil {
-		return err
-	}
-
-	_, err = s.MetricRepository.Add(ctx, metricEntity)
-
-	if err != nil && !errors.Is(err, store.ErrorNotFound) {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return []metric.Entity{metricEntity}, nil
 }
 
-func (s *Service) calculateMAU(ctx context.Context, timestamp time.Time) error {
-	startOfMonth := time.Now().Add(-time.Hour * 24 * 30)
+func (s *Service) calculateMAU(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
+	startOfMonth := p.AsOf.Add(-time.Hour * 24 * 30)
 
 	count, err := s.clientRepository.Count(ctx, bson.M{
 		"last_login": bson.M{
@@ -224,113 +567,255 @@ func (s *Service) calculateMAU(ctx context.Context, timestamp time.Time) error {
 		},
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	metricEntity, err := s.createMetric("", metric.MAU, float64(count), "month", timestamp, nil)
+	metricEntity, err := s.createMetric("", metric.MAU, float64(count), p.Interval, p.AsOf, nil)
 	if err != nil {
-		return err
-	}
-	_, err = s.MetricRepository.Add(ctx, metricEntity)
-	if err != nil && !errors.Is(err, store.ErrorNotFound) {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return []metric.Entity{metricEntity}, nil
 }
 
-func (s *Service) calculateClientsPerStage(ctx context.Context, timestamp time.Time) error {
+func (s *Service) calculateClientsPerStage(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
 	stages, err := s.StageRepository.List(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var res []metric.Entity
 	for _, stage := range stages {
 		count, err := s.clientRepository.Count(ctx, bson.M{
 			"current_stage": stage.ID,
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
-		metricEntity, err := s.createMetric("", metric.ClientsPerStage, float64(count), "", timestamp, map[string]string{"stage": stage.ID})
+		metricEntity, err := s.createMetric("", metric.ClientsPerStage, float64(count), "", p.AsOf, map[string]string{"stage": stage.ID})
 		if err != nil {
-			return err
+			return nil, err
 		}
-		_, err = s.MetricRepository.Add(ctx, metricEntity)
+		res = append(res, metricEntity)
+	}
 
-		if err != nil {
-			return err
+	return res, nil
+}
+
+// durationPercentiles are the dwell time percentiles stored for stage and journey durations
+var durationPercentiles = []struct {
+	Label string
+	Level float64
+}{
+	{"p50", 0.5},
+	{"p75", 0.75},
+	{"p90", 0.9},
+	{"p95", 0.95},
+}
+
+// percentileLevels returns the levels of durationPercentiles
+func percentileLevels() []float64 {
+	levels := make([]float64, len(durationPercentiles))
+	for i, p := range durationPercentiles {
+		levels[i] = p.Level
+	}
+	return levels
+}
+
+// clientsWithTransitions returns the clients with stage transition events recorded by asOf.
+// Event data is only complete for these clients; the others, such as clients last updated before
+// events were recorded, fall back to their snapshot one by one.
+func (s *Service) clientsWithTransitions(ctx context.Context, asOf time.Time) (map[string]bool, error) {
+	res := make(map[string]bool)
+	if s.transitions == nil {
+		return res, nil
+	}
+
+	ids, err := s.transitions.Clients(ctx, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients with stage transitions: %w", err)
+	}
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
+
+// stageStays returns the stays in a stage as of asOf and the number of clients they come from
+// events. Clients with transition events contribute every recorded stay; active clients without
+// events contribute the time since their last update in their current stage.
+func (s *Service) stageStays(ctx context.Context, asOf time.Time) ([]transition.Stay, int, error) {
+	withEvents, err := s.clientsWithTransitions(ctx, asOf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var stays []transition.Stay
+	if len(withEvents) > 0 {
+		if stays, err = s.transitions.Stays(ctx, asOf); err != nil {
+			return nil, 0, fmt.Errorf("failed to list stage stays: %w", err)
 		}
+	}
 
+	snapshot, err := s.snapshotStays(ctx, asOf, withEvents)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return nil
+	return append(stays, snapshot...), len(withEvents), nil
 }
 
-func (s *Service) calculateStageDuration(ctx context.Context, timestamp time.Time) error {
-	isActive := true
-	clients, _, err := s.clientRepository.List(ctx, client.Filters{IsActive: &isActive}, 0, 0)
+// snapshotStays returns the time active clients without transition events have spent in their
+// current stage as of asOf
+func (s *Service) snapshotStays(ctx context.Context, asOf time.Time, withEvents map[string]bool) ([]transition.Stay, error) {
+	clients, err := s.activeClients(ctx, "")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	stageDurations := make(map[string][]time.Duration)
+	var stays []transition.Stay
 	for _, c := range clients {
-		if c.CurrentStage == nil || c.RegistrationDate == nil || c.LastUpdated == nil {
+		if withEvents[c.ID] || c.CurrentStage == nil || c.LastUpdated == nil {
 			continue
 		}
-		duration := timestamp.Sub(*c.LastUpdated)
-		stageDurations[*c.CurrentStage] = append(stageDurations[*c.CurrentStage], duration)
+		stays = append(stays, transition.Stay{ClientID: c.ID, Stage: *c.CurrentStage, EnteredAt: *c.LastUpdated, LeftAt: asOf})
 	}
 
-	for stageID, durations := range stageDurations {
-		var total time.Duration
-		for _, d := range durations {
-			total += d
+	return stays, nil
+}
+
+// journeys returns the journeys to lastStage as of asOf and the number of clients with events.
+// Clients with transition events contribute the time from their first transition to reaching
+// lastStage; active clients on lastStage without events the time from registration to their
+// last update.
+func (s *Service) journeys(ctx context.Context, lastStage string, asOf time.Time) ([]transition.Journey, int, error) {
+	withEvents, err := s.clientsWithTransitions(ctx, asOf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var journeys []transition.Journey
+	if len(withEvents) > 0 {
+		if journeys, err = s.transitions.Journeys(ctx, lastStage, asOf); err != nil {
+			return nil, 0, fmt.Errorf("failed to list journeys: %w", err)
 		}
-		avgDuration := total / time.Duration(len(durations))
+	}
 
-		metricEntity, err := s.createMetric("", metric.StageDuration, avgDuration.Hours(), "", timestamp, map[string]string{"stage": stageID})
-		if err != nil {
-			return err
+	snapshot, err := s.snapshotJourneys(ctx, lastStage, withEvents)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return append(journeys, snapshot...), len(withEvents), nil
+}
+
+// snapshotJourneys returns the time from registration to the last update of the active clients
+// on lastStage without transition events
+func (s *Service) snapshotJourneys(ctx context.Context, lastStage string, withEvents map[string]bool) ([]transition.Journey, error) {
+	clients, err := s.activeClients(ctx, lastStage)
+	if err != nil {
+		return nil, err
+	}
+
+	var journeys []transition.Journey
+	for _, c := range clients {
+		if withEvents[c.ID] || c.RegistrationDate == nil || c.LastUpdated == nil {
+			continue
+		}
+		journeys = append(journeys, transition.Journey{ClientID: c.ID, StartedAt: *c.RegistrationDate, FinishedAt: *c.LastUpdated})
+	}
+
+	return journeys, nil
+}
+
+// calculateStageDuration stores the p50, p75, p90 and p95 hours clients spend in each stage.
+// When transition events exist the percentiles are computed in ClickHouse from the events, with
+// the time active clients without events have spent in their current stage added to them;
+// otherwise they are computed from that time alone.
+func (s *Service) calculateStageDuration(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.stage_duration").Logger()
+
+	withEvents, err := s.clientsWithTransitions(ctx, p.AsOf)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := s.snapshotStays(ctx, p.AsOf, withEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	// Quantiles per stage in hours
+	quantiles := make(map[string][]float64)
+
+	if len(withEvents) > 0 {
+		extra := make(map[string][]float64)
+		for _, stay := range snapshot {
+			extra[stay.Stage] = append(extra[stay.Stage], stay.LeftAt.Sub(stay.EnteredAt).Seconds())
 		}
 
-		if _, err := s.MetricRepository.Add(ctx, metricEntity); err != nil {
-			return err
+		seconds, err := s.transitions.StageDurationQuantiles(ctx, p.AsOf, percentileLevels(), extra)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate stage duration quantiles: %w", err)
 		}
+		for stageID, values := range seconds {
+			hours := make([]float64, len(values))
+			for i, v := range values {
+				hours[i] = v / 3600
+			}
+			quantiles[stageID] = hours
+		}
+	} else {
+		stageDurations := make(map[string][]float64)
+		for _, stay := range snapshot {
+			stageDurations[stay.Stage] = append(stageDurations[stay.Stage], stay.LeftAt.Sub(stay.EnteredAt).Hours())
+		}
+		for stageID, durations := range stageDurations {
+			quantiles[stageID] = metric.Quantiles(durations, percentileLevels()...)
+		}
+	}
 
+	logger.Info().
+		Int("event_clients", len(withEvents)).
+		Int("snapshot_stays", len(snapshot)).
+		Int("stages", len(quantiles)).
+		Msg("Stage duration calculation results")
+
+	var res []metric.Entity
+	for stageID, values := range quantiles {
+		for i, pct := range durationPercentiles {
+			if i >= len(values) {
+				break
+			}
+			m, err := s.createMetric("", metric.StageDuration, values[i], "", p.AsOf, map[string]string{
+				"stage":      stageID,
+				"percentile": pct.Label,
+			})
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, m)
+		}
 	}
 
-	return nil
+	return res, nil
 }
 
-func (s *Service) aggregateRollBackCount(ctx context.Context, timestamp time.Time, interval string) error {
+func (s *Service) aggregateRollBackCount(ctx context.Context, period metric.Period) ([]metric.Entity, error) {
+	interval := period.Interval
 	logger := log.LoggerFromContext(ctx).With().
-		Str("timestamp", timestamp.Format(time.RFC3339)).
+		Str("timestamp", period.AsOf.Format(time.RFC3339)).
 		Str("interval", interval).
 		Str("component", "service.track.metric").
 		Logger()
 
 	// Verify the interval is valid
 	if interval != "week" && interval != "month" {
-		return fmt.Errorf("invalid interval: %s (valid values: week, month)", interval)
-	}
-
-	var startTime, endTime time.Time
-
-	if interval == "week" {
-		// Get the start and end of the week
-		year, week := timestamp.ISOWeek()
-		startTime = firstDayOfISOWeek(year, week, timestamp.Location())
-		endTime = startTime.AddDate(0, 0, 7)
-	} else {
-		// Get the start and end of the month
-		startTime = time.Date(timestamp.Year(), timestamp.Month(), 1, 0, 0, 0, 0, timestamp.Location())
-		endTime = startTime.AddDate(0, 1, 0)
+		return nil, fmt.Errorf("invalid interval: %s (valid values: week, month)", interval)
 	}
 
 	logger.Info().
-		Time("startTime", startTime).
-		Time("endTime", endTime).
+		Time("startTime", period.Start).
+		Time("endTime", period.End).
 		Msg("Aggregating rollback count")
 
 	// Get all rollback count metrics for the period
@@ -339,13 +824,13 @@ func (s *Service) aggregateRollBackCount(ctx context.Context, timestamp time.Tim
 		Interval: "day",
 	})
 	if err != nil && !errors.Is(err, store.ErrorNotFound) {
-		return fmt.Errorf("failed to get rollback count metrics: %w", err)
+		return nil, fmt.Errorf("failed to get rollback count metrics: %w", err)
 	}
 
 	// Filter metrics for the period
 	periodMetrics := make([]metric.Entity, 0)
 	for _, m := range dailyMetrics {
-		if !m.CreatedAt.Before(startTime) && m.CreatedAt.Before(endTime) {
+		if period.Contains(*m.CreatedAt) {
 			periodMetrics = append(periodMetrics, m)
 		}
 	}
@@ -360,90 +845,45 @@ func (s *Service) aggregateRollBackCount(ctx context.Context, timestamp time.Tim
 		Float64("total_rollbacks", totalRollbacks).
 		Msg("Rollback count aggregation results")
 
-	// Create and store aggregated metric
-	aggregatedMetric, err := s.createMetric("",
-		metric.RollbackCount,
-		totalRollbacks,
-		interval,
-		endTime,
-		nil,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create aggregated rollback count metric: %w", err)
-	}
-
-	// Check if we already have a metric for this period
+	// Check if we already have a metric for this period, storing it again replaces that row
 	existingMetrics, err := s.MetricRepository.List(ctx, metric.Filters{
 		Type:     string(metric.RollbackCount),
 		Interval: interval,
 	})
 	if err != nil && !errors.Is(err, store.ErrorNotFound) {
-		return fmt.Errorf("failed to check existing aggregated metrics: %w", err)
+		return nil, fmt.Errorf("failed to check existing aggregated metrics: %w", err)
 	}
 
-	var found bool
+	var existingID string
 	for _, m := range existingMetrics {
-		var isInSamePeriod bool
-
-		if interval == "week" {
-			existingYear, existingWeek := m.CreatedAt.ISOWeek()
-			currentYear, currentWeek := timestamp.ISOWeek()
-			isInSamePeriod = existingYear == currentYear && existingWeek == currentWeek
-		} else {
-			isInSamePeriod = m.CreatedAt.Year() == timestamp.Year() &&
-				m.CreatedAt.Month() == timestamp.Month()
-		}
-
-		if isInSamePeriod {
-			found = true
-			if _, err := s.MetricRepository.Update(ctx, m.ID, aggregatedMetric); err != nil {
-				return fmt.Errorf("failed to update existing %s rollback count metric: %w", interval, err)
-			}
-			logger.Info().Msgf("Updated existing %s rollback count metric", interval)
+		if period.Contains(*m.CreatedAt) {
+			existingID = m.ID
 			break
 		}
 	}
 
-	if !found {
-		if _, err := s.MetricRepository.Add(ctx, aggregatedMetric); err != nil {
-			return fmt.Errorf("failed to store %s rollback count metric: %w", interval, err)
-		}
-		logger.Info().Msgf("Created new %s rollback count metric", interval)
-	}
-	return nil
-}
-
-// Helper function to get the first day of an ISO week
-func firstDayOfISOWeek(year, week int, loc *time.Location) time.Time {
-	// Get January 1 for the year
-	jan1 := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
-
-	// Get the day of the week for January 1
-	dayOfWeek := int(jan1.Weekday())
-	if dayOfWeek == 0 {
-		// Sunday is 0, but ISO considers it 7
-		dayOfWeek = 7
-	}
-
-	// Days to add to get to the Monday of week 1
-	daysToAdd := 1 - dayOfWeek
-
-	// Monday of the first week
-	firstMonday := jan1.AddDate(0, 0, daysToAdd)
-
-	// If January 1 is after Thursday, it's part of week 1
-	// If not, it's part of the last week of the previous year
-	if dayOfWeek > 4 {
-		firstMonday = firstMonday.AddDate(0, 0, 7)
+	// Create aggregated metric within the period it aggregates
+	aggregatedMetric, err := s.createMetric(existingID,
+		metric.RollbackCount,
+		totalRollbacks,
+		interval,
+		period.AsOf,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create aggregated rollback count metric: %w", err)
 	}
 
-	// Add the required number of weeks
-	return firstMonday.AddDate(0, 0, 7*(week-1))
+	return []metric.Entity{aggregatedMetric}, nil
 }
 
 func (s *Service) calculateRollbackCount(ctx context.Context, timestamp time.Time) error {
 	logger := log.LoggerFromContext(ctx)
-	todayDate := timestamp.Truncate(24 * time.Hour)
+	today, err := s.periodOf("day", timestamp)
+	if err != nil {
+		return err
+	}
+	todayDate := today.Start
 
 	// Получаем текущее значение за сегодня
 	rollBackCountMetrics, err := s.ListMetrics(ctx, metric.Filters{
@@ -462,8 +902,7 @@ func (s *Service) calculateRollbackCount(ctx context.Context, timestamp time.Tim
 	var existingID string
 
 	for i := range rollBackCountMetrics {
-		metricDate := rollBackCountMetrics[i].CreatedAt.Truncate(24 * time.Hour)
-		if metricDate.Equal(todayDate) {
+		if today.Contains(rollBackCountMetrics[i].CreatedAt) {
 			currentValue = rollBackCountMetrics[i].Value
 			existingID = rollBackCountMetrics[i].ID
 			break
@@ -483,7 +922,7 @@ func (s *Service) calculateRollbackCount(ctx context.Context, timestamp time.Tim
 		Value:     &newValue,
 		Interval:  &interval,
 		CreatedAt: &timestamp,
-		Metadata:  make(map[string]string),
+		Metadata:  map[string]string{"timezone": s.location.String()},
 	}
 
 	if _, err = s.MetricRepository.Add(ctx, newMetric); err != nil {
@@ -507,66 +946,47 @@ func (s *Service) calculateRollbackCount(ctx context.Context, timestamp time.Tim
 	return nil
 }
 
-func (s *Service) calculateDropout(ctx context.Context, timestamp time.Time, interval string) error {
+func (s *Service) calculateDropout(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
 	var inactivePeriod int
-	switch interval {
+	switch p.Interval {
 	case "week":
 		inactivePeriod = 7
 	case "month":
 		inactivePeriod = 30
 	}
 
-	cutoffDate := timestamp.AddDate(0, 0, -inactivePeriod)
+	cutoffDate := p.AsOf.AddDate(0, 0, -inactivePeriod)
 
 	count, err := s.clientRepository.Count(ctx, bson.M{
 		"last_updated": bson.M{"$lt": cutoffDate},
 		"is_active":    true,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	m, err := s.createMetric("", metric.Dropout, float64(count), interval, timestamp, nil)
-
+	m, err := s.createMetric("", metric.Dropout, float64(count), p.Interval, p.AsOf, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create dropout metric: %w", err)
+		return nil, fmt.Errorf("failed to create dropout metric: %w", err)
 	}
 
-	_, err = s.MetricRepository.Add(ctx, m)
-	if err != nil && !errors.Is(err, store.ErrorNotFound) {
-		return err
-	}
-
-	return err
+	return []metric.Entity{m}, nil
 }
 
-func (s *Service) calculateConversion(ctx context.Context, timestamp time.Time, interval string) error {
+func (s *Service) calculateConversion(ctx context.Context, period metric.Period) ([]metric.Entity, error) {
 	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.conversion").Logger()
 
 	stages, err := s.StageRepository.List(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list stages: %w", err)
+		return nil, fmt.Errorf("failed to list stages: %w", err)
 	}
 
 	if len(stages) < 1 {
-		return nil
+		return nil, nil
 	}
 
 	lastStage := stages[len(stages)-1].ID
-
-	// Calculate time period based on interval
-	var startDate time.Time
-	switch interval {
-	case "day":
-		startDate = time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, timestamp.Location())
-	case "week":
-		year, week := timestamp.ISOWeek()
-		startDate = firstDayOfISOWeek(year, week, timestamp.Location())
-	case "month":
-		startDate = time.Date(timestamp.Year(), timestamp.Month(), 1, 0, 0, 0, 0, timestamp.Location())
-	default:
-		return fmt.Errorf("invalid interval: %s", interval)
-	}
+	startDate, timestamp, interval := period.Start, period.AsOf, period.Interval
 
 	logger.Info().
 		Time("start_date", startDate).
@@ -583,7 +1003,7 @@ func (s *Service) calculateConversion(ctx context.Context, timestamp time.Time,
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to count clients on last stage with recent updates: %w", err)
+		return nil, fmt.Errorf("failed to count clients on last stage with recent updates: %w", err)
 	}
 
 	// Total number of clients active in this period
@@ -594,7 +1014,7 @@ func (s *Service) calculateConversion(ctx context.Context, timestamp time.Time,
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to count total active clients: %w", err)
+		return nil, fmt.Errorf("failed to count total active clients: %w", err)
 	}
 
 	var conversionRate float64
@@ -616,81 +1036,100 @@ func (s *Service) calculateConversion(ctx context.Context, timestamp time.Time,
 		nil,
 	)
 	if err != nil {
-		return err
-	}
-
-	if _, err = s.MetricRepository.Add(ctx, m); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return []metric.Entity{m}, nil
 }
 
-func (s *Service) calculateTotalDuration(ctx context.Context, timestamp time.Time) error {
+// calculateTotalDuration stores the p50, p75, p90 and p95 days clients take to reach the last
+// stage. When transition events exist the percentiles are computed in ClickHouse from the events,
+// with the time from registration to the last update of active clients on the last stage without
+// events added to them; otherwise they are computed from that time alone.
+func (s *Service) calculateTotalDuration(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.total_duration").Logger()
+
 	stages, err := s.StageRepository.List(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(stages) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	lastStage := stages[len(stages)-1].ID
-	isActive := true
-	clients, _, err := s.clientRepository.List(ctx, client.Filters{
-		Stage:    lastStage,
-		IsActive: &isActive,
-	}, 0, 0)
+
+	withEvents, err := s.clientsWithTransitions(ctx, p.AsOf)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var totalDuration time.Duration
-	var count int
+	snapshot, err := s.snapshotJourneys(ctx, lastStage, withEvents)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, c := range clients {
-		if c.RegistrationDate != nil && c.LastUpdated != nil {
-			totalDuration += c.LastUpdated.Sub(*c.RegistrationDate)
-			count++
+	// Quantiles in days
+	var (
+		quantiles []float64
+		count     int
+	)
+
+	if len(withEvents) > 0 {
+		extra := make([]float64, 0, len(snapshot))
+		for _, j := range snapshot {
+			extra = append(extra, j.FinishedAt.Sub(j.StartedAt).Seconds())
 		}
-	}
 
-	var avgDurationDays float64
-	if count > 0 {
-		avgDurationDays = totalDuration.Hours() / 24 / float64(count)
+		var seconds []float64
+		seconds, count, err = s.transitions.JourneyDurationQuantiles(ctx, lastStage, p.AsOf, percentileLevels(), extra)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate journey duration quantiles: %w", err)
+		}
+		if count > 0 {
+			for _, v := range seconds {
+				quantiles = append(quantiles, v/3600/24)
+			}
+		}
+	} else {
+		durations := make([]float64, 0, len(snapshot))
+		for _, j := range snapshot {
+			durations = append(durations, j.FinishedAt.Sub(j.StartedAt).Hours()/24)
+		}
+		count = len(durations)
+		quantiles = metric.Quantiles(durations, percentileLevels()...)
 	}
 
-	m, err := s.createMetric("", metric.TotalDuration, avgDurationDays, "", timestamp, nil)
+	logger.Info().
+		Int("event_clients", len(withEvents)).
+		Int("clients", count).
+		Msg("Total duration calculation results")
 
-	if err != nil {
-		return fmt.Errorf("failed to create total duration metric: %w", err)
+	// Without finished journeys every percentile is zero, as the mean used to be
+	if len(quantiles) == 0 {
+		quantiles = make([]float64, len(durationPercentiles))
 	}
 
-	_, err = s.MetricRepository.Add(ctx, m)
-	if err != nil && !errors.Is(err, store.ErrorNotFound) {
-		return err
+	var res []metric.Entity
+	for i, pct := range durationPercentiles {
+		if i >= len(quantiles) {
+			break
+		}
+		m, err := s.createMetric("", metric.TotalDuration, quantiles[i], "", p.AsOf, map[string]string{"percentile": pct.Label})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create total duration metric: %w", err)
+		}
+		res = append(res, m)
 	}
 
-	return nil
+	return res, nil
 }
 
-func (s *Service) calculateStatusUpdates(ctx context.Context, timestamp time.Time, interval string) error {
+func (s *Service) calculateStatusUpdates(ctx context.Context, period metric.Period) ([]metric.Entity, error) {
 	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.status_updates").Logger()
 
-	// Calculate time period based on interval
-	var startDate time.Time
-	switch interval {
-	case "day":
-		startDate = time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, timestamp.Location())
-	case "week":
-		year, week := timestamp.ISOWeek()
-		startDate = firstDayOfISOWeek(year, week, timestamp.Location())
-	case "month":
-		startDate = time.Date(timestamp.Year(), timestamp.Month(), 1, 0, 0, 0, 0, timestamp.Location())
-	default:
-		return fmt.Errorf("invalid interval: %s", interval)
-	}
+	startDate, timestamp, interval := period.Start, period.AsOf, period.Interval
 
 	logger.Info().
 		Time("start_date", startDate).
@@ -706,7 +1145,7 @@ func (s *Service) calculateStatusUpdates(ctx context.Context, timestamp time.Tim
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to count status updates: %w", err)
+		return nil, fmt.Errorf("failed to count status updates: %w", err)
 	}
 
 	logger.Info().
@@ -714,256 +1153,25 @@ func (s *Service) calculateStatusUpdates(ctx context.Context, timestamp time.Tim
 		Str("interval", interval).
 		Msg("Status updates calculation results")
 
-	// Create and store the metric
 	m, err := s.createMetric("", metric.StatusUpdates, float64(count), interval, timestamp, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create status updates metric: %w", err)
+		return nil, fmt.Errorf("failed to create status updates metric: %w", err)
 	}
 
-	if _, err = s.MetricRepository.Add(ctx, m); err != nil {
-		return fmt.Errorf("failed to store status updates metric: %w", err)
-	}
-
-	return nil
+	return []metric.Entity{m}, nil
 }
 
-func (s *Service) calculateSourceConversion(ctx context.Context, timestamp time.Time, interval string) error {
-	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.source_conversion").Logger()
-
-	stages, err := s.StageRepository.List(ctx)
-	if err != nil {
-		return err
-	}
-
-	if len(stages) < 2 {
-		return nil
-	}
-
-	lastStage := stages[len(stages)-1].ID
-
-	// Calculate time period based on interval
-	var startDate time.Time
-	switch interval {
-	case "day":
-		startDate = time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, timestamp.Location())
-	case "week":
-		year, week := timestamp.ISOWeek()
-		startDate = firstDayOfISOWeek(year, week, timestamp.Location())
-	case "month":
-		startDate = time.Date(timestamp.Year(), timestamp.Month(), 1, 0, 0, 0, 0, timestamp.Location())
-	default:
-		return err
-	}
-
-	logger.Info().
-		Time("start_date", startDate).
-		Time("end_date", timestamp).
-		Str("interval", interval).
-		Msg("Calculating source conversion")
-
-	// Get clients active within the time period
-	clients, _, err := s.clientRepository.List(ctx, client.Filters{}, 0, 0)
-	if err != nil {
-		return err
-	}
-
-	// Collect unique sources
-	sourceMap := make(map[string]bool)
-	for _, c := range clients {
-		if c.Source != nil && *c.Source != "" && c.LastUpdated != nil {
-			if !c.LastUpdated.Before(startDate) && !c.LastUpdated.After(timestamp) {
-				sourceMap[*c.Source] = true
-			}
-		}
-	}
-
-	sources := make([]string, 0, len(sourceMap))
-	for source := range sourceMap {
-		sources = append(sources, source)
-	}
-
-	for _, source := range sources {
-		// Count total clients from this source active in the period
-		total, err := s.clientRepository.Count(ctx, bson.M{
-			"source": source,
-			"last_updated": bson.M{
-				"$gte": startDate,
-				"$lte": timestamp,
-			},
-		})
-		if err != nil {
-			return err
-		}
-
-		// Count completed clients from this source active in the period
-		completed, err := s.clientRepository.Count(ctx, bson.M{
-			"source":        source,
-			"current_stage": lastStage,
-			"last_updated": bson.M{
-				"$gte": startDate,
-				"$lte": timestamp,
-			},
-		})
-		if err != nil {
-			return err
-		}
-
-		var conversionRate float64
-		if total > 0 {
-			conversionRate = float64(completed) / float64(total)
-		}
-
-		logger.Info().
-			Str("source", source).
-			Int64("total", total).
-			Int64("completed", completed).
-			Float64("conversion_rate", conversionRate).
-			Msg("Source conversion calculation results")
-
-		m, err := s.createMetric("",
-			metric.SourceConversion,
-			conversionRate,
-			interval,
-			timestamp,
-			map[string]string{"source": source},
-		)
-		if err != nil {
-			return fmt.Errorf("failed to create source conversion metric: %w", err)
-		}
-
-		if _, err := s.MetricRepository.Add(ctx, m); err != nil {
-			return fmt.Errorf("failed to store source conversion metric: %w", err)
-		}
-
-	}
-
-	return nil
-}
-
-// calculateSourceConversion calculates the conversion rate for each source
-func (s *Service) calculateChannelConversion(ctx context.Context, timestamp time.Time, interval string) error {
-	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.channel_conversion").Logger()
-
-	stages, err := s.StageRepository.List(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to list stages: %w", err)
-	}
-
-	if len(stages) < 2 {
-		return nil
-	}
-
-	lastStage := stages[len(stages)-1].ID
-
-	// Calculate time period based on interval
-	var startDate time.Time
-	switch interval {
-	case "day":
-		startDate = time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, timestamp.Location())
-	case "week":
-		year, week := timestamp.ISOWeek()
-		startDate = firstDayOfISOWeek(year, week, timestamp.Location())
-	case "month":
-		startDate = time.Date(timestamp.Year(), timestamp.Month(), 1, 0, 0, 0, 0, timestamp.Location())
-	default:
-		return fmt.Errorf("invalid interval: %s", interval)
-	}
-
-	logger.Info().
-		Time("start_date", startDate).
-		Time("end_date", timestamp).
-		Str("interval", interval).
-		Msg("Calculating channel conversion")
-
-	// Get clients active within the time period
-	clients, _, err := s.clientRepository.List(ctx, client.Filters{}, 0, 0)
-	if err != nil {
-		return fmt.Errorf("failed to list clients: %w", err)
-	}
-
-	// Collect unique channels from active clients in this period
-	channelMap := make(map[string]bool)
-	for _, c := range clients {
-		if c.Channel != nil && *c.Channel != "" && c.LastUpdated != nil {
-			if !c.LastUpdated.Before(startDate) && !c.LastUpdated.After(timestamp) {
-				channelMap[*c.Channel] = true
-			}
-		}
-	}
-
-	channels := make([]string, 0, len(channelMap))
-	for channel := range channelMap {
-		channels = append(channels, channel)
-	}
-
-	for _, channel := range channels {
-		// Count total clients from this channel active in the period
-		total, err := s.clientRepository.Count(ctx, bson.M{
-			"channel": channel,
-			"last_updated": bson.M{
-				"$gte": startDate,
-				"$lte": timestamp,
-			},
-		})
-		if err != nil {
-			return fmt.Errorf("failed to count clients with channel %s: %w", channel, err)
-		}
-
-		// Count completed clients from this channel active in the period
-		completed, err := s.clientRepository.Count(ctx, bson.M{
-			"channel":       channel,
-			"current_stage": lastStage,
-			"last_updated": bson.M{
-				"$gte": startDate,
-				"$lte": timestamp,
-			},
-		})
-		if err != nil {
-			return fmt.Errorf("failed to count completed clients with channel %s: %w", channel, err)
-		}
-
-		var conversionRate float64
-		if total > 0 {
-			conversionRate = float64(completed) / float64(total)
-		}
-
-		logger.Info().
-			Str("channel", channel).
-			Int64("total", total).
-			Int64("completed", completed).
-			Float64("conversion_rate", conversionRate).
-			Msg("Channel conversion calculation results")
-
-		m, err := s.createMetric("",
-			metric.ChannelConversion,
-			conversionRate,
-			interval,
-			timestamp,
-			map[string]string{"channel": channel},
-		)
-		if err != nil {
-			return fmt.Errorf("failed to create channel conversion metric: %w", err)
-		}
-
-		if _, err := s.MetricRepository.Add(ctx, m); err != nil {
-			return fmt.Errorf("failed to store channel conversion metric: %w", err)
-		}
-	}
-
-	return nil
-}
-
-func (s *Service) calculateAppInstallRate(ctx context.Context, timestamp time.Time) error {
+func (s *Service) calculateAppInstallRate(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
 	// Общее количество клиентов с указанным статусом app
 	total, err := s.clientRepository.Count(ctx, bson.M{"app": bson.M{"$exists": true}})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Количество клиентов с установленным приложением
 	installed, err := s.clientRepository.Count(ctx, bson.M{"app": "installed"})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var installRate float64
@@ -971,24 +1179,19 @@ func (s *Service) calculateAppInstallRate(ctx context.Context, timestamp time.Ti
 		installRate = float64(installed) / float64(total)
 	}
 
-	m, err := s.createMetric("", metric.AppInstallRate, installRate, "", timestamp, nil)
+	m, err := s.createMetric("", metric.AppInstallRate, installRate, "", p.AsOf, nil)
 	if err != nil {
-		return err
-	}
-
-	_, err = s.MetricRepository.Add(ctx, m)
-	if err != nil && !errors.Is(err, store.ErrorNotFound) {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return []metric.Entity{m}, nil
 }
 
-func (s *Service) calculateAutoPaymentRate(ctx context.Context, timestamp time.Time) error {
+func (s *Service) calculateAutoPaymentRate(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
 	// Получаем всех клиентов с договорами
-	clients, _, err := s.clientRepository.List(ctx, client.Filters{}, 0, 0)
+	clients, err := s.clientRepository.ListAll(ctx, client.Filters{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var totalContracts int
@@ -1008,17 +1211,12 @@ func (s *Service) calculateAutoPaymentRate(ctx context.Context, timestamp time.T
 		autopaymentRate = float64(enabledContracts) / float64(totalContracts)
 	}
 
-	m, err := s.createMetric("", metric.AutoPaymentRate, autopaymentRate, "", timestamp, nil)
+	m, err := s.createMetric("", metric.AutoPaymentRate, autopaymentRate, "", p.AsOf, nil)
 	if err != nil {
-		return err
-	}
-
-	_, err = s.MetricRepository.Add(ctx, m)
-	if err != nil && !errors.Is(err, store.ErrorNotFound) {
-		return err
+		return nil, err
 	}
 
-	return err
+	return []metric.Entity{m}, nil
 }
 
 func (s *Service) createMetric(id string, metricType metric.Type, value float64, interval string, timestamp time.Time, metaData map[string]string) (metric.Entity, error) {
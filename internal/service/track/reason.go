@@ -0,0 +1,122 @@
+package track
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/metric"
+	"TrackMe/internal/domain/reason"
+	"context"
+	"fmt"
+	"sort"
+)
+
+// WithReasons applies the reason taxonomy of the repository that rollback and deactivation reason
+// codes are validated against
+func WithReasons(reasons reason.Repository) Configuration {
+	return func(s *Service) error {
+		taxonomy, err := reasons.Taxonomy(context.Background())
+		if err != nil {
+			return err
+		}
+		s.reasons = taxonomy
+		return nil
+	}
+}
+
+// ReasonTaxonomy returns the rollback and deactivation reason codes clients can be given
+func (s *Service) ReasonTaxonomy(ctx context.Context) reason.Taxonomy {
+	return s.reasons
+}
+
+// calculateRollbackReasons counts the moves back to a previous stage in the period per stage left
+// and reason code
+func (s *Service) calculateRollbackReasons(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
+	if s.transitions == nil {
+		return nil, nil
+	}
+
+	stages, err := s.StageRepository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stages: %w", err)
+	}
+
+	order := make(map[string]int, len(stages))
+	for i, st := range stages {
+		order[st.ID] = i
+	}
+
+	events, err := s.transitions.List(ctx, p.Start, p.AsOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transitions: %w", err)
+	}
+
+	counts := make(map[[2]string]float64)
+	for _, e := range events {
+		from, ok := order[e.FromStage]
+		if !ok || e.FromStage == "" {
+			continue
+		}
+		if to, ok := order[e.ToStage]; !ok || to >= from {
+			continue
+		}
+		counts[[2]string{e.FromStage, reasonOrUnspecified(e.Reason)}]++
+	}
+
+	return s.reasonMetrics(metric.RollbackReasons, counts, p)
+}
+
+// calculateDropoutReasons counts the deactivations in the period per stage the client was in and
+// reason code
+func (s *Service) calculateDropoutReasons(ctx context.Context, p metric.Period) ([]metric.Entity, error) {
+	if s.activity == nil {
+		return nil, nil
+	}
+
+	events, err := s.activity.List(ctx, p.AsOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list client activity: %w", err)
+	}
+
+	counts := make(map[[2]string]float64)
+	for _, e := range events {
+		if e.Event != client.Deactivated || e.CreatedAt.Before(p.Start) || e.CreatedAt.After(p.AsOf) {
+			continue
+		}
+		counts[[2]string{e.Stage, reasonOrUnspecified(e.Reason)}]++
+	}
+
+	return s.reasonMetrics(metric.DropoutReasons, counts, p)
+}
+
+// reasonMetrics creates one metric per stage and reason of the counts, in a stable order
+func (s *Service) reasonMetrics(metricType metric.Type, counts map[[2]string]float64, p metric.Period) ([]metric.Entity, error) {
+	keys := make([][2]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+
+	res := make([]metric.Entity, 0, len(keys))
+	for _, k := range keys {
+		m, err := s.createMetric("", metricType, counts[k], p.Interval, p.AsOf, map[string]string{
+			"stage":  k[0],
+			"reason": k[1],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s metric: %w", metricType, err)
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+func reasonOrUnspecified(code string) string {
+	if code == "" {
+		return reason.Unspecified
+	}
+	return code
+}
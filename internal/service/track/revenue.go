@@ -0,0 +1,243 @@
+package track
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/currency"
+	"TrackMe/internal/domain/metric"
+	"TrackMe/pkg/log"
+	"context"
+	"fmt"
+	"time"
+)
+
+// revenueTypes lists the metric types calculateRevenue produces
+var revenueTypes = []metric.Type{
+	metric.MRR,
+	metric.NewMRR,
+	metric.ChurnedMRR,
+	metric.ExpansionMRR,
+	metric.ContractionMRR,
+	metric.ARPU,
+	metric.LTV,
+}
+
+// revenueSegment accumulates revenue figures for one breakdown (total, a source or a channel).
+type revenueSegment struct {
+	metadata map[string]string
+
+	mrrStart float64
+	mrrEnd   float64
+
+	newMRR         float64
+	churnedMRR     float64
+	expansionMRR   float64
+	contractionMRR float64
+
+	payingStart    int
+	payingEnd      int
+	churnedClients int
+
+	lifetimeMonths float64
+	contracts      int
+}
+
+// add folds a single client's start and end MRR into the segment.
+func (seg *revenueSegment) add(c client.Entity, start, end float64, timestamp time.Time) {
+	seg.mrrStart += start
+	seg.mrrEnd += end
+
+	switch {
+	case start == 0 && end > 0:
+		seg.newMRR += end
+	case start > 0 && end == 0:
+		seg.churnedMRR += start
+		seg.churnedClients++
+	case end > start:
+		seg.expansionMRR += end - start
+	case end < start:
+		seg.contractionMRR += start - end
+	}
+
+	if start > 0 {
+		seg.payingStart++
+	}
+	if end > 0 {
+		seg.payingEnd++
+	}
+
+	for _, ct := range c.Contracts {
+		if !ct.IsActiveAt(timestamp) || ct.ConclusionDate == nil || ct.ExpirationDate == nil {
+			continue
+		}
+		seg.lifetimeMonths += ct.ExpirationDate.Sub(*ct.ConclusionDate).Hours() / 24 / 30
+		seg.contracts++
+	}
+}
+
+// arpu returns the average MRR per paying client at the end of the period.
+func (seg *revenueSegment) arpu() float64 {
+	if seg.payingEnd == 0 {
+		return 0
+	}
+	return seg.mrrEnd / float64(seg.payingEnd)
+}
+
+// ltv estimates lifetime value as ARPU divided by the monthly client churn rate.
+// Without observed churn the average contract lifetime is used instead.
+func (seg *revenueSegment) ltv(periodDays float64) float64 {
+	arpu := seg.arpu()
+	if seg.payingStart > 0 && seg.churnedClients > 0 && periodDays > 0 {
+		churnRate := float64(seg.churnedClients) / float64(seg.payingStart)
+		monthlyChurn := churnRate * 30 / periodDays
+		if monthlyChurn > 1 {
+			monthlyChurn = 1
+		}
+		return arpu / monthlyChurn
+	}
+	if seg.contracts == 0 {
+		return 0
+	}
+	return arpu * seg.lifetimeMonths / float64(seg.contracts)
+}
+
+// clientMRR sums the monthly normalized amount of every contract active at t in the base
+// currency of rates. Contracts in a currency without a known rate are skipped and counted.
+func clientMRR(c client.Entity, t time.Time, rates currency.Rates) (mrr float64, skipped int) {
+	for _, ct := range c.Contracts {
+		if !ct.IsActiveAt(t) {
+			continue
+		}
+
+		var code string
+		if ct.Currency != nil {
+			code = *ct.Currency
+		}
+
+		amount, err := rates.Convert(ct.MonthlyAmount(), code)
+		if err != nil {
+			skipped++
+			continue
+		}
+		mrr += amount
+	}
+	return mrr, skipped
+}
+
+// exchangeRates returns the rates used to normalize contract amounts to the base currency.
+// Without a provider every amount is assumed to already be in the base currency.
+func (s *Service) exchangeRates(ctx context.Context, date time.Time) (currency.Rates, error) {
+	if s.currencyProvider == nil {
+		return currency.Rates{Base: s.baseCurrency, Date: date}, nil
+	}
+	return s.currencyProvider.Rates(ctx, s.baseCurrency, date)
+}
+
+// calculateRevenue computes MRR, new/churned/expansion/contraction MRR, ARPU and LTV for the period as of
+// its timestamp. Amounts are normalized to the base currency and each metric is returned
+// as a total and broken down by source and channel.
+func (s *Service) calculateRevenue(ctx context.Context, period metric.Period) ([]metric.Entity, error) {
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric.revenue").Logger()
+
+	startDate, timestamp, interval := period.Start, period.AsOf, period.Interval
+
+	clients, err := s.clientRepository.ListAll(ctx, client.Filters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	rates, err := s.exchangeRates(ctx, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	total := &revenueSegment{metadata: map[string]string{}}
+	segments := []*revenueSegment{total}
+	bySource := make(map[string]*revenueSegment)
+	byChannel := make(map[string]*revenueSegment)
+
+	segmentFor := func(index map[string]*revenueSegment, key, value string) *revenueSegment {
+		seg, ok := index[value]
+		if !ok {
+			seg = &revenueSegment{metadata: map[string]string{key: value}}
+			if rates.Base != "" {
+				seg.metadata["currency"] = rates.Base
+			}
+			index[value] = seg
+			segments = append(segments, seg)
+		}
+		return seg
+	}
+
+	if rates.Base != "" {
+		total.metadata["currency"] = rates.Base
+	}
+
+	var skippedContracts int
+	for _, c := range clients {
+		start, _ := clientMRR(c, startDate, rates)
+		end, skipped := clientMRR(c, timestamp, rates)
+		skippedContracts += skipped
+		if start == 0 && end == 0 {
+			continue
+		}
+
+		total.add(c, start, end, timestamp)
+		if c.Source != nil && *c.Source != "" {
+			segmentFor(bySource, "source", *c.Source).add(c, start, end, timestamp)
+		}
+		if c.Channel != nil && *c.Channel != "" {
+			segmentFor(byChannel, "channel", *c.Channel).add(c, start, end, timestamp)
+		}
+	}
+
+	periodDays := timestamp.Sub(startDate).Hours() / 24
+	if periodDays < 1 {
+		periodDays = 1
+	}
+
+	var res []metric.Entity
+	for _, seg := range segments {
+		values := []struct {
+			Type  metric.Type
+			Value float64
+		}{
+			{metric.MRR, seg.mrrEnd},
+			{metric.NewMRR, seg.newMRR},
+			{metric.ChurnedMRR, seg.churnedMRR},
+			{metric.ExpansionMRR, seg.expansionMRR},
+			{metric.ContractionMRR, seg.contractionMRR},
+			{metric.ARPU, seg.arpu()},
+			{metric.LTV, seg.ltv(periodDays)},
+		}
+
+		for _, v := range values {
+			m, err := s.createMetric("", v.Type, v.Value, interval, timestamp, copyMetadata(seg.metadata))
+			if err != nil {
+				return nil, fmt.Errorf("failed to create %s metric: %w", v.Type, err)
+			}
+			res = append(res, m)
+		}
+	}
+
+	logger.Info().
+		Float64("mrr", total.mrrEnd).
+		Float64("new_mrr", total.newMRR).
+		Float64("churned_mrr", total.churnedMRR).
+		Float64("expansion_mrr", total.expansionMRR).
+		Float64("contraction_mrr", total.contractionMRR).
+		Int("paying_clients", total.payingEnd).
+		Int("skipped_contracts", skippedContracts).
+		Str("currency", rates.Base).
+		Msg("Revenue calculation results")
+
+	return res, nil
+}
+
+// copyMetadata returns a copy of metadata so stored metrics never share a map.
+func copyMetadata(metadata map[string]string) map[string]string {
+	res := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		res[k] = v
+	}
+	return res
+}
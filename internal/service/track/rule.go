@@ -0,0 +1,505 @@
+package track
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/reason"
+	"TrackMe/internal/domain/rule"
+	"TrackMe/pkg/log"
+	"TrackMe/pkg/store"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RuleTrackService interface {
+	CreateRule(ctx context.Context, req rule.Request) (rule.Entity, error)
+	GetRule(ctx context.Context, id string) (rule.Entity, error)
+	ListRules(ctx context.Context, filters rule.Filters) ([]rule.Entity, error)
+	UpdateRule(ctx context.Context, id string, req rule.Request) (rule.Entity, error)
+	DeleteRule(ctx context.Context, id string) error
+	DryRunRule(ctx context.Context, id string, req rule.DryRunRequest) ([]rule.Execution, error)
+	ListRuleExecutions(ctx context.Context, filters rule.ExecutionFilters, limit, offset int) ([]rule.Execution, int, error)
+}
+
+// WithRuleRepository applies the given rule and rule execution log repositories to the Service
+func WithRuleRepository(rules rule.Repository, executions rule.ExecutionRepository) Configuration {
+	return func(s *Service) error {
+		s.rules = rules
+		s.ruleExecutions = executions
+		return nil
+	}
+}
+
+// WithRuleNotifiers applies the channels the messages of notify actions are sent through
+func WithRuleNotifiers(notifiers ...rule.Notifier) Configuration {
+	return func(s *Service) error {
+		s.ruleNotifiers = append(s.ruleNotifiers, notifiers...)
+		return nil
+	}
+}
+
+// WithWebhookCaller applies the caller webhook actions post through
+func WithWebhookCaller(caller rule.Caller) Configuration {
+	return func(s *Service) error {
+		s.caller = caller
+		return nil
+	}
+}
+
+// CreateRule creates an automation rule
+func (s *Service) CreateRule(ctx context.Context, req rule.Request) (rule.Entity, error) {
+	if s.rules == nil {
+		return rule.Entity{}, errors.New("rule repository is not configured")
+	}
+	if err := s.checkRuleActions(ctx, req.Actions); err != nil {
+		return rule.Entity{}, err
+	}
+
+	data := rule.New(req)
+	data.CreatedAt = s.clock()
+	data.UpdatedAt = data.CreatedAt
+
+	return s.rules.Create(ctx, data)
+}
+
+// GetRule retrieves a rule by ID
+func (s *Service) GetRule(ctx context.Context, id string) (rule.Entity, error) {
+	if s.rules == nil {
+		return rule.Entity{}, errors.New("rule repository is not configured")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return rule.Entity{}, store.ErrorNotFound
+	}
+
+	return s.rules.Get(ctx, id)
+}
+
+// ListRules retrieves the rules matching the filters in evaluation order
+func (s *Service) ListRules(ctx context.Context, filters rule.Filters) ([]rule.Entity, error) {
+	if s.rules == nil {
+		return nil, errors.New("rule repository is not configured")
+	}
+
+	return s.rules.List(ctx, filters)
+}
+
+// UpdateRule replaces a rule
+func (s *Service) UpdateRule(ctx context.Context, id string, req rule.Request) (rule.Entity, error) {
+	if s.rules == nil {
+		return rule.Entity{}, errors.New("rule repository is not configured")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return rule.Entity{}, store.ErrorNotFound
+	}
+	if err := s.checkRuleActions(ctx, req.Actions); err != nil {
+		return rule.Entity{}, err
+	}
+
+	data := rule.New(req)
+	data.UpdatedAt = s.clock()
+
+	return s.rules.Update(ctx, id, data)
+}
+
+// DeleteRule removes a rule and its execution log
+func (s *Service) DeleteRule(ctx context.Context, id string) error {
+	if s.rules == nil {
+		return errors.New("rule repository is not configured")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return store.ErrorNotFound
+	}
+
+	return s.rules.Delete(ctx, id)
+}
+
+// ListRuleExecutions retrieves the execution log, newest first
+func (s *Service) ListRuleExecutions(ctx context.Context, filters rule.ExecutionFilters, limit, offset int) ([]rule.Execution, int, error) {
+	if s.ruleExecutions == nil {
+		return nil, 0, errors.New("rule execution repository is not configured")
+	}
+
+	return s.ruleExecutions.List(ctx, filters, limit, offset)
+}
+
+// DryRunRule evaluates a rule, enabled or not, against a client or, without one, every client and
+// returns the executions of the matching clients with their actions planned but not taken. Dry
+// runs are recorded in the execution log.
+func (s *Service) DryRunRule(ctx context.Context, id string, req rule.DryRunRequest) ([]rule.Execution, error) {
+	r, err := s.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.Compile(); err != nil {
+		return nil, fmt.Errorf("%w condition: %w", store.ErrorInvalid, err)
+	}
+
+	var clients []client.Entity
+	if req.ClientID != "" {
+		if _, err = uuid.Parse(req.ClientID); err != nil {
+			return nil, fmt.Errorf("%w client_id: %s", store.ErrorInvalid, req.ClientID)
+		}
+		c, err := s.clientRepository.Get(ctx, req.ClientID)
+		if err != nil {
+			if errors.Is(err, store.ErrorNotFound) {
+				return nil, fmt.Errorf("%w client_id: client %s not found", store.ErrorInvalid, req.ClientID)
+			}
+			return nil, err
+		}
+		clients = []client.Entity{c}
+	} else {
+		if clients, err = s.clientRepository.ListAll(ctx, client.Filters{}); err != nil {
+			return nil, fmt.Errorf("failed to list clients: %w", err)
+		}
+	}
+
+	now := s.clock()
+	executions := []rule.Execution{}
+	for _, c := range clients {
+		matched, err := r.Matches(c, "", now)
+		if err != nil {
+			return nil, fmt.Errorf("%w condition: %w", store.ErrorInvalid, err)
+		}
+		if !matched {
+			continue
+		}
+		executions = append(executions, s.executeRule(ctx, r, c, r.Trigger, true))
+	}
+
+	return executions, nil
+}
+
+// RunScheduledRules evaluates the enabled schedule rules due within (from, to] against every
+// client and returns how many rule executions were recorded
+func (s *Service) RunScheduledRules(ctx context.Context, from, to time.Time) (int, error) {
+	if s.rules == nil {
+		return 0, nil
+	}
+
+	enabled := true
+	rules, err := s.loadRules(ctx, rule.Filters{Trigger: rule.TriggerSchedule, Enabled: &enabled})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list schedule rules: %w", err)
+	}
+
+	var due []rule.Entity
+	for _, r := range rules {
+		if r.Due(from.In(s.location), to.In(s.location)) {
+			due = append(due, r)
+		}
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	clients, err := s.clientRepository.ListAll(ctx, client.Filters{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	var executed int
+	for _, r := range due {
+		for _, c := range clients {
+			if !s.ruleMatches(ctx, r, c, "", rule.TriggerSchedule) {
+				continue
+			}
+			s.runRule(ctx, r, c, rule.TriggerSchedule)
+			executed++
+		}
+	}
+	return executed, nil
+}
+
+// ruleContextKey marks the context of actions taken by rules
+type ruleContextKey struct{}
+
+// Rule actions triggered by client writes run in the background, at most ruleWorkers at a time
+// and each execution within ruleExecutionTimeout
+const (
+	ruleWorkers          = 8
+	ruleExecutionTimeout = time.Minute
+)
+
+// loadRules lists the rules matching the filters with their conditions compiled. Rules with a
+// condition that no longer compiles are logged and left out.
+func (s *Service) loadRules(ctx context.Context, filters rule.Filters) ([]rule.Entity, error) {
+	rules, err := s.rules.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := rules[:0]
+	for _, r := range rules {
+		if err = r.Compile(); err != nil {
+			logger := log.LoggerFromContext(ctx)
+			logger.Warn().Err(err).Str("rule_id", r.ID).Msg("failed to compile rule condition")
+			continue
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled, nil
+}
+
+// evaluateRules matches the enabled rules of the trigger against a client after a write and
+// queues the actions of the matching ones, so they do not hold up the write. Writes made by rule
+// actions do not trigger rules again, so rules cannot loop. Failures are logged only, rules must
+// not fail the write that already happened.
+func (s *Service) evaluateRules(ctx context.Context, trigger string, c client.Entity, fromStage string) {
+	if s.rules == nil || ctx.Value(ruleContextKey{}) != nil {
+		return
+	}
+
+	enabled := true
+	rules, err := s.loadRules(ctx, rule.Filters{Trigger: trigger, Enabled: &enabled})
+	if err != nil {
+		logger := log.LoggerFromContext(ctx)
+		logger.Warn().Err(err).Str("trigger", trigger).Msg("failed to list rules")
+		return
+	}
+
+	var matched []rule.Entity
+	for _, r := range rules {
+		if s.ruleMatches(ctx, r, c, fromStage, trigger) {
+			matched = append(matched, r)
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+
+	s.queueRules(ctx, matched, c, trigger)
+}
+
+// queueRules runs the rules matched by a client in order in the background, waiting for a free
+// worker as long as ctx allows. The executions outlive ctx and keep its values.
+func (s *Service) queueRules(ctx context.Context, rules []rule.Entity, c client.Entity, trigger string) {
+	select {
+	case s.ruleSlots <- struct{}{}:
+	case <-ctx.Done():
+		logger := log.LoggerFromContext(ctx)
+		logger.Warn().Err(ctx.Err()).Str("trigger", trigger).Str("client_id", c.ID).Msg("rule actions dropped")
+		return
+	}
+
+	go func() {
+		defer func() { <-s.ruleSlots }()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ruleExecutionTimeout)
+		defer cancel()
+
+		for _, r := range rules {
+			s.runRule(ctx, r, c, trigger)
+		}
+	}()
+}
+
+// ruleMatches evaluates the condition of a rule against a client, logging evaluation failures
+func (s *Service) ruleMatches(ctx context.Context, r rule.Entity, c client.Entity, fromStage, trigger string) bool {
+	matched, err := r.Matches(c, fromStage, s.clock())
+	if err != nil {
+		logger := log.LoggerFromContext(ctx)
+		logger.Warn().Err(err).
+			Str("trigger", trigger).
+			Str("rule_id", r.ID).
+			Str("client_id", c.ID).
+			Msg("failed to evaluate rule condition")
+		return false
+	}
+	return matched
+}
+
+// runRule takes the actions of a matched rule on a client, logging failed actions
+func (s *Service) runRule(ctx context.Context, r rule.Entity, c client.Entity, trigger string) {
+	execution := s.executeRule(ctx, r, c, trigger, false)
+	if execution.Failed() {
+		logger := log.LoggerFromContext(ctx)
+		logger.Warn().
+			Str("trigger", trigger).
+			Str("rule_id", r.ID).
+			Str("client_id", c.ID).
+			Msg("rule action failed")
+	}
+}
+
+// executeRule takes, or on a dry run plans, the actions of a rule on a client in order and
+// records the execution. A failed action does not stop the following ones.
+func (s *Service) executeRule(ctx context.Context, r rule.Entity, c client.Entity, trigger string, dryRun bool) rule.Execution {
+	execution := rule.Execution{
+		RuleID:    r.ID,
+		ClientID:  c.ID,
+		Trigger:   trigger,
+		DryRun:    dryRun,
+		Results:   make([]rule.ActionResult, 0, len(r.Actions)),
+		CreatedAt: s.clock(),
+	}
+
+	actionCtx := context.WithValue(ctx, ruleContextKey{}, r.ID)
+	for _, a := range r.Actions {
+		result := rule.ActionResult{Action: a, Status: rule.StatusPlanned}
+		if !dryRun {
+			updated, err := s.takeAction(actionCtx, r, a, c)
+			if err != nil {
+				result.Status = rule.StatusFailed
+				result.Error = err.Error()
+			} else {
+				result.Status = rule.StatusSucceeded
+				c = updated
+			}
+		}
+		execution.Results = append(execution.Results, result)
+	}
+
+	if s.ruleExecutions != nil {
+		recorded, err := s.ruleExecutions.Add(ctx, execution)
+		if err != nil {
+			logger := log.LoggerFromContext(ctx)
+			logger.Warn().Err(err).Str("rule_id", r.ID).Str("client_id", c.ID).Msg("failed to record rule execution")
+		} else {
+			execution = recorded
+		}
+	}
+	return execution
+}
+
+// takeAction takes an action of a rule on a client and returns the client as it is afterwards
+func (s *Service) takeAction(ctx context.Context, r rule.Entity, a rule.Action, c client.Entity) (client.Entity, error) {
+	switch a.Type {
+	case rule.ActionTransition:
+		return s.moveClient(ctx, c, a.Params["direction"], client.Request{
+			ReasonCode: a.Params["reason_code"],
+			Comment:    a.Params["comment"],
+		})
+
+	case rule.ActionDeactivate:
+		if _, err := s.setClientActivity(ctx, c.ID, client.Deactivated, client.ActivityRequest{
+			Reason:  a.Params["reason_code"],
+			Comment: a.Params["comment"],
+		}); err != nil {
+			return c, err
+		}
+		return s.clientRepository.Get(ctx, c.ID)
+
+	case rule.ActionTag:
+		tag := strings.TrimSpace(a.Params["tag"])
+		if slices.Contains(c.Tags, tag) {
+			return c, nil
+		}
+		tags := append(slices.Clone(c.Tags), tag)
+		if err := s.clientRepository.UpdateTags(ctx, c.ID, tags); err != nil {
+			return c, err
+		}
+		c.Tags = tags
+		return c, nil
+
+	case rule.ActionAssign:
+		userID := strings.TrimSpace(a.Params["user_id"])
+		if err := s.clientRepository.Assign(ctx, c.ID, userID); err != nil {
+			return c, err
+		}
+		c.AssigneeID = &userID
+		return c, nil
+
+	case rule.ActionNotify:
+		if len(s.ruleNotifiers) == 0 {
+			return c, errors.New("no notifiers configured")
+		}
+		subject := fmt.Sprintf("%s: %s", r.Name, clientLabel(c))
+		text := fmt.Sprintf("%s\n\nClient: %s\nStage: %s", a.Params["message"], c.ID, deref(c.CurrentStage))
+
+		var errs []error
+		for _, n := range s.ruleNotifiers {
+			if err := n.Send(ctx, subject, text); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+			}
+		}
+		return c, errors.Join(errs...)
+
+	case rule.ActionWebhook:
+		if s.caller == nil {
+			return c, errors.New("webhook caller is not configured")
+		}
+		payload := map[string]any{
+			"rule":   map[string]string{"id": r.ID, "name": r.Name},
+			"client": client.ParseFromEntity(c),
+		}
+		return c, s.caller.Post(ctx, a.Params["url"], payload)
+	}
+
+	return c, fmt.Errorf("unknown action type %s", a.Type)
+}
+
+// moveClient moves a client to the next or previous stage as UpdateClient does, with the reason
+// code and comment of req for a move back
+func (s *Service) moveClient(ctx context.Context, c client.Entity, direction string, req client.Request) (client.Entity, error) {
+	fromStage := deref(c.CurrentStage)
+
+	newStage, err := s.StageRepository.UpdateStage(ctx, fromStage, direction)
+	if err != nil {
+		return c, errors.New("invalid stage transition: " + err.Error())
+	}
+
+	if direction == "prev" {
+		if err = s.reasons.Check(reason.Rollback, req.ReasonCode, fromStage); err != nil {
+			return c, err
+		}
+		if err = s.calculateRollbackCount(ctx, s.clock()); err != nil {
+			return c, err
+		}
+	} else {
+		req = client.Request{}
+	}
+
+	now := s.clock()
+	c.CurrentStage = &newStage
+	c.LastUpdated = &now
+	if err = s.scoreClient(ctx, &c); err != nil {
+		return c, err
+	}
+
+	result, err := s.clientRepository.Update(ctx, c.ID, c)
+	if err != nil {
+		return c, err
+	}
+
+	if newStage != fromStage {
+		s.recordTransition(ctx, result, fromStage, req)
+	}
+	return result, nil
+}
+
+// checkRuleActions validates the actions of a rule against the current data: assigned users must
+// exist
+func (s *Service) checkRuleActions(ctx context.Context, actions []rule.Action) error {
+	for i, a := range actions {
+		if a.Type != rule.ActionAssign {
+			continue
+		}
+		userID := strings.TrimSpace(a.Params["user_id"])
+		if _, err := uuid.Parse(userID); err != nil {
+			return fmt.Errorf("%w actions[%d].params.user_id: %s", store.ErrorInvalid, i, userID)
+		}
+		if _, err := s.userRepository.Get(ctx, userID); err != nil {
+			if errors.Is(err, store.ErrorNotFound) {
+				return fmt.Errorf("%w actions[%d].params.user_id: user %s not found", store.ErrorInvalid, i, userID)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// clientLabel names a client in messages
+func clientLabel(c client.Entity) string {
+	if name := deref(c.Name); name != "" {
+		return name
+	}
+	if email := deref(c.Email); email != "" {
+		return email
+	}
+	return c.ID
+}
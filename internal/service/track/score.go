@@ -0,0 +1,125 @@
+package track
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/currency"
+	"TrackMe/internal/domain/score"
+	"TrackMe/pkg/log"
+	"context"
+	"fmt"
+	"time"
+)
+
+// WithScoringModel applies the lead scoring model of the repository. Without a model clients are
+// not scored.
+func WithScoringModel(scoring score.Repository) Configuration {
+	return func(s *Service) error {
+		model, err := scoring.Model(context.Background())
+		if err != nil {
+			return err
+		}
+		s.scoring = model
+		return nil
+	}
+}
+
+// scoreClient sets the lead score of a client about to be written, as of now
+func (s *Service) scoreClient(ctx context.Context, c *client.Entity) error {
+	if s.scoring == nil {
+		return nil
+	}
+
+	progress, err := s.stageProgress(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock()
+	rates, err := s.scoringRates(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	amount, _ := clientMRR(*c, now, rates)
+	value := s.scoring.Score(*c, progress(c.CurrentStage), amount, now)
+	c.Score = &value
+	return nil
+}
+
+// RescoreClients recalculates the lead score of every client, as the recency of their last login
+// changes with time, and returns how many scores changed.
+func (s *Service) RescoreClients(ctx context.Context) (int, error) {
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.score").Logger()
+
+	if s.scoring == nil {
+		return 0, nil
+	}
+
+	progress, err := s.stageProgress(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	clients, err := s.clientRepository.ListAll(ctx, client.Filters{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	now := s.clock()
+	rates, err := s.scoringRates(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var changed int
+	for _, c := range clients {
+		amount, _ := clientMRR(c, now, rates)
+		value := s.scoring.Score(c, progress(c.CurrentStage), amount, now)
+		if c.Score != nil && *c.Score == value {
+			continue
+		}
+		if err = s.clientRepository.UpdateScore(ctx, c.ID, &value); err != nil {
+			logger.Warn().Err(err).Str("client_id", c.ID).Msg("failed to update client score")
+			continue
+		}
+		changed++
+	}
+
+	logger.Info().Int("clients", len(clients)).Int("changed", changed).Msg("Client scores recalculated")
+	return changed, nil
+}
+
+// scoringRates returns the exchange rates converting the monthly amount of clients to the base
+// currency. They are fetched only when the model scores the amount; contracts in currencies
+// without a rate do not count.
+func (s *Service) scoringRates(ctx context.Context, date time.Time) (currency.Rates, error) {
+	if !s.scoring.Uses(score.MonthlyAmount) {
+		return currency.Rates{Base: s.baseCurrency, Date: date}, nil
+	}
+
+	rates, err := s.exchangeRates(ctx, date)
+	if err != nil {
+		return currency.Rates{}, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// stageProgress returns a function mapping a stage to its position in the funnel, from 0 for the
+// first stage to 1 for the last one. Unknown stages are at 0.
+func (s *Service) stageProgress(ctx context.Context) (func(stage *string) float64, error) {
+	stages, err := s.StageRepository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stages: %w", err)
+	}
+
+	order := make(map[string]float64, len(stages))
+	for i, st := range stages {
+		if len(stages) > 1 {
+			order[st.ID] = float64(i) / float64(len(stages)-1)
+		}
+	}
+
+	return func(stage *string) float64 {
+		return order[deref(stage)]
+	}, nil
+}
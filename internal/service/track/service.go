@@ -1,10 +1,25 @@
 package track
 
 import (
+	"TrackMe/internal/domain/alert"
+	"TrackMe/internal/domain/annotation"
 	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/currency"
+	"TrackMe/internal/domain/experiment"
+	"TrackMe/internal/domain/job"
 	"TrackMe/internal/domain/metric"
+	"TrackMe/internal/domain/reason"
+	"TrackMe/internal/domain/rule"
+	"TrackMe/internal/domain/score"
 	"TrackMe/internal/domain/stage"
+	"TrackMe/internal/domain/touchpoint"
+	"TrackMe/internal/domain/transition"
 	"TrackMe/internal/domain/user"
+	"TrackMe/pkg/calendar"
+	"context"
+	"fmt"
+	"strings"
+	"time"
 )
 
 // Configuration is an alias for a function that will take in a pointer to a Service and modify it
@@ -17,13 +32,55 @@ type Service struct {
 	StageRepository  stage.Repository
 	MetricRepository metric.Repository
 	MetricCache      metric.Cache
+	jobRepository    job.Repository
+	runRepository    metric.RunRepository
+	transitions      transition.Repository
+	currencyProvider currency.Provider
+	baseCurrency     string
+	clock            func() time.Time
+	stepTimeout      time.Duration
+	stepRetries      int
+	calculators      *metric.Registry
+	calendar         *calendar.Calendar
+	location         *time.Location
+	alerts           alert.Repository
+	alertRules       map[string]alert.Rule
+	notifiers        []alert.Notifier
+	goals            metric.GoalRepository
+	annotations      annotation.Repository
+	experiments      experiment.Repository
+	touchpoints      touchpoint.Repository
+	activity         client.ActivityRepository
+	reasons          reason.Taxonomy
+	scoring          *score.Model
+	rules            rule.Repository
+	ruleExecutions   rule.ExecutionRepository
+	ruleNotifiers    []rule.Notifier
+	ruleSlots        chan struct{}
+	caller           rule.Caller
 }
 
 // New takes a variable amount of Configuration functions and returns a new Service
 // Each Configuration will be called in the order they are passed in
 func New(configs ...Configuration) (s *Service, err error) {
 	// Add the service
-	s = &Service{}
+	s = &Service{
+		clock:       time.Now,
+		stepTimeout: 2 * time.Minute,
+		stepRetries: 2,
+		calculators: metric.NewRegistry(),
+		calendar:    calendar.Default(time.UTC),
+		location:    time.UTC,
+		alertRules:  make(map[string]alert.Rule),
+		ruleSlots:   make(chan struct{}, ruleWorkers),
+	}
+
+	// Register the built-in calculators ahead of any added by configurations
+	for _, c := range s.builtinCalculators() {
+		if err = s.calculators.Register(c); err != nil {
+			return
+		}
+	}
 
 	// Apply all Configurations passed in
 	for _, cfg := range configs {
@@ -80,6 +137,30 @@ func WithMetricCache(metricCache metric.Cache) Configuration {
 	}
 }
 
+// WithJobRepository applies a given metric calculation job repository to the Service
+func WithJobRepository(jobRepository job.Repository) Configuration {
+	return func(s *Service) error {
+		s.jobRepository = jobRepository
+		return nil
+	}
+}
+
+// WithMetricRunRepository applies a given metric run repository to the Service
+func WithMetricRunRepository(runRepository metric.RunRepository) Configuration {
+	return func(s *Service) error {
+		s.runRepository = runRepository
+		return nil
+	}
+}
+
+// WithTransitionRepository applies a given stage transition event repository to the Service
+func WithTransitionRepository(transitions transition.Repository) Configuration {
+	return func(s *Service) error {
+		s.transitions = transitions
+		return nil
+	}
+}
+
 // WithUserRepository applies a given user repository to the Service
 func WithUserRepository(userRepository user.Repository) Configuration {
 	return func(s *Service) error {
@@ -87,3 +168,95 @@ func WithUserRepository(userRepository user.Repository) Configuration {
 		return nil
 	}
 }
+
+// WithCurrencyProvider applies a given exchange-rate provider and the base currency
+// revenue aggregates are normalized to
+func WithCurrencyProvider(currencyProvider currency.Provider, baseCurrency string) Configuration {
+	return func(s *Service) error {
+		s.currencyProvider = currencyProvider
+		s.baseCurrency = strings.ToUpper(baseCurrency)
+		return nil
+	}
+}
+
+// WithTimezone applies the reporting timezone (an IANA name such as Asia/Almaty) that metric
+// period boundaries and creation times are expressed in
+func WithTimezone(name string) Configuration {
+	return func(s *Service) error {
+		if name == "" {
+			return nil
+		}
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", name, err)
+		}
+		s.location = location
+		return nil
+	}
+}
+
+// WithCalendar applies the business calendar that business-time durations and SLAs are measured in
+func WithCalendar(cal *calendar.Calendar) Configuration {
+	return func(s *Service) error {
+		if cal != nil {
+			s.calendar = cal
+		}
+		return nil
+	}
+}
+
+// WithStepPolicy applies the timeout of a single metric calculation attempt and the number of
+// times a failed calculation is retried
+func WithStepPolicy(timeout time.Duration, retries int) Configuration {
+	return func(s *Service) error {
+		if timeout > 0 {
+			s.stepTimeout = timeout
+		}
+		if retries >= 0 {
+			s.stepRetries = retries
+		}
+		return nil
+	}
+}
+
+// WithCalculators registers additional metric calculators, run after the built-in ones
+func WithCalculators(calculators ...metric.Calculator) Configuration {
+	return func(s *Service) error {
+		for _, c := range calculators {
+			if err := s.calculators.Register(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithMetricDefinitions registers a calculator for every declarative metric definition of the
+// repository, run after the built-in calculators
+func WithMetricDefinitions(definitions metric.DefinitionRepository) Configuration {
+	return func(s *Service) error {
+		list, err := definitions.List(context.Background())
+		if err != nil {
+			return err
+		}
+
+		for _, d := range list {
+			c, err := newDefinitionCalculator(s, d)
+			if err != nil {
+				return err
+			}
+			if err = s.calculators.Register(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithClock applies a given clock to the Service, used as the default as-of time of metric calculations
+func WithClock(clock func() time.Time) Configuration {
+	return func(s *Service) error {
+		s.clock = clock
+		return nil
+	}
+}
@@ -0,0 +1,293 @@
+package track
+
+import (
+	"TrackMe/internal/domain/client"
+	"TrackMe/internal/domain/metric"
+	"TrackMe/internal/domain/touchpoint"
+	"TrackMe/pkg/log"
+	"TrackMe/pkg/store"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WithTouchpointRepository applies a given touchpoint repository to the Service
+func WithTouchpointRepository(touchpoints touchpoint.Repository) Configuration {
+	return func(s *Service) error {
+		s.touchpoints = touchpoints
+		return nil
+	}
+}
+
+// AddTouchpoint ingests a touchpoint event of a client, e.g. an ad click, without changing the
+// source and channel of the client. A zero occurrence time means now.
+func (s *Service) AddTouchpoint(ctx context.Context, clientID string, req touchpoint.Request) (touchpoint.Entity, error) {
+	if s.touchpoints == nil {
+		return touchpoint.Entity{}, errors.New("touchpoint repository is not configured")
+	}
+	if _, err := uuid.Parse(clientID); err != nil {
+		return touchpoint.Entity{}, store.ErrorNotFound
+	}
+	c, err := s.clientRepository.Get(ctx, clientID)
+	if err != nil {
+		return touchpoint.Entity{}, err
+	}
+
+	data := touchpoint.Entity{
+		ClientID:  clientID,
+		Source:    req.Source,
+		Channel:   req.Channel,
+		Origin:    touchpoint.OriginEvent,
+		CreatedAt: req.OccurredAt,
+	}
+	if data.CreatedAt.IsZero() {
+		data.CreatedAt = s.clock()
+	}
+
+	if err := s.addInitialTouchpoint(ctx, c, data.CreatedAt); err != nil {
+		return touchpoint.Entity{}, err
+	}
+	if err := s.touchpoints.Add(ctx, data); err != nil {
+		return touchpoint.Entity{}, err
+	}
+	return data, nil
+}
+
+// ListTouchpoints retrieves the touchpoint history of a client, oldest first
+func (s *Service) ListTouchpoints(ctx context.Context, clientID string) ([]touchpoint.Entity, error) {
+	if s.touchpoints == nil {
+		return nil, errors.New("touchpoint repository is not configured")
+	}
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, store.ErrorNotFound
+	}
+	if _, err := s.clientRepository.Get(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	res, err := s.touchpoints.ListByClient(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		res = []touchpoint.Entity{}
+	}
+	return res, nil
+}
+
+// recordTouchpoint stores the source and channel of a client as a touchpoint when they differ from
+// the previous ones. Failures are logged only, touchpoints feed attribution and must not block
+// client writes.
+func (s *Service) recordTouchpoint(ctx context.Context, c client.Entity, previous client.Entity) {
+	if s.touchpoints == nil {
+		return
+	}
+
+	source, channel := deref(c.Source), deref(c.Channel)
+	if source == "" && channel == "" {
+		return
+	}
+	if source == deref(previous.Source) && channel == deref(previous.Channel) {
+		return
+	}
+
+	data := touchpoint.Entity{
+		ClientID:  c.ID,
+		Source:    source,
+		Channel:   channel,
+		Origin:    touchpoint.OriginClient,
+		CreatedAt: time.Now(),
+	}
+	if c.LastUpdated != nil {
+		data.CreatedAt = *c.LastUpdated
+	}
+
+	logger := log.LoggerFromContext(ctx)
+
+	// The values a client had before its first recorded change are its first touch
+	if previous.ID != "" {
+		if err := s.addInitialTouchpoint(ctx, previous, data.CreatedAt); err != nil {
+			logger.Warn().
+				Err(err).
+				Str("client_id", c.ID).
+				Msg("failed to record initial touchpoint")
+		}
+	}
+
+	if err := s.touchpoints.Add(ctx, data); err != nil {
+		logger.Warn().
+			Err(err).
+			Str("client_id", c.ID).
+			Msg("failed to record touchpoint")
+	}
+}
+
+// addInitialTouchpoint stores the source and channel of the client snapshot as its first
+// touchpoint when the client has none yet, so a touchpoint recorded at next does not replace
+// the values the client was acquired with. The snapshot is dated at its last update, or just
+// before next when that is not earlier.
+func (s *Service) addInitialTouchpoint(ctx context.Context, c client.Entity, next time.Time) error {
+	source, channel := deref(c.Source), deref(c.Channel)
+	if source == "" && channel == "" {
+		return nil
+	}
+
+	history, err := s.touchpoints.ListByClient(ctx, c.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list touchpoints: %w", err)
+	}
+	if len(history) > 0 {
+		return nil
+	}
+
+	data := touchpoint.Entity{
+		ClientID:  c.ID,
+		Source:    source,
+		Channel:   channel,
+		Origin:    touchpoint.OriginClient,
+		CreatedAt: next.Add(-time.Second),
+	}
+	if c.LastUpdated != nil && c.LastUpdated.Before(next) {
+		data.CreatedAt = *c.LastUpdated
+	}
+	return s.touchpoints.Add(ctx, data)
+}
+
+// calculateSourceConversion calculates the conversion rate per source under every attribution model
+func (s *Service) calculateSourceConversion(ctx context.Context, period metric.Period) ([]metric.Entity, error) {
+	return s.calculateAttributedConversion(ctx, period, metric.SourceConversion, "source")
+}
+
+// calculateChannelConversion calculates the conversion rate per channel under every attribution model
+func (s *Service) calculateChannelConversion(ctx context.Context, period metric.Period) ([]metric.Entity, error) {
+	return s.calculateAttributedConversion(ctx, period, metric.ChannelConversion, "channel")
+}
+
+// calculateAttributedConversion calculates, per value of the dimension (source or channel) and
+// attribution model, the share of the clients active in the period that are in the last stage.
+// Every client is credited to the values of its touchpoints by the model; a client without
+// touchpoints is credited fully to its current value.
+func (s *Service) calculateAttributedConversion(ctx context.Context, period metric.Period, metricType metric.Type, dimension string) ([]metric.Entity, error) {
+	logger := log.LoggerFromContext(ctx).With().Str("component", "service.track.metric."+dimension+"_conversion").Logger()
+
+	stages, err := s.StageRepository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stages: %w", err)
+	}
+
+	if len(stages) < 2 {
+		return nil, nil
+	}
+
+	lastStage := stages[len(stages)-1].ID
+	startDate, timestamp, interval := period.Start, period.AsOf, period.Interval
+
+	logger.Info().
+		Time("start_date", startDate).
+		Time("end_date", timestamp).
+		Str("interval", interval).
+		Msg("Calculating " + dimension + " conversion")
+
+	clients, err := s.clientRepository.ListAll(ctx, client.Filters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	touches := make(map[string][]string)
+	if s.touchpoints != nil {
+		events, err := s.touchpoints.List(ctx, timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list touchpoints: %w", err)
+		}
+		for _, e := range events {
+			value := e.Source
+			if dimension == "channel" {
+				value = e.Channel
+			}
+			touches[e.ClientID] = append(touches[e.ClientID], value)
+		}
+	}
+
+	total := make(map[string]map[string]float64, len(touchpoint.Models))
+	completed := make(map[string]map[string]float64, len(touchpoint.Models))
+	for _, model := range touchpoint.Models {
+		total[model] = make(map[string]float64)
+		completed[model] = make(map[string]float64)
+	}
+
+	// Clients active within the time period
+	for _, c := range clients {
+		if c.LastUpdated == nil || c.LastUpdated.Before(startDate) || c.LastUpdated.After(timestamp) {
+			continue
+		}
+
+		values := touches[c.ID]
+		if len(values) == 0 {
+			current := c.Source
+			if dimension == "channel" {
+				current = c.Channel
+			}
+			values = []string{deref(current)}
+		}
+		converted := deref(c.CurrentStage) == lastStage
+
+		for _, model := range touchpoint.Models {
+			for value, credit := range touchpoint.Credit(model, values) {
+				total[model][value] += credit
+				if converted {
+					completed[model][value] += credit
+				}
+			}
+		}
+	}
+
+	var res []metric.Entity
+	for _, model := range touchpoint.Models {
+		values := make([]string, 0, len(total[model]))
+		for value := range total[model] {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+
+		for _, value := range values {
+			var conversionRate float64
+			if total[model][value] > 0 {
+				conversionRate = completed[model][value] / total[model][value]
+			}
+
+			logger.Info().
+				Str(dimension, value).
+				Str("model", model).
+				Float64("total", total[model][value]).
+				Float64("completed", completed[model][value]).
+				Float64("conversion_rate", conversionRate).
+				Msg("Attributed conversion calculation results")
+
+			m, err := s.createMetric("",
+				metricType,
+				conversionRate,
+				interval,
+				timestamp,
+				map[string]string{dimension: value, "model": model},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create %s metric: %w", metricType, err)
+			}
+			res = append(res, m)
+		}
+	}
+
+	return res, nil
+}
+
+// deref returns the value of a string pointer, empty for nil
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
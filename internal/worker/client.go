@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"TrackMe/internal/service/track"
+	"TrackMe/pkg/log"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// inactivitySchedule is the cron spec of the inactivity check, daily at 01:00 in the reporting timezone
+const inactivitySchedule = "0 0 1 * * *"
+
+// scoreSchedule is the cron spec of the lead score recalculation, daily at 02:00 in the reporting
+// timezone, after the inactivity check
+const scoreSchedule = "0 0 2 * * *"
+
+// ruleSchedule is the cron spec schedule rules are checked on, every minute
+const ruleSchedule = "0 * * * * *"
+
+// ClientWorker handles scheduled client maintenance
+type ClientWorker struct {
+	trackService *track.Service
+	threshold    time.Duration
+	lastRuleRun  time.Time
+	cron         *cron.Cron
+	wg           sync.WaitGroup
+	ctx          context.Context
+	cancel       context.CancelFunc
+}
+
+// NewClientWorker creates a new client worker deactivating clients whose last login is older than
+// the threshold, a zero threshold disables the check, recalculating lead scores nightly and
+// running schedule rules.
+func NewClientWorker(trackService *track.Service, threshold time.Duration) *ClientWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ClientWorker{
+		trackService: trackService,
+		threshold:    threshold,
+		cron:         cron.New(cron.WithSeconds(), cron.WithLocation(trackService.Location())),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Start schedules the inactivity check, the lead score recalculation and the schedule rules
+func (w *ClientWorker) Start() {
+	logger := log.LoggerFromContext(w.ctx).With().Str("component", "worker.client").Logger()
+	logger.Info().Dur("threshold", w.threshold).Msg("Starting client worker")
+
+	if w.threshold <= 0 {
+		logger.Info().Msg("Client inactivity check is disabled")
+	} else if _, err := w.cron.AddFunc(inactivitySchedule, w.job(func(ctx context.Context) error {
+		_, err := w.trackService.DeactivateInactiveClients(ctx, w.threshold)
+		return err
+	}, "Failed to deactivate inactive clients")); err != nil {
+		logger.Error().Err(err).Msg("Failed to schedule the inactivity check")
+		return
+	}
+
+	if _, err := w.cron.AddFunc(scoreSchedule, w.job(func(ctx context.Context) error {
+		_, err := w.trackService.RescoreClients(ctx)
+		return err
+	}, "Failed to recalculate client scores")); err != nil {
+		logger.Error().Err(err).Msg("Failed to schedule the score recalculation")
+		return
+	}
+
+	// A tick is skipped while the previous one still runs, the next one catches up from lastRuleRun
+	w.lastRuleRun = time.Now()
+	runRules := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(w.job(func(ctx context.Context) error {
+		now := time.Now()
+		_, err := w.trackService.RunScheduledRules(ctx, w.lastRuleRun, now)
+		w.lastRuleRun = now
+		return err
+	}, "Failed to run schedule rules")))
+	if _, err := w.cron.AddJob(ruleSchedule, runRules); err != nil {
+		logger.Error().Err(err).Msg("Failed to schedule the rule runs")
+		return
+	}
+
+	w.cron.Start()
+}
+
+// job wraps a scheduled task with the wait group, a timeout and error logging
+func (w *ClientWorker) job(task func(ctx context.Context) error, msg string) func() {
+	return func() {
+		w.wg.Add(1)
+		defer w.wg.Done()
+
+		ctx, cancel := context.WithTimeout(w.ctx, 5*time.Minute)
+		defer cancel()
+
+		if err := task(ctx); err != nil {
+			logger := log.LoggerFromContext(ctx).With().Str("component", "worker.client").Logger()
+			logger.Error().Err(err).Msg(msg)
+		}
+	}
+}
+
+// Stop gracefully shuts down the client worker
+func (w *ClientWorker) Stop() {
+	ctx := w.cron.Stop()
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		logger := log.LoggerFromContext(w.ctx)
+		logger.Warn().Msg("Client jobs did not complete before timeout")
+	case <-ctx.Done():
+	}
+}
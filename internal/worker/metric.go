@@ -25,69 +25,50 @@ func NewMetricWorker(trackService *track.Service) *MetricWorker {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &MetricWorker{
 		trackService: trackService,
-		cron:         cron.New(cron.WithSeconds()),
+		cron:         cron.New(cron.WithSeconds(), cron.WithLocation(trackService.Location())),
 		ctx:          ctx,
 		cancel:       cancel,
 	}
 }
 
-// Start begins the background metric calculation process
+// metricSchedules maps every interval to the cron spec its calculations run at, in the reporting
+// timezone: daily at midnight, weekly on Sunday at midnight and monthly on the 1st at midnight
+var metricSchedules = map[string]string{
+	"day":   "0 0 0 * * *",
+	"week":  "0 0 0 * * 0",
+	"month": "0 0 0 1 * *",
+}
+
+// Start begins the background metric calculation process. Only intervals with at least one
+// registered calculator are scheduled.
 func (w *MetricWorker) Start() {
 	logger := log.LoggerFromContext(w.ctx).With().Str("component", "worker.metric").Logger()
 	logger.Info().Msg("Starting metric worker")
 
-	// Run daily calculations at midnight
-	_, err := w.cron.AddFunc("0 0 0 * * *", func() {
-		logger.Info().Msg("Running daily metric calculations")
-		logger.Info().Msg("Running daily metric calculations")
-		w.wg.Add(1)
-		defer w.wg.Done()
-
-		ctx, cancel := context.WithTimeout(w.ctx, 5*time.Minute)
-		defer cancel()
-
-		if err := w.trackService.CalculateAllMetrics(ctx, "day"); err != nil {
-			logger.Error().Err(err).Msg("Failed to calculate daily metrics")
+	for _, interval := range w.trackService.MetricIntervals() {
+		spec, ok := metricSchedules[interval]
+		if !ok {
+			continue
 		}
-	})
-	if err != nil {
-		logger.Error().Err(err).Msg("Failed to schedule daily metrics")
-
-	}
 
-	// Run weekly calculations on Sunday at midnight
-	_, err = w.cron.AddFunc("0 0 0 * * 0", func() {
-		logger.Info().Msg("Running weekly metric calculations")
-		w.wg.Add(1)
-		defer w.wg.Done()
-
-		ctx, cancel := context.WithTimeout(w.ctx, 5*time.Minute)
-		defer cancel()
-
-		if err := w.trackService.CalculateAllMetrics(ctx, "week"); err != nil {
-			logger.Error().Err(err).Msg("Failed to calculate weekly metrics")
+		interval := interval
+		_, err := w.cron.AddFunc(spec, func() {
+			logger.Info().Str("interval", interval).Msg("Running scheduled metric calculations")
+			w.wg.Add(1)
+			defer w.wg.Done()
+
+			ctx, cancel := context.WithTimeout(w.ctx, 5*time.Minute)
+			defer cancel()
+
+			if err := w.trackService.RunMetricJob(ctx, interval, time.Time{}); err != nil {
+				logger.Error().Err(err).Str("interval", interval).Msg("Failed to calculate metrics")
+			}
+		})
+		if err != nil {
+			logger.Error().Err(err).Str("interval", interval).Msg("Failed to schedule metrics")
 		}
-	})
-	if err != nil {
-		logger.Error().Err(err).Msg("Failed to schedule weekly metrics")
 	}
 
-	// Run monthly calculations on the 1st of each month at midnight
-	_, err = w.cron.AddFunc("0 0 0 1 * *", func() {
-		logger.Info().Msg("Running monthly metric calculations")
-		w.wg.Add(1)
-		defer w.wg.Done()
-
-		ctx, cancel := context.WithTimeout(w.ctx, 5*time.Minute)
-		defer cancel()
-
-		if err := w.trackService.CalculateAllMetrics(ctx, "month"); err != nil {
-			logger.Error().Err(err).Msg("Failed to calculate monthly metrics")
-		}
-	})
-	if err != nil {
-		logger.Error().Err(err).Msg("Failed to schedule monthly metrics")
-	}
 	// Start the cron scheduler
 	w.cron.Start()
 }
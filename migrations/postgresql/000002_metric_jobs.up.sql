@@ -0,0 +1,17 @@
+CREATE TABLE metric_jobs (
+    id UUID PRIMARY KEY,
+    metric_interval VARCHAR(10) NOT NULL,
+    as_of TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL,
+    steps JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Only one job per interval may be pending or running at a time
+CREATE UNIQUE INDEX metric_jobs_active_interval_idx ON metric_jobs (metric_interval)
+    WHERE status IN ('pending', 'running');
+
+CREATE INDEX metric_jobs_created_at_idx ON metric_jobs (created_at DESC);
@@ -0,0 +1,12 @@
+CREATE TABLE metric_runs (
+    id UUID PRIMARY KEY,
+    job_id UUID REFERENCES metric_jobs(id) ON DELETE SET NULL,
+    metric_interval VARCHAR(10) NOT NULL,
+    as_of TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    results JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX metric_runs_interval_started_at_idx ON metric_runs (metric_interval, started_at DESC);
@@ -0,0 +1,17 @@
+CREATE TABLE alerts (
+    id UUID PRIMARY KEY,
+    metric_id VARCHAR(255) NOT NULL,
+    metric_type VARCHAR(100) NOT NULL,
+    metric_interval VARCHAR(10) NOT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    value DOUBLE PRECISION NOT NULL,
+    baseline DOUBLE PRECISION NOT NULL,
+    stddev DOUBLE PRECISION NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    metric_created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX alerts_type_detected_at_idx ON alerts (metric_type, detected_at DESC);
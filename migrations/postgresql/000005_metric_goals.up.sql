@@ -0,0 +1,11 @@
+CREATE TABLE metric_goals (
+    id UUID PRIMARY KEY,
+    metric_type VARCHAR(100) NOT NULL,
+    metric_interval VARCHAR(10) NOT NULL,
+    operator VARCHAR(3) NOT NULL,
+    target DOUBLE PRECISION NOT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX metric_goals_type_idx ON metric_goals (metric_type);
@@ -0,0 +1,15 @@
+CREATE TABLE metric_annotations (
+    id UUID PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    source VARCHAR(100) NOT NULL DEFAULT '',
+    channel VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX metric_annotations_range_idx ON metric_annotations (starts_at, ends_at);
+CREATE INDEX metric_annotations_tags_idx ON metric_annotations USING GIN (tags);
@@ -0,0 +1,17 @@
+CREATE TABLE experiments (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    goal_stage VARCHAR(100) NOT NULL,
+    variants JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE experiment_assignments (
+    experiment_id UUID NOT NULL REFERENCES experiments (id) ON DELETE CASCADE,
+    client_id VARCHAR(255) NOT NULL,
+    variant VARCHAR(100) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (experiment_id, client_id)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE clients ADD COLUMN score SMALLINT;
+
+CREATE INDEX clients_score_idx ON clients (score DESC NULLS LAST);
@@ -0,0 +1,30 @@
+ALTER TABLE clients ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE clients ADD COLUMN assignee_id UUID REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE TABLE rules (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    trigger VARCHAR(20) NOT NULL,
+    schedule VARCHAR(100) NOT NULL DEFAULT '',
+    condition TEXT NOT NULL DEFAULT '',
+    actions JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX rules_trigger_idx ON rules (trigger, enabled);
+
+CREATE TABLE rule_executions (
+    id UUID PRIMARY KEY,
+    rule_id UUID NOT NULL REFERENCES rules (id) ON DELETE CASCADE,
+    client_id VARCHAR(255) NOT NULL,
+    trigger VARCHAR(20) NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    results JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX rule_executions_rule_idx ON rule_executions (rule_id, created_at DESC);
+CREATE INDEX rule_executions_client_idx ON rule_executions (client_id, created_at DESC);
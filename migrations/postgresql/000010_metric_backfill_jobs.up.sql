@@ -0,0 +1,7 @@
+ALTER TABLE metric_jobs ADD COLUMN backfill_from TIMESTAMP WITH TIME ZONE;
+ALTER TABLE metric_jobs ADD COLUMN backfill_to TIMESTAMP WITH TIME ZONE;
+
+-- Backfills run longer than calculations, every job carries the time it stops holding its interval
+ALTER TABLE metric_jobs ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+UPDATE metric_jobs SET expires_at = created_at + INTERVAL '30 minutes';
+ALTER TABLE metric_jobs ALTER COLUMN expires_at SET NOT NULL;
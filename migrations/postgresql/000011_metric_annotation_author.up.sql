@@ -0,0 +1 @@
+ALTER TABLE metric_annotations ADD COLUMN created_by UUID REFERENCES users (id) ON DELETE SET NULL;
@@ -0,0 +1,193 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	// Embed the timezone database, the runtime image ships without one
+	_ "time/tzdata"
+
+	"gopkg.in/yaml.v2"
+)
+
+var ErrInvalidWorkingHours = errors.New("working hours: start must be before end")
+
+// Calendar describes the working time of a business: its timezone, daily working hours,
+// working weekdays and holidays.
+type Calendar struct {
+	location *time.Location
+	start    time.Duration // offset of the working day start from midnight
+	end      time.Duration // offset of the working day end from midnight
+	workdays map[time.Weekday]bool
+	holidays map[string]bool
+}
+
+// New creates a calendar. start and end are offsets from midnight in location.
+func New(location *time.Location, start, end time.Duration, workdays []time.Weekday, holidays []string) (*Calendar, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	if start < 0 || end > 24*time.Hour || start >= end {
+		return nil, ErrInvalidWorkingHours
+	}
+
+	c := &Calendar{
+		location: location,
+		start:    start,
+		end:      end,
+		workdays: make(map[time.Weekday]bool, len(workdays)),
+		holidays: make(map[string]bool, len(holidays)),
+	}
+	for _, d := range workdays {
+		c.workdays[d] = true
+	}
+	for _, h := range holidays {
+		if _, err := time.Parse("2006-01-02", h); err != nil {
+			return nil, fmt.Errorf("holiday %q: must be YYYY-MM-DD", h)
+		}
+		c.holidays[h] = true
+	}
+
+	return c, nil
+}
+
+// Default returns a Monday to Friday, 09:00 to 18:00 calendar without holidays.
+func Default(location *time.Location) *Calendar {
+	c, _ := New(location, 9*time.Hour, 18*time.Hour, []time.Weekday{
+		time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+	}, nil)
+	return c
+}
+
+// Load reads a calendar from a yaml file:
+//
+//	timezone: Asia/Almaty
+//	working_hours:
+//	  start: "09:00"
+//	  end: "18:00"
+//	workdays: [monday, tuesday, wednesday, thursday, friday]
+//	holidays: [2026-01-01, 2026-03-08]
+func Load(path string) (*Calendar, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var config struct {
+		Timezone     string `yaml:"timezone"`
+		WorkingHours struct {
+			Start string `yaml:"start"`
+			End   string `yaml:"end"`
+		} `yaml:"working_hours"`
+		Workdays []string `yaml:"workdays"`
+		Holidays []string `yaml:"holidays"`
+	}
+
+	if err = yaml.Unmarshal(file, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	location := time.UTC
+	if config.Timezone != "" {
+		if location, err = time.LoadLocation(config.Timezone); err != nil {
+			return nil, fmt.Errorf("timezone: %w", err)
+		}
+	}
+
+	start, err := parseClock(config.WorkingHours.Start, 9*time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("working_hours.start: %w", err)
+	}
+	end, err := parseClock(config.WorkingHours.End, 18*time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("working_hours.end: %w", err)
+	}
+
+	workdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	if len(config.Workdays) > 0 {
+		workdays = workdays[:0]
+		for _, name := range config.Workdays {
+			day, err := parseWeekday(name)
+			if err != nil {
+				return nil, fmt.Errorf("workdays: %w", err)
+			}
+			workdays = append(workdays, day)
+		}
+	}
+
+	return New(location, start, end, workdays, config.Holidays)
+}
+
+// Location returns the timezone of the calendar.
+func (c *Calendar) Location() *time.Location {
+	return c.location
+}
+
+// WorkingDay returns the length of a working day.
+func (c *Calendar) WorkingDay() time.Duration {
+	return c.end - c.start
+}
+
+// IsWorkday reports whether the date of t in the calendar timezone is a working day.
+func (c *Calendar) IsWorkday(t time.Time) bool {
+	t = t.In(c.location)
+	return c.workdays[t.Weekday()] && !c.holidays[t.Format("2006-01-02")]
+}
+
+// BusinessDuration returns the working time between from and to. It is zero when to is not after from.
+func (c *Calendar) BusinessDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	from, to = from.In(c.location), to.In(c.location)
+
+	var total time.Duration
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, c.location)
+	for !day.After(to) {
+		if c.IsWorkday(day) {
+			open, closed := day.Add(c.start), day.Add(c.end)
+			if open.Before(from) {
+				open = from
+			}
+			if closed.After(to) {
+				closed = to
+			}
+			if closed.After(open) {
+				total += closed.Sub(open)
+			}
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, c.location)
+	}
+
+	return total
+}
+
+// parseClock parses an HH:MM time of day into an offset from midnight
+func parseClock(s string, fallback time.Duration) (time.Duration, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q must be HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// parseWeekday parses an English weekday name or its three-letter abbreviation
+func parseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", name)
+}
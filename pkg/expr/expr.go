@@ -0,0 +1,289 @@
+// Package expr evaluates boolean expressions over named values, such as
+// `stage == "payment_waiting" && app == "not_installed"`.
+//
+// Operands are numbers, single or double quoted strings, true, false, null, lists in brackets
+// and variable names. Operators, from the lowest precedence:
+//
+//	||                      logical or
+//	&&                      logical and
+//	!                       logical not
+//	== != < <= > >= in      comparisons; in tests membership of a list
+//
+// Parentheses group sub-expressions. Numbers are compared numerically and strings
+// lexicographically; values of different types are never equal and ordering comparisons with
+// null are false.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Env resolves variables to values: string, float64, bool, []string, []any or nil.
+type Env func(name string) (any, bool)
+
+// Map returns an Env resolving variables from a map
+func Map(values map[string]any) Env {
+	return func(name string) (any, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+}
+
+// Program is a compiled expression.
+type Program struct {
+	source string
+	root   node
+	vars   []string
+}
+
+// Compile parses an expression.
+func Compile(src string) (*Program, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+
+	return &Program{source: src, root: root, vars: p.vars}, nil
+}
+
+// String returns the source of the program
+func (p *Program) String() string {
+	return p.source
+}
+
+// Vars returns the names of the variables the expression refers to, in order of appearance.
+func (p *Program) Vars() []string {
+	return slices.Clone(p.vars)
+}
+
+// Eval evaluates the expression in env and returns its value.
+func (p *Program) Eval(env Env) (any, error) {
+	return p.root.eval(env)
+}
+
+// Bool evaluates the expression in env and requires a boolean result.
+func (p *Program) Bool(env Env) (bool, error) {
+	value, err := p.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q is not a condition: evaluates to %v", p.source, value)
+	}
+	return b, nil
+}
+
+// node is an expression of the syntax tree
+type node interface {
+	eval(env Env) (any, error)
+}
+
+// literal is a constant value
+type literal struct {
+	value any
+}
+
+func (n literal) eval(Env) (any, error) {
+	return n.value, nil
+}
+
+// variable is a value resolved from the environment
+type variable struct {
+	name string
+}
+
+func (n variable) eval(env Env) (any, error) {
+	value, ok := env(n.name)
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %s", n.name)
+	}
+	return normalize(value), nil
+}
+
+// list is a bracketed list of values
+type list struct {
+	items []node
+}
+
+func (n list) eval(env Env) (any, error) {
+	values := make([]any, len(n.items))
+	for i, item := range n.items {
+		value, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// not negates a boolean
+type not struct {
+	operand node
+}
+
+func (n not) eval(env Env) (any, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("operand of ! is not a boolean: %v", value)
+	}
+	return !b, nil
+}
+
+// logical is && or ||, evaluating the right operand only when needed
+type logical struct {
+	op          string
+	left, right node
+}
+
+func (n logical) eval(env Env) (any, error) {
+	left, err := asBool(n.op, n.left, env)
+	if err != nil {
+		return nil, err
+	}
+	if (n.op == "&&" && !left) || (n.op == "||" && left) {
+		return left, nil
+	}
+	return asBool(n.op, n.right, env)
+}
+
+func asBool(op string, operand node, env Env) (bool, error) {
+	value, err := operand.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("operand of %s is not a boolean: %v", op, value)
+	}
+	return b, nil
+}
+
+// comparison is a binary comparison or membership test
+type comparison struct {
+	op          string
+	left, right node
+}
+
+var errIncomparable = errors.New("incomparable values")
+
+func (n comparison) eval(env Env) (any, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		items, ok := right.([]any)
+		if !ok {
+			return nil, fmt.Errorf("right operand of in is not a list: %v", right)
+		}
+		return slices.ContainsFunc(items, func(item any) bool { return equal(left, item) }), nil
+	}
+
+	// Ordering against null, e.g. an unset field, is never true
+	if left == nil || right == nil {
+		return false, nil
+	}
+
+	c, err := compare(left, right)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compare %v %s %v: %w", left, n.op, right, err)
+	}
+	switch n.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+// normalize converts the values an Env may return to the types the evaluator works with
+func normalize(value any) any {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case []string:
+		items := make([]any, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items
+	}
+	return value
+}
+
+// equal reports whether two values have the same type and value
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case []any:
+		b, ok := b.([]any)
+		return ok && slices.EqualFunc(a, b, equal)
+	case nil:
+		return b == nil
+	}
+	if _, ok := b.([]any); ok {
+		return false
+	}
+	return a == b
+}
+
+// compare orders two numbers or two strings
+func compare(a, b any) (int, error) {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			switch {
+			case a < b:
+				return -1, nil
+			case a > b:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			switch {
+			case a < b:
+				return -1, nil
+			case a > b:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	return 0, errIncomparable
+}
@@ -0,0 +1,171 @@
+package expr
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	env := Map(map[string]any{
+		"stage":     "payment_waiting",
+		"source":    "partner",
+		"contracts": 2,
+		"amount":    -1.5,
+		"active":    true,
+		"tags":      []string{"vip", "needs_app"},
+		"assignee":  nil,
+	})
+
+	tests := []struct {
+		name string
+		src  string
+		want any
+	}{
+		// Precedence
+		{"and binds tighter than or", `true || false && false`, true},
+		{"parentheses group", `(true || false) && false`, false},
+		{"not binds looser than comparison", `!stage == "completed"`, true},
+		{"not of not", `!!active`, true},
+		{"comparison before and", `contracts > 1 && source == "partner"`, true},
+		{"or short circuits", `active || missing == 1`, true},
+		{"and short circuits", `!active && missing == 1`, false},
+
+		// Membership
+		{"in list literal", `source in ["partner", "referral"]`, true},
+		{"not in list literal", `source in ["ads"]`, false},
+		{"in variable list", `"vip" in tags`, true},
+		{"in empty list", `source in []`, false},
+		{"number in list", `contracts in [1, 2, 3]`, true},
+		{"types never equal", `contracts in ["2"]`, false},
+		{"null in list", `assignee in [null]`, true},
+
+		// Quoting and escapes
+		{"double quotes", `"partner" == source`, true},
+		{"single quotes", `'partner' == source`, true},
+		{"escaped single quote", `'it\'s'`, "it's"},
+		{"double quote inside single quotes", `'say "hi"'`, `say "hi"`},
+		{"escaped double quote", `"a\"b"`, `a"b`},
+		{"escape sequence", `"tab\tx"`, "tab\tx"},
+
+		// Numbers
+		{"integer variables are numbers", `contracts == 2`, true},
+		{"negative literal", `amount < -1`, true},
+		{"negative decimal", `amount == -1.5`, true},
+		{"negative first operand", `-2 < contracts`, true},
+		{"negative in list", `amount in [-1.5, 0]`, true},
+		{"decimal", `contracts >= 1.5`, true},
+
+		// Null
+		{"null equals null", `assignee == null`, true},
+		{"set value is not null", `stage != null`, true},
+		{"null is not a string", `assignee == ""`, false},
+		{"ordering with null is false", `assignee < 1`, false},
+		{"ordering with null is false both ways", `assignee >= 1`, false},
+
+		// Strings
+		{"strings order lexicographically", `stage > "completed"`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile(%q) error: %v", tt.src, err)
+			}
+			got, err := program.Eval(env)
+			if err != nil {
+				t.Fatalf("Eval(%q) error: %v", tt.src, err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unterminated string", `stage == "paid`, `unterminated string at 9`},
+		{"invalid escape", `stage == "\q"`, `invalid string at 9: invalid syntax`},
+		{"unexpected character", `stage @ 1`, `unexpected character '@' at 6`},
+		{"minus is not an operator", `contracts-1`, `unexpected character '-' at 9`},
+		{"chained comparison", `a == b == c`, `unexpected "==" at 7`},
+		{"missing parenthesis", `(a == b`, `expected ")" at end of expression`},
+		{"missing comma", `a in [1 2]`, `expected "," at 8, got "2"`},
+		{"in without operand", `in [1]`, `unexpected "in" at 0`},
+		{"missing operand", `a ==`, `unexpected end of expression`},
+		{"empty", ``, `unexpected end of expression`},
+		{"trailing operator", `a && )`, `unexpected ")" at 5`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src)
+			if err == nil {
+				t.Fatalf("Compile(%q) succeeded, want error %q", tt.src, tt.want)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Compile(%q) error = %q, want %q", tt.src, err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	env := Map(map[string]any{"stage": "new", "contracts": 1})
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unknown variable", `missing == 1`, `unknown variable missing`},
+		{"in requires a list", `stage in "new"`, `right operand of in is not a list: new`},
+		{"not requires a boolean", `!stage`, `operand of ! is not a boolean: new`},
+		{"and requires booleans", `contracts && true`, `operand of && is not a boolean: 1`},
+		{"ordering mixed types", `stage < 1`, `cannot compare new < 1: incomparable values`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile(%q) error: %v", tt.src, err)
+			}
+			_, err = program.Eval(env)
+			if err == nil {
+				t.Fatalf("Eval(%q) succeeded, want error %q", tt.src, tt.want)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Eval(%q) error = %q, want %q", tt.src, err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestBool(t *testing.T) {
+	program, err := Compile(`stage`)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	if _, err = program.Bool(Map(map[string]any{"stage": "new"})); err == nil {
+		t.Error("Bool of a string succeeded, want error")
+	}
+}
+
+func TestVars(t *testing.T) {
+	program, err := Compile(`stage == "new" && (source in ["a"] || stage != null)`)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+
+	got := program.Vars()
+	want := []string{"stage", "source"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Vars() = %v, want %v", got, want)
+	}
+}
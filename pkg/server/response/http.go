@@ -31,6 +31,15 @@ func Created(w http.ResponseWriter, r *http.Request, data any) {
 	render.JSON(w, r, v)
 }
 
+func Accepted(w http.ResponseWriter, r *http.Request, data any) {
+	render.Status(r, http.StatusAccepted)
+
+	v := Object{
+		Data: data,
+	}
+	render.JSON(w, r, v)
+}
+
 func BadRequest(w http.ResponseWriter, r *http.Request, err error, data any) {
 	render.Status(r, http.StatusBadRequest)
 
@@ -5,5 +5,9 @@ import (
 )
 
 var (
-	ErrorNotFound = errors.New("error not found")
+	ErrorNotFound      = errors.New("error not found")
+	ErrorAlreadyExists = errors.New("error already exists")
+
+	// ErrorInvalid marks errors caused by invalid input, wrapped as fmt.Errorf("%w field: ...", ErrorInvalid)
+	ErrorInvalid = errors.New("invalid")
 )
@@ -39,10 +39,10 @@ func Migrate(dataSourceName string) (err error) {
 	return
 }
 
-// MigrateClickHouse ensures required tables exist
-func MigrateClickHouse(ctx context.Context, conn clickhouse.Conn) error {
-	tables := []string{
-		`CREATE TABLE IF NOT EXISTS metrics (
+// metricsTable creates the metrics table under the given name. Versions of a metric share its id and
+// are replaced by the newest one; the id is part of the sorting key so that the series of a type,
+// date and interval that differ only in metadata are kept apart.
+const metricsTable = `CREATE TABLE IF NOT EXISTS %s (
    id String,
    type String,
    value Float64,
@@ -52,7 +52,43 @@ func MigrateClickHouse(ctx context.Context, conn clickhouse.Conn) error {
    date Date DEFAULT toDate(created_at)
   ) ENGINE = ReplacingMergeTree(created_at)
   PARTITION BY (type, date)
-  ORDER BY (type, date, interval)`,
+  ORDER BY (type, date, interval, id)`
+
+// MigrateClickHouse ensures required tables exist
+func MigrateClickHouse(ctx context.Context, conn clickhouse.Conn) error {
+	tables := []string{
+		fmt.Sprintf(metricsTable, "metrics"),
+		`CREATE TABLE IF NOT EXISTS stage_transitions (
+   client_id String,
+   from_stage String DEFAULT '',
+   to_stage String,
+   source String DEFAULT '',
+   channel String DEFAULT '',
+   created_at DateTime DEFAULT now()
+  ) ENGINE = MergeTree()
+  PARTITION BY toYYYYMM(created_at)
+  ORDER BY (client_id, created_at)`,
+		`CREATE TABLE IF NOT EXISTS client_touchpoints (
+   client_id String,
+   source String DEFAULT '',
+   channel String DEFAULT '',
+   origin String DEFAULT '',
+   created_at DateTime DEFAULT now()
+  ) ENGINE = MergeTree()
+  PARTITION BY toYYYYMM(created_at)
+  ORDER BY (client_id, created_at)`,
+		`CREATE TABLE IF NOT EXISTS client_activity (
+   client_id String,
+   event String,
+   reason String DEFAULT '',
+   comment String DEFAULT '',
+   created_at DateTime DEFAULT now()
+  ) ENGINE = MergeTree()
+  PARTITION BY toYYYYMM(created_at)
+  ORDER BY (client_id, created_at)`,
+		`ALTER TABLE stage_transitions ADD COLUMN IF NOT EXISTS reason String DEFAULT '' AFTER channel`,
+		`ALTER TABLE stage_transitions ADD COLUMN IF NOT EXISTS comment String DEFAULT '' AFTER reason`,
+		`ALTER TABLE client_activity ADD COLUMN IF NOT EXISTS stage String DEFAULT '' AFTER event`,
 	}
 
 	for _, query := range tables {
@@ -61,10 +97,44 @@ func MigrateClickHouse(ctx context.Context, conn clickhouse.Conn) error {
 		}
 	}
 
+	if err := migrateMetricsSortingKey(ctx, conn); err != nil {
+		return fmt.Errorf("failed to migrate metrics sorting key: %w", err)
+	}
+
 	fmt.Println("ClickHouse migration completed")
 	return nil
 }
 
+// migrateMetricsSortingKey rebuilds a metrics table created with the former sorting key
+// (type, date, interval), under which rows of different series replaced each other on merge.
+// The sorting key of a table cannot be extended by existing columns, so the rows are copied
+// into a new table that then takes the place of the old one.
+func migrateMetricsSortingKey(ctx context.Context, conn clickhouse.Conn) error {
+	var key string
+	row := conn.QueryRow(ctx, `SELECT sorting_key FROM system.tables WHERE database = currentDatabase() AND name = 'metrics'`)
+	if err := row.Scan(&key); err != nil {
+		return err
+	}
+	if key != "type, date, interval" {
+		return nil
+	}
+
+	queries := []string{
+		`DROP TABLE IF EXISTS metrics_rekeyed`,
+		fmt.Sprintf(metricsTable, "metrics_rekeyed"),
+		`INSERT INTO metrics_rekeyed SELECT * FROM metrics`,
+		`RENAME TABLE metrics TO metrics_former, metrics_rekeyed TO metrics`,
+		`DROP TABLE metrics_former`,
+	}
+	for _, query := range queries {
+		if err := conn.Exec(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // MigratePostgres runs migrations for PostgreSQL
 func MigratePostgres(dsn string, migrationsPath string) error {
 	// Connect using database/sql